@@ -20,11 +20,21 @@ func DefaultConfig() *models.Config {
 		HourlyInterval:       1,
 		SummaryTime:          "00:00",
 		ClaudeCodePath:       "claude-code",
+		AITimeout:            600, // 10分钟
+		AIRetries:            2,
+		AIRetryBackoff:       30,
+		AIOutputRetries:      1,
+		OfflineFallback:      true,
 		DialogTimeout:        300, // 5分钟
 		EnableLogging:        true,
 		EnableWeeklySummary:  false,
 		WeeklySummaryTime:    "09:00",
 		WeeklySummaryDay:     1, // 周一
+		MonthlySummaryTime:   "10:00",
+		MonthlySummaryDay:    1,
+		ReportChunkChars:     40000,
+		BackupTime:           "03:00",
+		BackupRetention:      7,
 	}
 }
 
@@ -99,6 +109,12 @@ func resolvePaths(cfg *models.Config) {
 	cfg.DataDir = resolve(cfg.DataDir)
 	cfg.SummaryDir = resolve(cfg.SummaryDir)
 	cfg.LogFile = resolve(cfg.LogFile)
+	cfg.SQLitePath = resolve(cfg.SQLitePath)
+	cfg.BackupDir = resolve(cfg.BackupDir)
+	cfg.TemplateDir = resolve(cfg.TemplateDir)
+	cfg.DailyTemplate = resolve(cfg.DailyTemplate)
+	cfg.WeeklyTemplate = resolve(cfg.WeeklyTemplate)
+	cfg.MonthlyTemplate = resolve(cfg.MonthlyTemplate)
 }
 
 // Save 保存配置到文件
@@ -0,0 +1,514 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/fileutil"
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// ManifestVersion 备份清单格式版本
+const ManifestVersion = 1
+
+// 备份文件命名：daily_summary-backup-YYYYMMDD-HHMMSS.tar.gz
+const (
+	archivePrefix = "daily_summary-backup-"
+	archiveExt    = ".tar.gz"
+)
+
+// 归档内的路径
+const (
+	manifestName = "manifest.json"    // 备份清单（位于归档末尾）
+	dataPrefix   = "data"             // 每日 JSON 数据目录
+	summaryDir   = "summaries"        // 总结目录
+	tasksName    = "tasks.json"       // 任务调度状态
+	sqliteName   = "daily_summary.db" // SQLite 数据库快照
+)
+
+// Sources 需要备份（或恢复到）的路径
+type Sources struct {
+	DataDir    string // 每日 JSON 数据目录
+	SummaryDir string // 总结目录
+	TasksFile  string // 任务调度状态文件（run/tasks.json）
+	SQLitePath string // SQLite 数据库路径，为空时不备份数据库
+}
+
+// Manifest 备份清单
+type Manifest struct {
+	Version       int        `json:"version"`        // 清单格式版本
+	CreatedAt     time.Time  `json:"created_at"`     // 备份时间
+	SchemaVersion int        `json:"schema_version"` // 备份时程序的数据格式版本
+	Files         []FileInfo `json:"files"`          // 归档中的文件（不含清单本身）
+}
+
+// FileInfo 归档中单个文件的信息
+type FileInfo struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// FileName 返回指定时间的备份文件名
+func FileName(t time.Time) string {
+	return archivePrefix + t.Format("20060102-150405") + archiveExt
+}
+
+// Create 在 dir 下创建带时间戳的备份文件，返回文件路径
+func Create(src Sources, dir string, now time.Time) (string, *Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("create backup directory: %w", err)
+	}
+	archivePath := filepath.Join(dir, FileName(now))
+	manifest, err := CreateFile(src, archivePath, now)
+	if err != nil {
+		return "", nil, err
+	}
+	return archivePath, manifest, nil
+}
+
+// CreateFile 将备份写入指定路径（先写临时文件，完成后再重命名，失败时不会留下不完整的备份）
+func CreateFile(src Sources, archivePath string, now time.Time) (*Manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(archivePath), "."+filepath.Base(archivePath)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Write(tmp, src, now)
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("close backup file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), archivePath); err != nil {
+		return nil, fmt.Errorf("rename backup file: %w", err)
+	}
+	return manifest, nil
+}
+
+// Write 将备份写为 tar.gz 流
+// 归档结构：data/...、summaries/...、tasks.json、daily_summary.db、manifest.json
+func Write(w io.Writer, src Sources, now time.Time) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	aw := &archiveWriter{tw: tw, now: now}
+
+	// 持有数据目录锁，避免备份过程中数据文件被修改
+	if err := os.MkdirAll(src.DataDir, 0755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	lock, err := fileutil.Lock(filepath.Join(src.DataDir, storage.DataLockFile))
+	if err != nil {
+		return nil, err
+	}
+	err = aw.addDir(src.DataDir, dataPrefix)
+	lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := aw.addDir(src.SummaryDir, summaryDir); err != nil {
+		return nil, err
+	}
+	if err := aw.addOptionalFile(src.TasksFile, tasksName); err != nil {
+		return nil, err
+	}
+
+	if src.SQLitePath != "" {
+		if _, err := os.Stat(src.SQLitePath); err == nil {
+			if err := aw.addSQLite(src.SQLitePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	manifest := &Manifest{
+		Version:       ManifestVersion,
+		CreatedAt:     now,
+		SchemaVersion: models.CurrentSchemaVersion,
+		Files:         aw.files,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := aw.writeFile(manifestName, int64(len(data)), strings.NewReader(string(data))); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("finalize tar: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("finalize gzip: %w", err)
+	}
+	return manifest, nil
+}
+
+// archiveWriter 写入归档并记录文件校验和
+type archiveWriter struct {
+	tw    *tar.Writer
+	now   time.Time
+	files []FileInfo
+}
+
+// addDir 递归加入目录（跳过锁文件和写入中的临时文件），目录不存在时跳过
+func (a *archiveWriter) addDir(dir, prefix string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read directory %s: %w", dir, err)
+	}
+
+	if err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     prefix + "/",
+		Mode:     0755,
+		ModTime:  a.now,
+	}); err != nil {
+		return fmt.Errorf("write tar header: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if name == storage.DataLockFile || strings.Contains(name, ".tmp-") {
+			continue
+		}
+		fullPath := filepath.Join(dir, name)
+		if file.IsDir() {
+			if err := a.addDir(fullPath, path.Join(prefix, name)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Type().IsRegular() {
+			continue
+		}
+		if err := a.addFile(fullPath, path.Join(prefix, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addOptionalFile 加入单个文件，文件不存在时跳过
+func (a *archiveWriter) addOptionalFile(filePath, name string) error {
+	if filePath == "" {
+		return nil
+	}
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil
+	}
+	return a.addFile(filePath, name)
+}
+
+// addFile 加入单个文件
+func (a *archiveWriter) addFile(filePath, name string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", filePath, err)
+	}
+	return a.writeFile(name, info.Size(), f)
+}
+
+// addSQLite 加入数据库快照（直接复制正在使用的数据库文件可能得到不一致的内容）
+func (a *archiveWriter) addSQLite(dbPath string) error {
+	tmpDir, err := os.MkdirTemp("", "daily_summary-backup-*")
+	if err != nil {
+		return fmt.Errorf("create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshot := filepath.Join(tmpDir, sqliteName)
+	if err := storage.SnapshotSQLite(dbPath, snapshot); err != nil {
+		return err
+	}
+	return a.addFile(snapshot, sqliteName)
+}
+
+// writeFile 写入一个文件条目，除清单外都记录到清单中
+func (a *archiveWriter) writeFile(name string, size int64, r io.Reader) error {
+	if err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  a.now,
+	}); err != nil {
+		return fmt.Errorf("write tar header for %s: %w", name, err)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(a.tw, io.TeeReader(r, hash))
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if n != size {
+		return fmt.Errorf("write %s: file changed during backup", name)
+	}
+
+	if name != manifestName {
+		a.files = append(a.files, FileInfo{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	}
+	return nil
+}
+
+// Verify 校验备份文件：清单存在且格式受支持，所有文件的大小和 SHA-256 与清单一致
+func Verify(archivePath string) (*Manifest, error) {
+	return extract(archivePath, "")
+}
+
+// extract 读取并校验备份文件；dst 不为空时同时解压到 dst
+func extract(archivePath, dst string) (*Manifest, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("open backup file: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read gzip: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	actual := make(map[string]FileInfo)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read tar: %w", err)
+		}
+
+		name, err := cleanArchivePath(header.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if dst != "" {
+				if err := os.MkdirAll(filepath.Join(dst, filepath.FromSlash(name)), 0755); err != nil {
+					return nil, fmt.Errorf("create directory %s: %w", name, err)
+				}
+			}
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("unsupported entry type in backup: %s", header.Name)
+		}
+
+		if name == manifestName {
+			manifest = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			continue
+		}
+
+		info, err := extractFile(tr, dst, name)
+		if err != nil {
+			return nil, err
+		}
+		actual[name] = info
+	}
+
+	if manifest == nil {
+		return nil, errors.New("backup has no manifest")
+	}
+	if manifest.Version == 0 || manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("unsupported backup manifest version: %d", manifest.Version)
+	}
+
+	for _, expected := range manifest.Files {
+		got, ok := actual[expected.Path]
+		if !ok {
+			return nil, fmt.Errorf("file %s listed in manifest is missing from backup", expected.Path)
+		}
+		if got.Size != expected.Size || got.SHA256 != expected.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s", expected.Path)
+		}
+		delete(actual, expected.Path)
+	}
+	for name := range actual {
+		return nil, fmt.Errorf("file %s is not listed in manifest", name)
+	}
+
+	return manifest, nil
+}
+
+// extractFile 计算文件的校验和，dst 不为空时写入 dst
+func extractFile(r io.Reader, dst, name string) (FileInfo, error) {
+	var w io.Writer = io.Discard
+	if dst != "" {
+		target := filepath.Join(dst, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return FileInfo{}, fmt.Errorf("create directory for %s: %w", name, err)
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return FileInfo{}, fmt.Errorf("create %s: %w", name, err)
+		}
+		defer out.Close()
+		w = out
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), r)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("extract %s: %w", name, err)
+	}
+	return FileInfo{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// cleanArchivePath 规范化归档内路径，拒绝绝对路径、".." 以及不属于备份结构的条目
+func cleanArchivePath(name string) (string, error) {
+	clean := path.Clean(strings.TrimSuffix(name, "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid path in backup: %s", name)
+	}
+
+	top := strings.SplitN(clean, "/", 2)[0]
+	switch top {
+	case dataPrefix, summaryDir, tasksName, sqliteName, manifestName:
+		return clean, nil
+	default:
+		return "", fmt.Errorf("unexpected entry in backup: %s", name)
+	}
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Manifest     *Manifest
+	SafetyBackup string // 恢复前对当前数据的备份
+}
+
+// Restore 校验备份并恢复到 dst
+// 备份中包含的部分（数据目录、总结目录、任务状态、数据库）会整体替换当前内容，不包含的部分保持不变；
+// 替换前会先把当前数据备份到 safetyDir，恢复结果不符合预期时可以再恢复回来
+func Restore(archivePath string, dst Sources, safetyDir string, now time.Time) (*RestoreResult, error) {
+	workDir := filepath.Dir(dst.DataDir)
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(workDir, ".restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := extract(archivePath, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion > models.CurrentSchemaVersion {
+		return nil, fmt.Errorf("backup schema version v%d is newer than supported v%d", manifest.SchemaVersion, models.CurrentSchemaVersion)
+	}
+
+	if err := os.MkdirAll(safetyDir, 0755); err != nil {
+		return nil, fmt.Errorf("create backup directory: %w", err)
+	}
+	safety := filepath.Join(safetyDir, archivePrefix+now.Format("20060102-150405")+"-pre-restore"+archiveExt)
+	if _, err := CreateFile(dst, safety, now); err != nil {
+		return nil, fmt.Errorf("backup current data before restore: %w", err)
+	}
+	result := &RestoreResult{Manifest: manifest, SafetyBackup: safety}
+
+	replacements := []struct {
+		extracted string
+		target    string
+	}{
+		{filepath.Join(tmpDir, dataPrefix), dst.DataDir},
+		{filepath.Join(tmpDir, summaryDir), dst.SummaryDir},
+		{filepath.Join(tmpDir, tasksName), dst.TasksFile},
+		{filepath.Join(tmpDir, sqliteName), dst.SQLitePath},
+	}
+	for _, r := range replacements {
+		if r.target == "" {
+			continue
+		}
+		if _, err := os.Stat(r.extracted); os.IsNotExist(err) {
+			continue
+		}
+		if r.target == dst.SQLitePath {
+			// 旧数据库的 WAL 文件与恢复后的数据库不匹配
+			os.Remove(r.target + "-wal")
+			os.Remove(r.target + "-shm")
+		}
+		if err := os.MkdirAll(filepath.Dir(r.target), 0755); err != nil {
+			return result, fmt.Errorf("create directory: %w", err)
+		}
+		if err := os.RemoveAll(r.target); err != nil {
+			return result, fmt.Errorf("remove %s: %w", r.target, err)
+		}
+		if err := os.Rename(r.extracted, r.target); err != nil {
+			return result, fmt.Errorf("restore %s: %w", r.target, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Prune 只保留 dir 下最新的 keep 个备份文件，返回被删除的文件；keep <= 0 时不删除
+func Prune(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup directory: %w", err)
+	}
+
+	// 文件名中的时间戳按字典序即时间顺序
+	var archives []string
+	for _, file := range files {
+		name := file.Name()
+		if !file.IsDir() && strings.HasPrefix(name, archivePrefix) && strings.HasSuffix(name, archiveExt) {
+			archives = append(archives, name)
+		}
+	}
+	sort.Strings(archives)
+
+	var removed []string
+	for len(archives) > keep {
+		archivePath := filepath.Join(dir, archives[0])
+		if err := os.Remove(archivePath); err != nil {
+			return removed, fmt.Errorf("remove old backup: %w", err)
+		}
+		removed = append(removed, archivePath)
+		archives = archives[1:]
+	}
+	return removed, nil
+}
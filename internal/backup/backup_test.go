@@ -0,0 +1,211 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// newTestSources 创建包含数据、总结和任务状态的测试目录
+func newTestSources(t *testing.T) Sources {
+	tmpDir := t.TempDir()
+	src := Sources{
+		DataDir:    filepath.Join(tmpDir, "run", "data"),
+		SummaryDir: filepath.Join(tmpDir, "run", "summaries"),
+		TasksFile:  filepath.Join(tmpDir, "run", "tasks.json"),
+	}
+	os.MkdirAll(src.DataDir, 0755)
+	os.MkdirAll(filepath.Join(src.SummaryDir, "daily"), 0755)
+	os.WriteFile(filepath.Join(src.DataDir, "2026-01-19.json"), []byte(`{"date":"2026-01-19","entries":[]}`), 0644)
+	os.WriteFile(filepath.Join(src.DataDir, ".schema_version"), []byte("3\n"), 0644)
+	os.WriteFile(filepath.Join(src.SummaryDir, "daily", "2026-01-19.md"), []byte("# 工作总结"), 0644)
+	os.WriteFile(src.TasksFile, []byte(`{"tasks":[]}`), 0644)
+	return src
+}
+
+// TestCreateAndVerify 测试创建备份并校验清单
+func TestCreateAndVerify(t *testing.T) {
+	src := newTestSources(t)
+	dir := filepath.Join(t.TempDir(), "backups")
+	now := time.Date(2026, 1, 20, 3, 0, 0, 0, time.Local)
+
+	archivePath, manifest, err := Create(src, dir, now)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if filepath.Base(archivePath) != "daily_summary-backup-20260120-030000.tar.gz" {
+		t.Errorf("Unexpected archive name: %s", archivePath)
+	}
+
+	paths := make(map[string]bool)
+	for _, file := range manifest.Files {
+		paths[file.Path] = true
+	}
+	for _, want := range []string{"data/2026-01-19.json", "data/.schema_version", "summaries/daily/2026-01-19.md", "tasks.json"} {
+		if !paths[want] {
+			t.Errorf("Expected %s in manifest, got %v", want, manifest.Files)
+		}
+	}
+	if paths["data/.daily_summary.lock"] {
+		t.Error("Lock file should not be backed up")
+	}
+
+	verified, err := Verify(archivePath)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(verified.Files) != len(manifest.Files) || !verified.CreatedAt.Equal(now) {
+		t.Errorf("Verified manifest does not match: %+v", verified)
+	}
+}
+
+// TestVerifyDetectsTampering 测试修改过的备份无法通过校验
+func TestVerifyDetectsTampering(t *testing.T) {
+	src := newTestSources(t)
+	dir := t.TempDir()
+	archivePath, _, err := Create(src, dir, time.Now())
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// 重写归档，替换其中一个文件的内容
+	tampered := filepath.Join(dir, "tampered.tar.gz")
+	in, _ := os.Open(archivePath)
+	defer in.Close()
+	gr, _ := gzip.NewReader(in)
+	out, _ := os.Create(tampered)
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		content, _ := io.ReadAll(tr)
+		if header.Name == "tasks.json" {
+			content = []byte(`{"tasks":[1]}`)
+			header.Size = int64(len(content))
+		}
+		tw.WriteHeader(header)
+		tw.Write(content)
+	}
+	tw.Close()
+	gw.Close()
+	out.Close()
+
+	if _, err := Verify(tampered); err == nil || !strings.Contains(err.Error(), "tasks.json") {
+		t.Errorf("Expected checksum error for tasks.json, got %v", err)
+	}
+}
+
+// TestRestore 测试恢复备份并在恢复前备份当前数据
+func TestRestore(t *testing.T) {
+	src := newTestSources(t)
+	dir := t.TempDir()
+	archivePath, _, err := Create(src, dir, time.Date(2026, 1, 20, 3, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// 备份之后的修改
+	os.WriteFile(filepath.Join(src.DataDir, "2026-01-20.json"), []byte(`{"date":"2026-01-20","entries":[]}`), 0644)
+	os.WriteFile(src.TasksFile, []byte(`{"tasks":[{"id":"x"}]}`), 0644)
+
+	result, err := Restore(archivePath, src, dir, time.Date(2026, 1, 21, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(src.DataDir, "2026-01-20.json")); !os.IsNotExist(err) {
+		t.Error("Files created after the backup should be removed by restore")
+	}
+	if data, _ := os.ReadFile(src.TasksFile); string(data) != `{"tasks":[]}` {
+		t.Errorf("tasks.json not restored: %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(src.SummaryDir, "daily", "2026-01-19.md")); string(data) != "# 工作总结" {
+		t.Errorf("Summary not restored: %s", data)
+	}
+
+	// 恢复前的数据保存在安全备份中
+	safety, err := Verify(result.SafetyBackup)
+	if err != nil {
+		t.Fatalf("Safety backup is invalid: %v", err)
+	}
+	found := false
+	for _, file := range safety.Files {
+		if file.Path == "data/2026-01-20.json" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Safety backup should contain data created after the restored backup")
+	}
+}
+
+// TestPrune 测试只保留最新的若干个备份
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 20, 3, 0, 0, 0, time.Local)
+	for i := 0; i < 4; i++ {
+		os.WriteFile(filepath.Join(dir, FileName(base.AddDate(0, 0, i))), []byte("x"), 0644)
+	}
+	os.Mkdir(filepath.Join(dir, "migrate-v1-20260101-000000"), 0755)
+
+	removed, err := Prune(dir, 2)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 2 || filepath.Base(removed[0]) != FileName(base) {
+		t.Errorf("Expected the 2 oldest backups to be removed, got %v", removed)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 3 {
+		t.Errorf("Expected 2 backups and the migration directory to remain, got %d entries", len(files))
+	}
+}
+
+// TestSQLiteSnapshot 测试备份和恢复 SQLite 数据库
+func TestSQLiteSnapshot(t *testing.T) {
+	src := newTestSources(t)
+	src.SQLitePath = filepath.Join(filepath.Dir(src.DataDir), "daily_summary.db")
+	store, err := storage.NewSQLiteStorage(src.SQLitePath, src.SummaryDir)
+	if err != nil {
+		t.Fatalf("Failed to open sqlite storage: %v", err)
+	}
+	entry := models.WorkEntry{Timestamp: time.Date(2026, 1, 19, 11, 0, 0, 0, time.Local), Content: "对账任务排查"}
+	if err := store.SaveEntry(entry); err != nil {
+		t.Fatalf("Failed to save entry: %v", err)
+	}
+	store.Close()
+
+	dir := t.TempDir()
+	archivePath, _, err := Create(src, dir, time.Now())
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	os.Remove(src.SQLitePath)
+	if _, err := Restore(archivePath, src, dir, time.Now()); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	store, err = storage.NewSQLiteStorage(src.SQLitePath, src.SummaryDir)
+	if err != nil {
+		t.Fatalf("Failed to reopen sqlite storage: %v", err)
+	}
+	defer store.Close()
+	data, err := store.GetDailyData(entry.Timestamp)
+	if err != nil || len(data.Entries) != 1 || data.Entries[0].Content != entry.Content {
+		t.Errorf("Unexpected restored data: %+v, err=%v", data, err)
+	}
+}
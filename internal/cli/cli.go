@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -10,9 +11,15 @@ import (
 	"syscall"
 	"time"
 
+	"humg.top/daily_summary/internal/backup"
 	"humg.top/daily_summary/internal/dialog"
+	"humg.top/daily_summary/internal/export"
+	"humg.top/daily_summary/internal/fsck"
+	"humg.top/daily_summary/internal/importer"
+	"humg.top/daily_summary/internal/migrate"
 	"humg.top/daily_summary/internal/models"
 	"humg.top/daily_summary/internal/scheduler"
+	"humg.top/daily_summary/internal/search"
 	"humg.top/daily_summary/internal/storage"
 )
 
@@ -44,7 +51,8 @@ func RunAdd(store storage.Storage, content string, dataDir string) error {
 }
 
 // RunList 列出今日记录
-func RunList(store storage.Storage) error {
+// filter 非空时只列出满足条件的记录；groupBy 非空时按项目（project）或标签（tag）分组显示
+func RunList(store storage.Storage, filter models.EntryFilter, groupBy string) error {
 	today := time.Now()
 
 	dailyData, err := store.GetDailyData(today)
@@ -52,20 +60,143 @@ func RunList(store storage.Storage) error {
 		return fmt.Errorf("failed to get daily data: %w", err)
 	}
 
-	if len(dailyData.Entries) == 0 {
-		fmt.Println("今日暂无记录")
+	entries := filter.Apply(dailyData.Entries)
+	if len(entries) == 0 {
+		if filter.IsEmpty() {
+			fmt.Println("今日暂无记录")
+		} else {
+			fmt.Printf("今日暂无 %s 相关记录\n", filter.String())
+		}
 		return nil
 	}
 
-	fmt.Printf("📝 今日工作记录 (%s)：\n\n", today.Format("2006-01-02"))
-	for _, entry := range dailyData.Entries {
-		fmt.Printf("  • %s - %s\n", entry.Timestamp.Format("15:04"), entry.Content)
+	if filter.IsEmpty() {
+		fmt.Printf("📝 今日工作记录 (%s)：\n\n", today.Format("2006-01-02"))
+	} else {
+		fmt.Printf("📝 今日工作记录 (%s，%s)：\n\n", today.Format("2006-01-02"), filter.String())
+	}
+
+	if groupBy == "" {
+		printEntries(entries)
+	} else {
+		for _, group := range models.GroupEntries(entries, groupBy) {
+			fmt.Printf("%s（%d 条）\n", groupTitle(group.Name, groupBy), len(group.Entries))
+			printEntries(group.Entries)
+			fmt.Println()
+		}
+	}
+	fmt.Printf("\n共 %d 条记录\n", len(entries))
+
+	return nil
+}
+
+// printEntries 输出记录列表（含记录 ID）
+func printEntries(entries []models.WorkEntry) {
+	for _, entry := range entries {
+		fmt.Printf("  • %s - %s  [%s]\n", entry.Timestamp.Format("15:04"), entry.Content, entry.ID)
+	}
+}
+
+// groupTitle 返回分组标题（@项目 / #标签 / 未标注）
+func groupTitle(name, groupBy string) string {
+	if name == "" {
+		return "未标注"
+	}
+	if groupBy == models.GroupByTag {
+		return "#" + name
+	}
+	return "@" + name
+}
+
+// RunEdit 修改指定 ID 的工作记录
+// content 为空时弹出对话框，以原内容作为默认值供用户修改
+func RunEdit(store storage.Storage, dlg dialog.Dialog, id string, content string) error {
+	entry, err := store.GetEntry(id)
+	if err != nil {
+		return err
+	}
+
+	if content == "" {
+		input, ok, err := dlg.ShowInput("修改工作记录", fmt.Sprintf("修改 %s 的记录:", entry.Timestamp.Format("2006-01-02 15:04")), entry.Content)
+		if err != nil {
+			return fmt.Errorf("failed to show dialog: %w", err)
+		}
+		if !ok || input == "" {
+			fmt.Println("已取消或未输入内容")
+			return nil
+		}
+		content = input
+	}
+
+	return updateEntryContent(store, *entry, content)
+}
+
+// RunAmend 修改最后一条工作记录
+func RunAmend(store storage.Storage, content string) error {
+	entry, err := store.GetLastEntry()
+	if err != nil {
+		return fmt.Errorf("failed to get last entry: %w", err)
+	}
+	if entry == nil {
+		return fmt.Errorf("没有可修改的记录")
+	}
+
+	return updateEntryContent(store, *entry, content)
+}
+
+// RunDelete 删除指定 ID 的工作记录
+func RunDelete(store storage.Storage, id string) error {
+	entry, err := store.GetEntry(id)
+	if err != nil {
+		return err
+	}
+
+	wasGenerated := summaryGenerated(store, entry.Timestamp)
+
+	if err := store.DeleteEntry(id); err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	log.Printf("Work entry deleted: %s (%s)", id, entry.Content)
+	fmt.Printf("✓ 已删除：%s (%s)\n", entry.Content, entry.Timestamp.Format("2006-01-02 15:04"))
+	printSummaryReset(wasGenerated, entry.Timestamp)
+
+	return nil
+}
+
+// updateEntryContent 保存修改后的记录内容并输出结果
+func updateEntryContent(store storage.Storage, entry models.WorkEntry, content string) error {
+	oldContent := entry.Content
+	wasGenerated := summaryGenerated(store, entry.Timestamp)
+
+	entry.Content = content
+	if err := store.UpdateEntry(entry); err != nil {
+		return fmt.Errorf("failed to update entry: %w", err)
 	}
-	fmt.Printf("\n共 %d 条记录\n", len(dailyData.Entries))
+
+	log.Printf("Work entry updated: %s (%s -> %s)", entry.ID, oldContent, content)
+	fmt.Printf("✓ 已修改：%s (%s)\n", content, entry.Timestamp.Format("2006-01-02 15:04"))
+	printSummaryReset(wasGenerated, entry.Timestamp)
 
 	return nil
 }
 
+// summaryGenerated 查询记录所在日期是否已生成总结
+func summaryGenerated(store storage.Storage, date time.Time) bool {
+	dailyData, err := store.GetDailyData(date)
+	if err != nil {
+		return false
+	}
+	return dailyData.SummaryGenerated
+}
+
+// printSummaryReset 如果修改的是已生成总结的日期，提示总结将重新生成
+func printSummaryReset(wasGenerated bool, date time.Time) {
+	if wasGenerated {
+		fmt.Printf("  %s 的总结已标记为待重新生成\n", date.Format("2006-01-02"))
+	}
+}
+
 // RunPopup 显示对话框让用户输入工作记录
 func RunPopup(store storage.Storage, dlg dialog.Dialog, dataDir string) error {
 	startTime := time.Now()
@@ -277,3 +408,342 @@ func updateTaskSchedule(dataDir string, addTime time.Time) error {
 
 	return nil
 }
+
+// RunSQLiteImport 将 JSON 数据目录和总结目录中的历史数据导入 SQLite
+func RunSQLiteImport(store *storage.SQLiteStorage, dataDir, summaryDir, dbPath string) error {
+	fmt.Printf("正在导入历史数据到 %s ...\n", dbPath)
+
+	result, err := store.ImportFromJSON(dataDir, summaryDir)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("SQLite import completed: %d days, %d entries (%d skipped), %d daily summaries, %d weekly summaries, %d monthly summaries",
+		result.Days, result.Entries, result.SkippedEntries, result.DailySummaries, result.WeeklySummaries, result.MonthlySummaries)
+
+	fmt.Printf("✓ 导入完成\n")
+	fmt.Printf("  天数: %d\n", result.Days)
+	fmt.Printf("  工作记录: %d 条（跳过已存在 %d 条）\n", result.Entries, result.SkippedEntries)
+	fmt.Printf("  日报: %d 篇\n", result.DailySummaries)
+	fmt.Printf("  周报: %d 篇\n", result.WeeklySummaries)
+	fmt.Printf("  月报: %d 篇\n", result.MonthlySummaries)
+
+	return nil
+}
+
+// RunBackup 创建备份；output 为空时保存到 backupDir 并按 retention 清理旧备份
+func RunBackup(sources backup.Sources, backupDir, output string, retention int) error {
+	now := time.Now()
+
+	var archivePath string
+	var manifest *backup.Manifest
+	var err error
+	if output != "" {
+		archivePath = output
+		manifest, err = backup.CreateFile(sources, output, now)
+	} else {
+		archivePath, manifest, err = backup.Create(sources, backupDir, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, file := range manifest.Files {
+		size += file.Size
+	}
+	log.Printf("Backup created: %s (%d files)", archivePath, len(manifest.Files))
+	fmt.Printf("✓ 备份已创建: %s\n", archivePath)
+	fmt.Printf("  文件: %d 个（%.1f KB）\n", len(manifest.Files), float64(size)/1024)
+
+	if output == "" {
+		removed, err := backup.Prune(backupDir, retention)
+		if len(removed) > 0 {
+			fmt.Printf("  已清理 %d 个旧备份（保留最近 %d 个）\n", len(removed), retention)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunRestore 校验并恢复备份（verifyOnly 时只校验）
+// 恢复会替换当前数据，需要先停止后台服务
+func RunRestore(archivePath string, sources backup.Sources, backupDir, workDir string, verifyOnly bool) error {
+	if verifyOnly {
+		manifest, err := backup.Verify(archivePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ 备份校验通过: %s\n", archivePath)
+		fmt.Printf("  备份时间: %s\n", manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("  文件: %d 个\n", len(manifest.Files))
+		return nil
+	}
+
+	if data, err := os.ReadFile(getLockFilePath(workDir)); err == nil {
+		if pid := strings.TrimSpace(string(data)); isProcessRunning(pid) {
+			return fmt.Errorf("后台服务正在运行 (PID: %s)，请先停止服务再恢复: launchctl unload ~/Library/LaunchAgents/com.humg.daily_summary.plist", pid)
+		}
+	}
+
+	result, err := backup.Restore(archivePath, sources, backupDir, time.Now())
+	if err != nil {
+		if result != nil {
+			fmt.Fprintf(os.Stderr, "恢复前的数据已备份到: %s\n", result.SafetyBackup)
+		}
+		return err
+	}
+
+	log.Printf("Backup restored: %s (safety backup: %s)", archivePath, result.SafetyBackup)
+	fmt.Printf("✓ 已从备份恢复: %s\n", archivePath)
+	fmt.Printf("  备份时间: %s\n", result.Manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  文件: %d 个\n", len(result.Manifest.Files))
+	fmt.Printf("  恢复前的数据已备份到: %s\n", result.SafetyBackup)
+	return nil
+}
+
+// fsckKindLabels 问题类型的显示名称
+var fsckKindLabels = map[fsck.Kind]string{
+	fsck.KindCorrupt:          "文件损坏",
+	fsck.KindDateMismatch:     "日期字段错误",
+	fsck.KindWrongDay:         "记录日期错误",
+	fsck.KindDuplicate:        "重复记录",
+	fsck.KindDuplicateID:      "重复 ID",
+	fsck.KindMissingSummary:   "日报缺失",
+	fsck.KindUnflaggedSummary: "日报未标记",
+	fsck.KindOrphanSummary:    "孤立日报",
+}
+
+// RunFsck 检查（并可选修复）数据文件，返回未修复的问题数
+func RunFsck(opts fsck.Options) (int, error) {
+	report, err := fsck.Run(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	fmt.Printf("已检查 %d 个数据文件，%d 条记录\n", report.Files, report.Entries)
+	if len(report.Issues) == 0 {
+		fmt.Println("✓ 未发现问题")
+		return 0, nil
+	}
+
+	remaining, quarantined := 0, 0
+	fmt.Printf("\n发现 %d 个问题：\n", len(report.Issues))
+	for _, issue := range report.Issues {
+		if issue.Kind == fsck.KindCorrupt && issue.Repaired {
+			quarantined++
+		}
+		mark := "✗"
+		if issue.Repaired {
+			mark = "✓"
+		} else {
+			remaining++
+		}
+		fmt.Printf("  %s %s  [%s] %s", mark, issue.Date, fsckKindLabels[issue.Kind], issue.Message)
+		if issue.EntryID != "" {
+			fmt.Printf("  (%s)", issue.EntryID)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	if opts.Repair {
+		log.Printf("Fsck repaired %d issue(s), %d remaining", len(report.Issues)-remaining, remaining)
+		fmt.Printf("✓ 已修复 %d 个问题", len(report.Issues)-remaining)
+		if remaining > 0 {
+			fmt.Printf("，%d 个问题需要手动处理", remaining)
+		}
+		fmt.Println()
+		if quarantined > 0 {
+			fmt.Printf("  无法解析的文件已移动到: %s\n", opts.QuarantineDir)
+		}
+	} else {
+		fmt.Println("使用 --repair 修复（无法解析的文件会被隔离，不会删除；建议先执行 daily_summary backup）")
+	}
+	return remaining, nil
+}
+
+// RunMigrate 将数据升级到当前格式版本（dryRun 时只列出待执行的迁移）
+func RunMigrate(migrator *migrate.Migrator, dryRun bool) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	if len(status.Pending) == 0 {
+		if !dryRun {
+			// 全新的数据目录会在这里写入版本文件
+			if _, err := migrator.Run(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("✓ 数据格式已是最新版本（v%d）\n", status.Latest)
+		return nil
+	}
+
+	fmt.Printf("数据格式版本: v%d，最新版本: v%d\n", status.Current, status.Latest)
+	if dryRun {
+		fmt.Println("待执行的迁移：")
+		for _, migration := range status.Pending {
+			fmt.Printf("  v%d  %s\n", migration.Version, migration.Description)
+		}
+		return nil
+	}
+
+	steps, err := migrator.Run()
+	for _, step := range steps {
+		log.Printf("Schema migrated to v%d (backup: %s)", step.Version, step.Backup)
+		fmt.Printf("✓ v%d  %s\n", step.Version, step.Description)
+		fmt.Printf("    备份: %s\n", step.Backup)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ 迁移完成，数据格式已升级到 v%d\n", status.Latest)
+	return nil
+}
+
+// RunSearch 搜索工作记录和总结（搜索前增量更新索引）
+func RunSearch(store storage.Storage, indexPath, summaryDir string, query search.Query) error {
+	idx, stats, err := search.Refresh(indexPath, store, summaryDir)
+	if err != nil {
+		return fmt.Errorf("failed to update search index: %w", err)
+	}
+	if stats.Updated > 0 || stats.Removed > 0 {
+		log.Printf("Search index updated: %d source(s) reindexed, %d removed", stats.Updated, stats.Removed)
+	}
+
+	results, err := idx.Search(query)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("未找到匹配的记录或总结")
+		return nil
+	}
+
+	fmt.Printf("🔍 找到 %d 条结果：\n\n", len(results))
+	for _, result := range results {
+		doc := result.Doc
+		switch doc.Kind {
+		case search.KindEntry:
+			fmt.Printf("  %s %s  [记录 %s]\n", doc.Date, doc.Time, doc.Ref)
+		case search.KindDaily:
+			fmt.Printf("  %s  [日报] %s\n", doc.Date, doc.Ref)
+		case search.KindWeekly:
+			fmt.Printf("  %s  [周报] %s\n", doc.Date, doc.Ref)
+		case search.KindMonthly:
+			fmt.Printf("  %s  [月报] %s\n", doc.Date, doc.Ref)
+		}
+		fmt.Printf("    %s\n\n", result.Snippet)
+	}
+
+	return nil
+}
+
+// RunExport 导出日期范围内的工作记录
+// bundle 为 true 时连同日报/周报打包为 zip；output 为空时输出到标准输出（打包模式必须指定 output）
+func RunExport(store storage.Storage, summaryDir, format string, opts export.Options, bundle bool, output string) error {
+	rows, err := export.Collect(store, opts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	period := fmt.Sprintf("%s 至 %s", opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02"))
+
+	if bundle {
+		result, err := export.WriteBundle(w, format, rows, opts, summaryDir)
+		if err != nil {
+			return err
+		}
+		log.Printf("Exported bundle %s: %d entries, %d daily summaries, %d weekly summaries",
+			output, result.Entries, result.DailySummaries, result.WeeklySummaries)
+		fmt.Printf("✓ 已导出 %s 的数据到: %s\n", period, output)
+		fmt.Printf("  工作记录: %d 条\n", result.Entries)
+		fmt.Printf("  日报: %d 篇\n", result.DailySummaries)
+		fmt.Printf("  周报: %d 篇\n", result.WeeklySummaries)
+		return nil
+	}
+
+	if err := export.Write(w, format, rows, opts); err != nil {
+		return err
+	}
+	log.Printf("Exported %d entries (%s) as %s", len(rows), period, format)
+
+	if output != "" {
+		fmt.Printf("✓ 已导出 %s 的 %d 条记录到: %s\n", period, len(rows), output)
+	}
+	return nil
+}
+
+// importPreviewLimit dry-run 时最多预览的记录条数
+const importPreviewLimit = 20
+
+// RunImport 从外部文件导入工作记录
+// dryRun 为 true 时只预览不写入；markSummarized 为 true 时将导入文件涉及的日期标记为已生成日报，避免后台服务批量补生成，
+// 否则有新记录导入的日期会清除已生成标记，以便重新生成
+func RunImport(store storage.Storage, parser importer.Parser, path string, dryRun, markSummarized bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	parsed, err := parser.Parse(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse import file: %w", err)
+	}
+
+	for _, rowErr := range parsed.Errors {
+		fmt.Fprintf(os.Stderr, "  跳过第 %d 行: %v\n", rowErr.Line, rowErr.Err)
+	}
+
+	result, err := importer.Import(store, parsed.Entries, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("📋 预览（dry-run，未写入）：将导入 %d 条记录，涉及 %d 天\n\n", len(result.Entries), len(result.Dates))
+		for i, entry := range result.Entries {
+			if i == importPreviewLimit {
+				fmt.Printf("  ... 以及另外 %d 条\n", len(result.Entries)-importPreviewLimit)
+				break
+			}
+			fmt.Printf("  • %s - %s\n", entry.Timestamp.Format("2006-01-02 15:04"), entry.Content)
+		}
+		fmt.Printf("\n重复跳过: %d 条，无法解析: %d 行\n", result.Duplicates, len(parsed.Errors))
+		return nil
+	}
+
+	if err := importer.UpdateSummaryFlags(store, result, markSummarized); err != nil {
+		log.Printf("Failed to update summary flags for imported dates: %v", err)
+	}
+
+	log.Printf("Imported %d entries from %s (%d duplicates, %d invalid rows)",
+		len(result.Entries), path, result.Duplicates, len(parsed.Errors))
+
+	fmt.Printf("✓ 导入完成\n")
+	fmt.Printf("  新增记录: %d 条（涉及 %d 天）\n", len(result.Entries), len(result.Dates))
+	fmt.Printf("  重复跳过: %d 条\n", result.Duplicates)
+	fmt.Printf("  无法解析: %d 行\n", len(parsed.Errors))
+	if len(result.Dates) > 0 && !markSummarized {
+		fmt.Println("\n提示：有新记录导入的日期（包括已生成日报的日期）会由后台服务在下次总结时间重新生成日报；如不需要，可使用 --mark-summarized 重新导入（重复记录会被跳过，导入文件涉及的日期都会被标记）")
+	}
+
+	return nil
+}
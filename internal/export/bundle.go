@@ -0,0 +1,92 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BundleResult 打包结果统计
+type BundleResult struct {
+	Entries         int // 导出的工作记录条数
+	DailySummaries  int // 打包的日报数
+	WeeklySummaries int // 打包的周报数
+}
+
+// WriteBundle 将工作记录和日期范围内的日报/周报打包为 zip
+// 包内结构：entries.<format>、summaries/daily/YYYY-MM-DD.md、summaries/weekly/weekly-YYYY-MM-DD.html
+func WriteBundle(w io.Writer, format string, rows []Row, opts Options, summaryDir string) (*BundleResult, error) {
+	result := &BundleResult{Entries: len(rows)}
+	zw := zip.NewWriter(w)
+
+	entriesFile, err := zw.Create("entries." + format)
+	if err != nil {
+		return nil, fmt.Errorf("create entries file: %w", err)
+	}
+	if err := Write(entriesFile, format, rows, opts); err != nil {
+		return nil, err
+	}
+
+	from := opts.From.Format("2006-01-02")
+	to := opts.To.Format("2006-01-02")
+
+	result.DailySummaries, err = addSummaryFiles(zw, summaryDir, "daily", "", ".md", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result.WeeklySummaries, err = addSummaryFiles(zw, summaryDir, "weekly", "weekly-", ".html", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("finalize zip: %w", err)
+	}
+	return result, nil
+}
+
+// addSummaryFiles 将 summaryDir/<kind> 下日期在范围内的 <prefix>YYYY-MM-DD<ext> 文件加入 zip
+func addSummaryFiles(zw *zip.Writer, summaryDir, kind, prefix, ext, from, to string) (int, error) {
+	dir := filepath.Join(summaryDir, kind)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read %s summary directory: %w", kind, err)
+	}
+
+	count := 0
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || filepath.Ext(name) != ext {
+			continue
+		}
+
+		dateStr := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse("2006-01-02", dateStr); err != nil || dateStr < from || dateStr > to {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return count, fmt.Errorf("read summary file %s: %w", name, err)
+		}
+
+		f, err := zw.Create("summaries/" + kind + "/" + name)
+		if err != nil {
+			return count, fmt.Errorf("add summary file %s: %w", name, err)
+		}
+		if _, err := f.Write(content); err != nil {
+			return count, fmt.Errorf("add summary file %s: %w", name, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
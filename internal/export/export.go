@@ -0,0 +1,237 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+	"humg.top/daily_summary/internal/timesheet"
+)
+
+// 导出格式
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatICS      = "ics"
+	FormatMarkdown = "md"
+)
+
+// Formats 支持的导出格式
+var Formats = []string{FormatCSV, FormatJSON, FormatICS, FormatMarkdown}
+
+// FileVersion JSON 导出格式的版本号（import 命令据此识别本工具的导出文件）
+const FileVersion = 1
+
+// Options 导出选项
+type Options struct {
+	From          time.Time          // 起始日期（包含）
+	To            time.Time          // 结束日期（包含）
+	Filter        models.EntryFilter // 按标签/项目过滤
+	DefaultWindow time.Duration      // 当天第一条记录的时间窗口（没有上一条记录可参考）
+	MaxWindow     time.Duration      // 单条记录时间窗口上限，超过时截断（如午休、下班后的第一条记录）
+
+	Breaks         []timesheet.Break // 不计入耗时的休息时段
+	SupplementTags []string          // 补充记录的标签（不占用时间窗口）
+}
+
+// Row 导出的一条工作记录
+// 每条记录是对前一个时间窗口工作内容的总结，因此窗口为 [上一条记录时间, 本条记录时间]
+type Row struct {
+	Entry    models.WorkEntry
+	Date     string        // 日期（YYYY-MM-DD）
+	Start    time.Time     // 时间窗口开始
+	End      time.Time     // 时间窗口结束（即记录时间）
+	Duration time.Duration // 推断的耗时
+}
+
+// Collect 读取日期范围内的工作记录并推断每条记录的耗时
+func Collect(store storage.Storage, opts Options) ([]Row, error) {
+	var rows []Row
+
+	for date := opts.From; !date.After(opts.To); date = date.AddDate(0, 0, 1) {
+		dailyData, err := store.GetDailyData(date)
+		if err != nil {
+			return nil, fmt.Errorf("get daily data for %s: %w", date.Format("2006-01-02"), err)
+		}
+
+		// 先用当天全部记录推断时间窗口，再过滤，避免过滤后窗口被拉长
+		for _, row := range inferWindows(dailyData, opts) {
+			if opts.Filter.Match(row.Entry) {
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// inferWindows 根据相邻记录的时间推断一天内每条记录的时间窗口（与日报中的工时统计使用相同的规则）
+func inferWindows(dailyData *models.DailyData, opts Options) []Row {
+	windows := timesheet.Windows(dailyData.Entries, timesheet.Options{
+		DefaultWindow:  opts.DefaultWindow,
+		MaxWindow:      opts.MaxWindow,
+		Breaks:         opts.Breaks,
+		SupplementTags: opts.SupplementTags,
+	})
+
+	rows := make([]Row, 0, len(windows))
+	for _, window := range windows {
+		rows = append(rows, Row{
+			Entry:    window.Entry,
+			Date:     dailyData.Date,
+			Start:    window.Start,
+			End:      window.End,
+			Duration: window.Duration,
+		})
+	}
+	return rows
+}
+
+// Write 按指定格式输出记录
+func Write(w io.Writer, format string, rows []Row, opts Options) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatJSON:
+		return writeJSON(w, rows, opts)
+	case FormatICS:
+		return writeICS(w, rows)
+	case FormatMarkdown:
+		return writeMarkdown(w, rows, opts)
+	default:
+		return fmt.Errorf("unknown export format: %s (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// csvHeader CSV 导出的列
+var csvHeader = []string{"date", "start", "end", "duration_minutes", "project", "tags", "content", "id"}
+
+// writeCSV 输出 CSV（每条记录一行）
+func writeCSV(w io.Writer, rows []Row) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Date,
+			row.Start.Format("15:04"),
+			row.End.Format("15:04"),
+			strconv.Itoa(int(row.Duration.Minutes())),
+			row.Entry.Project,
+			strings.Join(row.Entry.Tags, " "),
+			row.Entry.Content,
+			row.Entry.ID,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// File JSON 导出文件结构
+type File struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	Entries    []FileEntry `json:"entries"`
+}
+
+// FileEntry JSON 导出的单条记录
+type FileEntry struct {
+	ID              string    `json:"id"`
+	Date            string    `json:"date"`
+	Timestamp       time.Time `json:"timestamp"`
+	Start           time.Time `json:"start"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Project         string    `json:"project,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	Content         string    `json:"content"`
+}
+
+// writeJSON 输出 JSON（import 命令可以直接导入）
+func writeJSON(w io.Writer, rows []Row, opts Options) error {
+	file := File{
+		Version:    FileVersion,
+		ExportedAt: time.Now(),
+		From:       opts.From.Format("2006-01-02"),
+		To:         opts.To.Format("2006-01-02"),
+		Entries:    make([]FileEntry, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		file.Entries = append(file.Entries, FileEntry{
+			ID:              row.Entry.ID,
+			Date:            row.Date,
+			Timestamp:       row.Entry.Timestamp,
+			Start:           row.Start,
+			DurationMinutes: int(row.Duration.Minutes()),
+			Project:         row.Entry.Project,
+			Tags:            row.Entry.Tags,
+			Content:         row.Entry.Content,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// writeMarkdown 输出 Markdown 工时表（每天一个表格，附每日合计）
+func writeMarkdown(w io.Writer, rows []Row, opts Options) error {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# 工作记录 (%s 至 %s)\n", opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02")))
+	if !opts.Filter.IsEmpty() {
+		builder.WriteString(fmt.Sprintf("\n> 过滤条件：%s\n", opts.Filter.String()))
+	}
+
+	var total time.Duration
+	for i := 0; i < len(rows); {
+		date := rows[i].Date
+		var dayTotal time.Duration
+
+		builder.WriteString(fmt.Sprintf("\n## %s\n\n", date))
+		builder.WriteString("| 时间 | 耗时（小时） | 项目 | 内容 |\n")
+		builder.WriteString("|------|-------------|------|------|\n")
+		for ; i < len(rows) && rows[i].Date == date; i++ {
+			row := rows[i]
+			builder.WriteString(fmt.Sprintf("| %s-%s | %s | %s | %s |\n",
+				row.Start.Format("15:04"),
+				row.End.Format("15:04"),
+				formatHours(row.Duration),
+				row.Entry.Project,
+				escapeTableCell(row.Entry.Content)))
+			dayTotal += row.Duration
+		}
+		builder.WriteString(fmt.Sprintf("\n合计：%s 小时\n", formatHours(dayTotal)))
+		total += dayTotal
+	}
+
+	builder.WriteString(fmt.Sprintf("\n---\n\n共 %d 条记录，总计 %s 小时\n", len(rows), formatHours(total)))
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// formatHours 将时长格式化为小时（保留 1 位小数）
+func formatHours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 1, 64)
+}
+
+// escapeTableCell 转义 Markdown 表格单元格中的竖线和换行
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// newTestStore 创建包含两天记录和总结的测试存储
+func newTestStore(t *testing.T) (storage.Storage, string) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	summaryDir := filepath.Join(tmpDir, "summaries")
+	os.MkdirAll(dataDir, 0755)
+
+	store := storage.NewJSONStorage(dataDir, summaryDir)
+	day1 := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 1, 20, 0, 0, 0, 0, time.Local)
+	entries := []models.WorkEntry{
+		{Timestamp: day1.Add(11 * time.Hour), Content: "@billing 对账任务排查 #bug"},
+		{Timestamp: day1.Add(12 * time.Hour), Content: "需求评审, 讨论; 排期"},
+		{Timestamp: day1.Add(18 * time.Hour), Content: "@billing 修复并上线"},
+		{Timestamp: day2.Add(10*time.Hour + 30*time.Minute), Content: "周会"},
+	}
+	for _, entry := range entries {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("Failed to save entry: %v", err)
+		}
+	}
+	store.SaveSummary(day1, "## 主要完成的任务", models.SummaryMetadata{GeneratedAt: day1, EntryCount: 3})
+	os.MkdirAll(filepath.Join(summaryDir, "weekly"), 0755)
+	os.WriteFile(filepath.Join(summaryDir, "weekly", "weekly-2026-01-25.html"), []byte("<html></html>"), 0644)
+
+	return store, summaryDir
+}
+
+// testOptions 返回覆盖测试数据的导出选项
+func testOptions() Options {
+	return Options{
+		From:          time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local),
+		To:            time.Date(2026, 1, 25, 0, 0, 0, 0, time.Local),
+		DefaultWindow: time.Hour,
+		MaxWindow:     2 * time.Hour,
+	}
+}
+
+// TestCollectInfersDurations 测试根据相邻记录推断耗时及过滤
+func TestCollectInfersDurations(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	rows, err := Collect(store, testOptions())
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("Expected 4 rows, got %d", len(rows))
+	}
+
+	// 第一条使用默认窗口，第二条为相邻间隔，第三条超过上限被截断，第二天第一条重新使用默认窗口
+	want := []time.Duration{time.Hour, time.Hour, 2 * time.Hour, time.Hour}
+	for i, row := range rows {
+		if row.Duration != want[i] || !row.End.Equal(row.Entry.Timestamp) || !row.Start.Equal(row.End.Add(-want[i])) {
+			t.Errorf("Row %d: unexpected window %s-%s (%s)", i, row.Start.Format("15:04"), row.End.Format("15:04"), row.Duration)
+		}
+	}
+
+	opts := testOptions()
+	opts.Filter = models.EntryFilter{Project: "billing"}
+	rows, _ = Collect(store, opts)
+	if len(rows) != 2 || rows[1].Duration != 2*time.Hour {
+		t.Errorf("Filtered rows should keep windows inferred from all entries: %+v", rows)
+	}
+}
+
+// TestWriteFormats 测试 CSV/JSON/ICS/Markdown 输出
+func TestWriteFormats(t *testing.T) {
+	store, _ := newTestStore(t)
+	opts := testOptions()
+	rows, _ := Collect(store, opts)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, rows, opts); err != nil {
+		t.Fatalf("Write csv failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid csv: %v", err)
+	}
+	if len(records) != 5 || records[1][3] != "60" || records[1][4] != "billing" || records[2][6] != "需求评审, 讨论; 排期" {
+		t.Errorf("Unexpected csv records: %v", records)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatJSON, rows, opts); err != nil {
+		t.Fatalf("Write json failed: %v", err)
+	}
+	var file File
+	if err := json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatalf("Invalid json: %v", err)
+	}
+	if file.Version != FileVersion || len(file.Entries) != 4 || file.Entries[2].DurationMinutes != 120 {
+		t.Errorf("Unexpected json export: %+v", file)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatICS, rows, opts); err != nil {
+		t.Fatalf("Write ics failed: %v", err)
+	}
+	ics := buf.String()
+	if strings.Count(ics, "BEGIN:VEVENT") != 4 || !strings.Contains(ics, `SUMMARY:需求评审\, 讨论\; 排期`) || !strings.Contains(ics, "CATEGORIES:@billing,#bug") {
+		t.Errorf("Unexpected ics output:\n%s", ics)
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > icsMaxLineOctets {
+			t.Errorf("ics line exceeds %d octets: %q", icsMaxLineOctets, line)
+		}
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatMarkdown, rows, opts); err != nil {
+		t.Fatalf("Write md failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "合计：4.0 小时") || !strings.Contains(buf.String(), "总计 5.0 小时") {
+		t.Errorf("Unexpected markdown output:\n%s", buf.String())
+	}
+
+	if err := Write(&buf, "xlsx", rows, opts); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestFoldICSLine 测试长行折行不拆分多字节字符
+func TestFoldICSLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("工作记录", 20)
+	folded := foldICSLine(line)
+	unfolded := strings.ReplaceAll(folded, "\r\n ", "")
+	if unfolded != line {
+		t.Errorf("Unfolded line mismatch")
+	}
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > icsMaxLineOctets || !utf8.ValidString(part) {
+			t.Errorf("Invalid folded part: %q", part)
+		}
+	}
+}
+
+// TestWriteBundle 测试打包记录和范围内的总结文件
+func TestWriteBundle(t *testing.T) {
+	store, summaryDir := newTestStore(t)
+	opts := testOptions()
+	rows, _ := Collect(store, opts)
+
+	var buf bytes.Buffer
+	result, err := WriteBundle(&buf, FormatCSV, rows, opts, summaryDir)
+	if err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+	if result.Entries != 4 || result.DailySummaries != 1 || result.WeeklySummaries != 1 {
+		t.Errorf("Unexpected bundle result: %+v", result)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Invalid zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := "entries.csv summaries/daily/2026-01-19.md summaries/weekly/weekly-2026-01-25.html"
+	if strings.Join(names, " ") != want {
+		t.Errorf("Unexpected bundle files: %v", names)
+	}
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsMaxLineOctets iCalendar 单行最大字节数（RFC 5545 3.1），超出时折行
+const icsMaxLineOctets = 75
+
+// icsEscaper 转义 iCalendar 文本值中的特殊字符（RFC 5545 3.3.11）
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeICS 输出 iCalendar（每条记录一个事件，时间为推断的时间窗口）
+func writeICS(w io.Writer, rows []Row) error {
+	var builder strings.Builder
+
+	writeLine := func(line string) {
+		builder.WriteString(foldICSLine(line))
+		builder.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//daily_summary//export//CN")
+	writeLine("CALSCALE:GREGORIAN")
+
+	for _, row := range rows {
+		// DTSTAMP 使用记录时间而不是导出时间，保证重复导出结果一致
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + row.Entry.ID + "@daily_summary")
+		writeLine("DTSTAMP:" + formatICSTime(row.End))
+		writeLine("DTSTART:" + formatICSTime(row.Start))
+		writeLine("DTEND:" + formatICSTime(row.End))
+		writeLine("SUMMARY:" + icsEscaper.Replace(row.Entry.Content))
+
+		var categories []string
+		if row.Entry.Project != "" {
+			categories = append(categories, icsEscaper.Replace("@"+row.Entry.Project))
+		}
+		for _, tag := range row.Entry.Tags {
+			categories = append(categories, icsEscaper.Replace("#"+tag))
+		}
+		if len(categories) > 0 {
+			writeLine("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// formatICSTime 格式化为 UTC 时间（如 20260120T020000Z）
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// foldICSLine 将超过 75 字节的行折成多行（续行以空格开头），不拆分 UTF-8 字符
+func foldICSLine(line string) string {
+	if len(line) <= icsMaxLineOctets {
+		return line
+	}
+
+	var builder strings.Builder
+	limit := icsMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// 续行的前导空格占 1 字节
+		limit = icsMaxLineOctets - 1
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
@@ -0,0 +1,61 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestLockSerializesReadModifyWrite 测试文件锁能串行化并发的"读取-修改-写回"
+func TestLockSerializesReadModifyWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	counterFile := filepath.Join(tmpDir, "counter")
+	lockPath := filepath.Join(tmpDir, "counter.lock")
+
+	if err := WriteFileAtomic(counterFile, []byte("0"), 0644); err != nil {
+		t.Fatalf("Failed to init counter: %v", err)
+	}
+
+	const workers = 8
+	const increments = 25
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				lock, err := Lock(lockPath)
+				if err != nil {
+					t.Errorf("Failed to lock: %v", err)
+					return
+				}
+				data, _ := os.ReadFile(counterFile)
+				n, _ := strconv.Atoi(string(data))
+				if err := WriteFileAtomic(counterFile, []byte(strconv.Itoa(n+1)), 0644); err != nil {
+					t.Errorf("Failed to write: %v", err)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(counterFile)
+	if err != nil {
+		t.Fatalf("Failed to read counter: %v", err)
+	}
+	if string(data) != strconv.Itoa(workers*increments) {
+		t.Errorf("Expected counter %d, got %s", workers*increments, data)
+	}
+
+	// 不应残留临时文件
+	files, _ := os.ReadDir(tmpDir)
+	for _, f := range files {
+		if f.Name() != "counter" && f.Name() != "counter.lock" {
+			t.Errorf("Unexpected leftover file: %s", f.Name())
+		}
+	}
+}
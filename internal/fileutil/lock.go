@@ -0,0 +1,44 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileLock 基于锁文件的跨进程排他锁（Unix 下为 flock，Windows 下为 LockFileEx）
+// 锁属于打开的文件句柄，同一进程内的多个 FileLock 之间同样互斥
+type FileLock struct {
+	file *os.File
+}
+
+// Lock 获取指定锁文件的排他锁，阻塞直到获取成功
+// 锁文件不存在时自动创建；锁在 Unlock 或进程退出时释放
+func Lock(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file: %w", err)
+	}
+
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+
+	return &FileLock{file: file}, nil
+}
+
+// Unlock 释放锁
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	l.file.Close()
+	l.file = nil
+	return err
+}
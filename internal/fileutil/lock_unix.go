@@ -0,0 +1,23 @@
+//go:build !windows
+
+package fileutil
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 使用 flock 获取排他锁（EINTR 时重试）
+func lockFile(file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFile 释放 flock 锁
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
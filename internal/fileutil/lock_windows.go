@@ -0,0 +1,23 @@
+//go:build windows
+
+package fileutil
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockRange 锁定整个文件（LockFileEx 按字节范围加锁，锁定最大范围即可覆盖全部内容）
+const lockRange = ^uint32(0)
+
+// lockFile 使用 LockFileEx 获取排他锁（阻塞直到获取成功）
+// 与 Unix 下的 flock 相同，锁属于打开的文件句柄，同一进程内的不同 FileLock 之间同样互斥
+func lockFile(file *os.File) error {
+	return windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, lockRange, lockRange, new(windows.Overlapped))
+}
+
+// unlockFile 释放 LockFileEx 获取的锁
+func unlockFile(file *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(file.Fd()), 0, lockRange, lockRange, new(windows.Overlapped))
+}
@@ -0,0 +1,356 @@
+package fsck
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/fileutil"
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// Kind 问题类型
+type Kind string
+
+const (
+	KindCorrupt          Kind = "corrupt"           // 无法解析的数据文件
+	KindDateMismatch     Kind = "date_mismatch"     // 文件内 date 字段与文件名不一致
+	KindWrongDay         Kind = "wrong_day"         // 记录时间不在文件对应的日期
+	KindDuplicate        Kind = "duplicate"         // 时间和内容都相同的重复记录
+	KindDuplicateID      Kind = "duplicate_id"      // 不同记录使用了相同的 ID
+	KindMissingSummary   Kind = "missing_summary"   // 标记为已生成总结，但日报文件不存在
+	KindUnflaggedSummary Kind = "unflagged_summary" // 日报文件存在，但未标记为已生成
+	KindOrphanSummary    Kind = "orphan_summary"    // 日报文件没有对应的数据文件
+)
+
+// Issue 检查发现的问题
+type Issue struct {
+	Kind     Kind
+	Date     string // 问题所在的日期（YYYY-MM-DD）
+	EntryID  string // 相关记录 ID（如果有）
+	Message  string // 问题描述
+	Repaired bool   // 是否已修复
+}
+
+// Report 检查结果
+type Report struct {
+	Files   int     // 检查的数据文件数
+	Entries int     // 检查的记录数
+	Issues  []Issue // 发现的问题
+}
+
+// Options 检查选项
+type Options struct {
+	DataDir       string // 每日 JSON 数据目录
+	SummaryDir    string // 总结目录
+	QuarantineDir string // 修复时隔离无法解析的文件的目录
+	Repair        bool   // 是否修复
+}
+
+// day 一个数据文件的状态
+type day struct {
+	date    string
+	path    string
+	data    *models.DailyData
+	modTime time.Time
+	dirty   bool // 有修改，修复模式下需要写回
+}
+
+// Run 检查数据目录（Repair 为 true 时同时修复）
+// 修复时持有数据目录锁；无法解析的文件移动到 QuarantineDir，不会被删除
+func Run(opts Options) (*Report, error) {
+	if opts.Repair {
+		if err := os.MkdirAll(opts.DataDir, 0755); err != nil {
+			return nil, fmt.Errorf("create data directory: %w", err)
+		}
+		lock, err := fileutil.Lock(filepath.Join(opts.DataDir, storage.DataLockFile))
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
+	c := &checker{opts: opts, report: &Report{}, days: make(map[string]*day)}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	c.checkSummaryFlags()
+	c.checkEntries()
+	if err := c.checkOrphanSummaries(); err != nil {
+		return nil, err
+	}
+
+	if opts.Repair {
+		if err := c.save(); err != nil {
+			return c.report, err
+		}
+	}
+	return c.report, nil
+}
+
+// checker 单次检查的状态
+type checker struct {
+	opts   Options
+	report *Report
+	days   map[string]*day
+	order  []string // 日期（从旧到新）
+}
+
+// addIssue 记录问题
+func (c *checker) addIssue(kind Kind, date, entryID, message string) {
+	c.report.Issues = append(c.report.Issues, Issue{
+		Kind:     kind,
+		Date:     date,
+		EntryID:  entryID,
+		Message:  message,
+		Repaired: c.opts.Repair,
+	})
+}
+
+// load 读取所有数据文件，无法解析的文件记为 corrupt（修复时隔离）
+func (c *checker) load() error {
+	files, err := os.ReadDir(c.opts.DataDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read data directory: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		date := strings.TrimSuffix(name, ".json")
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			continue
+		}
+
+		path := filepath.Join(c.opts.DataDir, name)
+		info, err := file.Info()
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", name, err)
+		}
+		c.report.Files++
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		var dailyData models.DailyData
+		if err := json.Unmarshal(data, &dailyData); err != nil {
+			c.addIssue(KindCorrupt, date, "", fmt.Sprintf("无法解析 %s: %v", name, err))
+			if c.opts.Repair {
+				if err := c.quarantine(path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		c.days[date] = &day{date: date, path: path, data: &dailyData, modTime: info.ModTime()}
+		c.order = append(c.order, date)
+	}
+
+	sort.Strings(c.order)
+	return nil
+}
+
+// quarantine 将文件移动到隔离目录（文件名附加时间戳，避免覆盖之前隔离的文件）
+func (c *checker) quarantine(path string) error {
+	if err := os.MkdirAll(c.opts.QuarantineDir, 0755); err != nil {
+		return fmt.Errorf("create quarantine directory: %w", err)
+	}
+	target := filepath.Join(c.opts.QuarantineDir, fmt.Sprintf("%s.%s", filepath.Base(path), time.Now().Format("20060102-150405")))
+	if err := os.Rename(path, target); err != nil {
+		return fmt.Errorf("quarantine %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// summaryPath 返回日报文件路径
+func (c *checker) summaryPath(date string) string {
+	return filepath.Join(c.opts.SummaryDir, "daily", date+".md")
+}
+
+// checkSummaryFlags 核对 SummaryGenerated 标记与日报文件
+// 日报存在但未标记时，只有日报晚于数据文件最后修改时间才补标记；
+// 否则说明记录在生成日报后被修改过，保持未标记以便重新生成
+func (c *checker) checkSummaryFlags() {
+	for _, date := range c.order {
+		d := c.days[date]
+		info, err := os.Stat(c.summaryPath(date))
+		hasSummary := err == nil
+
+		switch {
+		case d.data.SummaryGenerated && !hasSummary:
+			c.addIssue(KindMissingSummary, date, "", "已标记生成日报，但日报文件不存在（修复后将重新生成）")
+			d.data.SummaryGenerated = false
+			d.dirty = true
+		case !d.data.SummaryGenerated && hasSummary && len(d.data.Entries) > 0 && !info.ModTime().Before(d.modTime):
+			c.addIssue(KindUnflaggedSummary, date, "", "日报文件已存在，但未标记为已生成")
+			d.data.SummaryGenerated = true
+			d.dirty = true
+		}
+	}
+}
+
+// checkEntries 检查日期字段、记录所在日期、重复记录和重复 ID
+func (c *checker) checkEntries() {
+	type move struct {
+		entry models.WorkEntry
+		from  string
+	}
+	var moves []move
+
+	seenIDs := make(map[string]string) // ID -> 所在日期
+	for _, date := range c.order {
+		d := c.days[date]
+		if d.data.Date != date {
+			c.addIssue(KindDateMismatch, date, "", fmt.Sprintf("文件中的日期为 %q", d.data.Date))
+			d.data.Date = date
+			d.dirty = true
+		}
+
+		seenKeys := make(map[string]bool)
+		kept := d.data.Entries[:0]
+		for _, entry := range d.data.Entries {
+			c.report.Entries++
+
+			key := entryKey(entry)
+			if seenKeys[key] {
+				c.addIssue(KindDuplicate, date, entry.ID, fmt.Sprintf("重复记录 %s %s", entry.Timestamp.Format("15:04"), entry.Content))
+				d.dirty = true
+				continue
+			}
+			seenKeys[key] = true
+
+			if entry.ID != "" {
+				if other, ok := seenIDs[entry.ID]; ok {
+					newID := models.NewEntryID(entry.Timestamp)
+					c.addIssue(KindDuplicateID, date, entry.ID, fmt.Sprintf("ID 与 %s 的记录重复（修复后改为 %s）", other, newID))
+					entry.ID = newID
+					d.dirty = true
+				}
+				seenIDs[entry.ID] = date
+			}
+
+			if entryDate := entry.Timestamp.Format("2006-01-02"); entryDate != date {
+				c.addIssue(KindWrongDay, date, entry.ID, fmt.Sprintf("记录时间为 %s（修复后移动到对应日期）", entry.Timestamp.Format("2006-01-02 15:04")))
+				moves = append(moves, move{entry: entry, from: date})
+				d.data.SummaryGenerated = false
+				d.dirty = true
+				continue
+			}
+
+			kept = append(kept, entry)
+		}
+		d.data.Entries = kept
+	}
+
+	for _, m := range moves {
+		date := m.entry.Timestamp.Format("2006-01-02")
+		target, ok := c.days[date]
+		if !ok {
+			target = &day{
+				date: date,
+				path: filepath.Join(c.opts.DataDir, date+".json"),
+				data: &models.DailyData{SchemaVersion: c.days[m.from].data.SchemaVersion, Date: date, Entries: []models.WorkEntry{}},
+			}
+			c.days[date] = target
+		}
+
+		entry := m.entry
+		duplicate := false
+		for _, existing := range target.data.Entries {
+			if entryKey(existing) == entryKey(entry) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		// ID 的日期前缀用于定位记录所在的文件，移动后需要重新生成
+		if entry.ID != "" {
+			entry.ID = models.NewEntryID(entry.Timestamp)
+		}
+		i := sort.Search(len(target.data.Entries), func(i int) bool {
+			return target.data.Entries[i].Timestamp.After(entry.Timestamp)
+		})
+		target.data.Entries = append(target.data.Entries, models.WorkEntry{})
+		copy(target.data.Entries[i+1:], target.data.Entries[i:])
+		target.data.Entries[i] = entry
+
+		// 与编辑记录一致：记录变化后重新生成该日的日报
+		target.data.SummaryGenerated = false
+		target.dirty = true
+	}
+}
+
+// checkOrphanSummaries 检查没有对应数据文件的日报（只报告，不修复）
+func (c *checker) checkOrphanSummaries() error {
+	files, err := os.ReadDir(filepath.Join(c.opts.SummaryDir, "daily"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read daily summary directory: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || filepath.Ext(name) != ".md" {
+			continue
+		}
+		date := strings.TrimSuffix(name, ".md")
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			continue
+		}
+		if _, ok := c.days[date]; ok {
+			continue
+		}
+		c.report.Issues = append(c.report.Issues, Issue{
+			Kind:    KindOrphanSummary,
+			Date:    date,
+			Message: "日报文件没有对应的数据文件",
+		})
+	}
+	return nil
+}
+
+// save 写回修复过的数据文件
+func (c *checker) save() error {
+	dates := make([]string, 0, len(c.days))
+	for date := range c.days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		d := c.days[date]
+		if !d.dirty {
+			continue
+		}
+		data, err := json.MarshalIndent(d.data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", date, err)
+		}
+		if err := fileutil.WriteFileAtomic(d.path, data, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", date, err)
+		}
+	}
+	return nil
+}
+
+// entryKey 判断重复记录的键：时间戳 + 内容
+func entryKey(entry models.WorkEntry) string {
+	return entry.Timestamp.Format(time.RFC3339Nano) + "\x00" + entry.Content
+}
@@ -0,0 +1,163 @@
+package fsck
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// newTestOptions 创建包含各类问题的测试数据目录
+func newTestOptions(t *testing.T) Options {
+	tmpDir := t.TempDir()
+	opts := Options{
+		DataDir:       filepath.Join(tmpDir, "data"),
+		SummaryDir:    filepath.Join(tmpDir, "summaries"),
+		QuarantineDir: filepath.Join(tmpDir, "quarantine"),
+	}
+	os.MkdirAll(opts.DataDir, 0755)
+	os.MkdirAll(filepath.Join(opts.SummaryDir, "daily"), 0755)
+
+	day := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	entry := models.WorkEntry{ID: "20260119-aaaaaa", Timestamp: day.Add(11 * time.Hour), Content: "对账任务排查"}
+	writeDailyData(t, opts.DataDir, "2026-01-19", models.DailyData{
+		Date: "2026-01-18",
+		Entries: []models.WorkEntry{
+			entry,
+			entry,
+			{ID: "20260119-aaaaaa", Timestamp: day.Add(12 * time.Hour), Content: "需求评审"},
+			{ID: "20260119-bbbbbb", Timestamp: day.Add(34 * time.Hour), Content: "周会"},
+		},
+		SummaryGenerated: true,
+	})
+	writeDailyData(t, opts.DataDir, "2026-01-21", models.DailyData{
+		Date:    "2026-01-21",
+		Entries: []models.WorkEntry{{ID: "20260121-cccccc", Timestamp: day.Add(50 * time.Hour), Content: "发布"}},
+	})
+	os.WriteFile(filepath.Join(opts.DataDir, "2026-01-22.json"), []byte(`{"date": "2026-01-22", "entries": [`), 0644)
+
+	// 01-21 的日报晚于数据文件生成但未标记；01-18 的日报没有数据文件
+	future := time.Now().Add(time.Hour)
+	os.WriteFile(filepath.Join(opts.SummaryDir, "daily", "2026-01-21.md"), []byte("# 工作总结"), 0644)
+	os.Chtimes(filepath.Join(opts.SummaryDir, "daily", "2026-01-21.md"), future, future)
+	os.WriteFile(filepath.Join(opts.SummaryDir, "daily", "2026-01-18.md"), []byte("# 工作总结"), 0644)
+
+	return opts
+}
+
+// writeDailyData 写入测试数据文件
+func writeDailyData(t *testing.T, dataDir, date string, dailyData models.DailyData) {
+	data, err := json.MarshalIndent(dailyData, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal daily data: %v", err)
+	}
+	os.WriteFile(filepath.Join(dataDir, date+".json"), data, 0644)
+}
+
+// countKinds 统计各类问题的数量
+func countKinds(report *Report) map[Kind]int {
+	counts := make(map[Kind]int)
+	for _, issue := range report.Issues {
+		counts[issue.Kind]++
+	}
+	return counts
+}
+
+// TestCheck 测试只检查不修改
+func TestCheck(t *testing.T) {
+	opts := newTestOptions(t)
+	before, _ := os.ReadFile(filepath.Join(opts.DataDir, "2026-01-19.json"))
+
+	report, err := Run(opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Files != 3 || report.Entries != 5 {
+		t.Errorf("Expected 3 files and 5 entries, got %d files and %d entries", report.Files, report.Entries)
+	}
+
+	want := map[Kind]int{
+		KindCorrupt:          1,
+		KindDateMismatch:     1,
+		KindDuplicate:        1,
+		KindDuplicateID:      1,
+		KindWrongDay:         1,
+		KindMissingSummary:   1,
+		KindUnflaggedSummary: 1,
+		KindOrphanSummary:    1,
+	}
+	got := countKinds(report)
+	for kind, n := range want {
+		if got[kind] != n {
+			t.Errorf("Expected %d %s issue(s), got %d", n, kind, got[kind])
+		}
+	}
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			t.Errorf("Issue should not be marked repaired in check mode: %+v", issue)
+		}
+	}
+
+	after, _ := os.ReadFile(filepath.Join(opts.DataDir, "2026-01-19.json"))
+	if string(before) != string(after) {
+		t.Error("Check mode should not modify data files")
+	}
+}
+
+// TestRepair 测试修复后数据可以被存储层正常读取且再次检查无问题
+func TestRepair(t *testing.T) {
+	opts := newTestOptions(t)
+	opts.Repair = true
+
+	if _, err := Run(opts); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(opts.DataDir, "2026-01-22.json")); !os.IsNotExist(err) {
+		t.Error("Corrupt file should be moved out of the data directory")
+	}
+	quarantined, _ := os.ReadDir(opts.QuarantineDir)
+	if len(quarantined) != 1 {
+		t.Errorf("Expected 1 quarantined file, got %d", len(quarantined))
+	}
+
+	store := storage.NewJSONStorage(opts.DataDir, opts.SummaryDir)
+	day := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	data, err := store.GetDailyData(day)
+	if err != nil {
+		t.Fatalf("GetDailyData failed: %v", err)
+	}
+	if data.Date != "2026-01-19" || len(data.Entries) != 2 || data.SummaryGenerated {
+		t.Errorf("Unexpected repaired data: %+v", data)
+	}
+	if data.Entries[0].ID == data.Entries[1].ID {
+		t.Error("Duplicate IDs should be reassigned")
+	}
+
+	moved, _ := store.GetDailyData(day.AddDate(0, 0, 1))
+	if len(moved.Entries) != 1 || moved.Entries[0].Content != "周会" {
+		t.Fatalf("Expected entry moved to 2026-01-20, got %+v", moved.Entries)
+	}
+	if _, err := store.GetEntry(moved.Entries[0].ID); err != nil {
+		t.Errorf("Moved entry should be reachable by its new ID: %v", err)
+	}
+
+	flagged, _ := store.GetDailyData(day.AddDate(0, 0, 2))
+	if !flagged.SummaryGenerated {
+		t.Error("Expected 2026-01-21 to be marked as summarized")
+	}
+
+	// 再次检查只剩无法自动修复的孤立日报
+	opts.Repair = false
+	report, err := Run(opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if counts := countKinds(report); len(report.Issues) != 1 || counts[KindOrphanSummary] != 1 {
+		t.Errorf("Expected only the orphan summary after repair, got %+v", report.Issues)
+	}
+}
@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// 导入格式
+const (
+	FormatCSV    = "csv"           // 通用 CSV（列映射）
+	FormatToggl  = "toggl"         // Toggl 风格的详细报表 CSV
+	FormatJSONL  = "jsonl"         // 每行一个 JSON 对象
+	FormatExport = "daily_summary" // 本工具 export --format json 的输出
+)
+
+// 可映射的字段
+const (
+	FieldTimestamp = "timestamp" // 完整时间（日期 + 时间）
+	FieldDate      = "date"      // 日期（与 time 组合使用）
+	FieldTime      = "time"      // 时间
+	FieldContent   = "content"   // 工作内容
+	FieldProject   = "project"   // 项目
+	FieldTags      = "tags"      // 标签（逗号或分号分隔）
+)
+
+// csvDefaultColumns 通用 CSV 未指定映射时，各字段按顺序尝试的列名（不区分大小写）
+var csvDefaultColumns = map[string][]string{
+	FieldTimestamp: {"timestamp", "datetime", "记录时间", "时间戳"},
+	FieldDate:      {"date", "日期"},
+	FieldTime:      {"time", "时间"},
+	FieldContent:   {"content", "description", "note", "notes", "内容", "工作内容", "描述"},
+	FieldProject:   {"project", "项目"},
+	FieldTags:      {"tags", "tag", "标签"},
+}
+
+// togglColumns Toggl 详细报表的列映射
+// 每条记录是对前一段时间工作的总结，因此记录时间取时间段的结束时间
+var togglColumns = map[string]string{
+	FieldDate:    "End date",
+	FieldTime:    "End time",
+	FieldContent: "Description",
+	FieldProject: "Project",
+	FieldTags:    "Tags",
+}
+
+func init() {
+	Register(FormatCSV, func(opts ParserOptions) Parser {
+		return &CSVParser{Columns: opts.Columns, TimeLayout: opts.TimeLayout}
+	})
+	Register(FormatToggl, func(opts ParserOptions) Parser {
+		columns := make(map[string]string)
+		for field, column := range togglColumns {
+			columns[field] = column
+		}
+		for field, column := range opts.Columns {
+			columns[field] = column
+		}
+		return &CSVParser{Columns: columns, TimeLayout: opts.TimeLayout}
+	})
+}
+
+// CSVParser 通用 CSV 解析器
+// 第一行为表头；时间取 timestamp 列，或 date + time 两列组合
+type CSVParser struct {
+	Columns    map[string]string // 字段 -> 列名；未指定的字段按 csvDefaultColumns 自动识别
+	TimeLayout string
+}
+
+// Parse 解析 CSV
+func (p *CSVParser) Parse(r io.Reader) (*ParseResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return &ParseResult{}, nil
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Excel 导出的 UTF-8 BOM
+	}
+
+	index, err := p.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ParseResult{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		entry, err := p.parseRecord(record, index)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+		if entry != nil {
+			result.Entries = append(result.Entries, *entry)
+		}
+	}
+
+	return result, nil
+}
+
+// resolveColumns 计算各字段对应的列下标
+func (p *CSVParser) resolveColumns(header []string) (map[string]int, error) {
+	find := func(name string) int {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for field := range p.Columns {
+		if _, ok := csvDefaultColumns[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q in column mapping", field)
+		}
+	}
+
+	index := make(map[string]int)
+	for field, candidates := range csvDefaultColumns {
+		if column, ok := p.Columns[field]; ok {
+			i := find(column)
+			if i < 0 {
+				return nil, fmt.Errorf("column %q for field %s not found in csv header", column, field)
+			}
+			index[field] = i
+			continue
+		}
+		for _, candidate := range candidates {
+			if i := find(candidate); i >= 0 {
+				index[field] = i
+				break
+			}
+		}
+	}
+
+	if _, ok := index[FieldContent]; !ok {
+		return nil, errors.New("csv has no content column (use --map content=<column>)")
+	}
+	_, hasTimestamp := index[FieldTimestamp]
+	_, hasDate := index[FieldDate]
+	_, hasTime := index[FieldTime]
+	if !hasTimestamp && !(hasDate && hasTime) {
+		return nil, errors.New("csv has no timestamp column or date/time columns (use --map timestamp=<column>)")
+	}
+
+	return index, nil
+}
+
+// parseRecord 解析一行数据；内容为空的行返回 nil
+func (p *CSVParser) parseRecord(record []string, index map[string]int) (*models.WorkEntry, error) {
+	get := func(field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	content := get(FieldContent)
+	if content == "" {
+		return nil, nil
+	}
+
+	value := get(FieldTimestamp)
+	if value == "" {
+		value = strings.TrimSpace(get(FieldDate) + " " + get(FieldTime))
+	}
+	timestamp, err := parseTime(value, p.TimeLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := strings.FieldsFunc(get(FieldTags), func(r rune) bool {
+		return r == ',' || r == ';' || r == '，' || r == '；'
+	})
+
+	return &models.WorkEntry{
+		Timestamp: timestamp,
+		Content:   withMeta(content, get(FieldProject), tags),
+	}, nil
+}
@@ -0,0 +1,240 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// Parser 将外部数据解析为工作记录
+type Parser interface {
+	// Parse 解析输入；无法解析的行记入 ParseResult.Errors，不中断整体解析
+	Parse(r io.Reader) (*ParseResult, error)
+}
+
+// ParseResult 解析结果
+type ParseResult struct {
+	Entries []models.WorkEntry
+	Errors  []RowError
+}
+
+// RowError 单行解析错误
+type RowError struct {
+	Line int   // 行号（从 1 开始，CSV 包含表头行；导出文件中为记录序号）
+	Err  error // 错误原因
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// ParserOptions 解析器选项
+type ParserOptions struct {
+	Columns    map[string]string // 字段映射：字段名（timestamp/date/time/content/project/tags）-> 列名
+	TimeLayout string            // 时间格式（Go layout），为空时自动识别常见格式
+}
+
+// Factory 根据选项创建解析器
+type Factory func(opts ParserOptions) Parser
+
+// registry 已注册的解析器（格式名 -> 工厂函数）
+var registry = make(map[string]Factory)
+
+// Register 注册解析器，同名格式会被覆盖
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// NewParser 根据格式名创建解析器
+func NewParser(name string, opts ParserOptions) (Parser, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format: %s (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(opts), nil
+}
+
+// Names 返回已注册的格式名（按字母排序）
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectFormat 根据文件扩展名推断格式，无法识别时返回空字符串
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".json":
+		return FormatExport
+	default:
+		return ""
+	}
+}
+
+// Result 导入结果
+type Result struct {
+	Entries    []models.WorkEntry // 新导入的记录（dry-run 时为将要导入的记录）
+	Duplicates int                // 与已有记录或本次输入重复而跳过的条数
+	Dates      []time.Time        // 有新记录导入的日期
+	InputDates []time.Time        // 输入中涉及的所有日期（包括记录全部重复的日期）
+}
+
+// Import 将记录写入存储，按时间戳（精确到分钟）+ 内容去重
+// dryRun 为 true 时只计算将要导入的记录，不写入
+func Import(store storage.Storage, entries []models.WorkEntry, dryRun bool) (*Result, error) {
+	sorted := make([]models.WorkEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	result := &Result{}
+	seen := make(map[string]map[string]bool) // 日期 -> 去重键
+	for _, entry := range sorted {
+		dateStr := entry.Timestamp.Format("2006-01-02")
+
+		keys, ok := seen[dateStr]
+		if !ok {
+			result.InputDates = append(result.InputDates, entry.Timestamp)
+			dailyData, err := store.GetDailyData(entry.Timestamp)
+			if err != nil {
+				return result, fmt.Errorf("get daily data for %s: %w", dateStr, err)
+			}
+			keys = make(map[string]bool)
+			for _, existing := range dailyData.Entries {
+				keys[dedupKey(existing)] = true
+				keys["id:"+existing.ID] = true
+			}
+			seen[dateStr] = keys
+		}
+
+		if keys[dedupKey(entry)] || (entry.ID != "" && keys["id:"+entry.ID]) {
+			result.Duplicates++
+			continue
+		}
+		keys[dedupKey(entry)] = true
+
+		// ID 的日期前缀必须与记录日期一致，否则由存储层重新生成
+		if entry.ID != "" {
+			if date, err := models.EntryIDDate(entry.ID); err != nil || date.Format("2006-01-02") != dateStr {
+				entry.ID = ""
+			} else {
+				keys["id:"+entry.ID] = true
+			}
+		}
+
+		if !dryRun {
+			if err := store.SaveEntry(entry); err != nil {
+				return result, fmt.Errorf("save entry at %s: %w", entry.Timestamp.Format("2006-01-02 15:04"), err)
+			}
+		}
+
+		entry.ParseMeta()
+		result.Entries = append(result.Entries, entry)
+		if n := len(result.Dates); n == 0 || result.Dates[n-1].Format("2006-01-02") != dateStr {
+			result.Dates = append(result.Dates, entry.Timestamp)
+		}
+	}
+
+	return result, nil
+}
+
+// dedupKey 去重键：时间戳精确到分钟（表格通常只记录到分钟）+ 去除首尾空白的内容
+func dedupKey(entry models.WorkEntry) string {
+	return entry.Timestamp.Truncate(time.Minute).Format(time.RFC3339) + "\x00" + strings.TrimSpace(entry.Content)
+}
+
+// timeLayouts 自动识别的时间格式
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+}
+
+// parseTime 按指定格式或常见格式解析时间（不带时区的时间按本地时区处理）
+func parseTime(value, layout string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if layout != "" {
+		return time.ParseInLocation(layout, value, time.Local)
+	}
+	for _, l := range timeLayouts {
+		if t, err := time.ParseInLocation(l, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time format: %q", value)
+}
+
+// withMeta 将独立的项目/标签字段以 @项目 / #标签 的形式追加到内容中，
+// 使存储层解析出与手动录入一致的 Tags/Project（内容中已有的不重复追加）
+func withMeta(content, project string, tags []string) string {
+	parsedTags, parsedProject := models.ParseEntryMeta(content)
+
+	var suffix []string
+	if project = toToken(project); project != "" && parsedProject == "" {
+		suffix = append(suffix, "@"+project)
+	}
+
+	existing := make(map[string]bool)
+	for _, tag := range parsedTags {
+		existing[tag] = true
+	}
+	for _, tag := range tags {
+		tag = toToken(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag != "" && !existing[strings.ToLower(tag)] {
+			existing[strings.ToLower(tag)] = true
+			suffix = append(suffix, "#"+tag)
+		}
+	}
+
+	if len(suffix) == 0 {
+		return content
+	}
+	return strings.TrimSpace(content + " " + strings.Join(suffix, " "))
+}
+
+// toToken 将名称中的空白替换为 "-"，使其可以作为 @项目 / #标签 解析
+func toToken(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// UpdateSummaryFlags 导入后更新日报标记
+// markSummarized 为 true 时将输入涉及的所有日期标记为已生成日报（重复导入时同样生效），避免后台服务批量补生成；
+// 否则清除有新记录导入的日期的标记，使已生成的日报在下次总结时包含新记录（与修改、删除记录一致）
+func UpdateSummaryFlags(store storage.Storage, result *Result, markSummarized bool) error {
+	if markSummarized {
+		for _, date := range result.InputDates {
+			if err := store.MarkSummaryGenerated(date); err != nil {
+				return fmt.Errorf("mark %s as summarized: %w", date.Format("2006-01-02"), err)
+			}
+		}
+		return nil
+	}
+	for _, date := range result.Dates {
+		if err := store.ResetSummaryGenerated(date); err != nil {
+			return fmt.Errorf("reset summary flag for %s: %w", date.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
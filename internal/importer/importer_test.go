@@ -0,0 +1,252 @@
+package importer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/export"
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// newTestStore 创建空的 JSON 测试存储
+func newTestStore(t *testing.T) storage.Storage {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	os.MkdirAll(dataDir, 0755)
+	return storage.NewJSONStorage(dataDir, filepath.Join(tmpDir, "summaries"))
+}
+
+// parse 使用指定格式解析输入
+func parse(t *testing.T, format string, opts ParserOptions, input string) *ParseResult {
+	t.Helper()
+	parser, err := NewParser(format, opts)
+	if err != nil {
+		t.Fatalf("NewParser(%s) failed: %v", format, err)
+	}
+	result, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return result
+}
+
+// TestCSVParser 测试通用 CSV 的自动识别列、列映射和项目/标签列
+func TestCSVParser(t *testing.T) {
+	input := "\ufeff日期,时间,工作内容,项目,标签\n" +
+		"2026-01-19,11:00,对账任务排查,billing,\"bug,线上问题\"\n" +
+		"2026-01-19,12:30,,billing,\n" +
+		"2026-01-19,bad,需求评审,,\n" +
+		"2026-01-19,18:00,@billing 修复并上线 #bug,,bug\n"
+
+	result := parse(t, FormatCSV, ParserOptions{}, input)
+	if len(result.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(result.Entries))
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 4 {
+		t.Fatalf("Expected 1 error at line 4, got %v", result.Errors)
+	}
+
+	first := result.Entries[0]
+	if want := time.Date(2026, 1, 19, 11, 0, 0, 0, time.Local); !first.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, first.Timestamp)
+	}
+	if first.Content != "对账任务排查 @billing #bug #线上问题" {
+		t.Errorf("Unexpected content: %q", first.Content)
+	}
+	// 内容中已有的项目/标签不重复追加
+	if second := result.Entries[1].Content; second != "@billing 修复并上线 #bug" {
+		t.Errorf("Unexpected content: %q", second)
+	}
+
+	mapped := parse(t, FormatCSV, ParserOptions{
+		Columns:    map[string]string{FieldTimestamp: "When", FieldContent: "What"},
+		TimeLayout: "2006/01/02 15:04",
+	}, "When,What\n2026/01/20 09:15,周会\n")
+	if len(mapped.Entries) != 1 || mapped.Entries[0].Content != "周会" ||
+		!mapped.Entries[0].Timestamp.Equal(time.Date(2026, 1, 20, 9, 15, 0, 0, time.Local)) {
+		t.Errorf("Unexpected mapped entries: %+v", mapped.Entries)
+	}
+
+	parser, _ := NewParser(FormatCSV, ParserOptions{Columns: map[string]string{"duration": "Duration"}})
+	if _, err := parser.Parse(strings.NewReader("Duration,content\n")); err == nil {
+		t.Error("Expected error for unknown mapping field")
+	}
+	parser, _ = NewParser(FormatCSV, ParserOptions{})
+	if _, err := parser.Parse(strings.NewReader("content\n")); err == nil {
+		t.Error("Expected error for missing timestamp column")
+	}
+}
+
+// TestTogglParser 测试 Toggl 报表使用结束时间作为记录时间
+func TestTogglParser(t *testing.T) {
+	input := "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\n" +
+		"me,me@example.com,,Billing API,,对账任务排查,No,2026-01-19,09:00:00,2026-01-19,11:00:00,02:00:00,bug\n"
+
+	result := parse(t, FormatToggl, ParserOptions{}, input)
+	if len(result.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d (errors: %v)", len(result.Entries), result.Errors)
+	}
+	entry := result.Entries[0]
+	if want := time.Date(2026, 1, 19, 11, 0, 0, 0, time.Local); !entry.Timestamp.Equal(want) {
+		t.Errorf("Expected end time %v, got %v", want, entry.Timestamp)
+	}
+	if entry.Content != "对账任务排查 @Billing-API #bug" {
+		t.Errorf("Unexpected content: %q", entry.Content)
+	}
+}
+
+// TestJSONLParser 测试 JSON Lines 解析
+func TestJSONLParser(t *testing.T) {
+	input := `{"timestamp": "2026-01-19T11:00:00+08:00", "content": "对账任务排查", "project": "billing", "tags": ["bug"]}
+
+{"timestamp": "2026-01-19 12:00", "content": "需求评审"}
+not json
+{"timestamp": "2026-01-19 13:00", "content": ""}
+`
+	result := parse(t, FormatJSONL, ParserOptions{}, input)
+	if len(result.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(result.Entries))
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Line != 4 || result.Errors[1].Line != 5 {
+		t.Errorf("Expected errors at lines 4 and 5, got %v", result.Errors)
+	}
+	if result.Entries[0].Content != "对账任务排查 @billing #bug" {
+		t.Errorf("Unexpected content: %q", result.Entries[0].Content)
+	}
+}
+
+// TestImportDedupAndDryRun 测试去重和 dry-run
+func TestImportDedupAndDryRun(t *testing.T) {
+	store := newTestStore(t)
+	day := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	if err := store.SaveEntry(models.WorkEntry{Timestamp: day.Add(11*time.Hour + 20*time.Second), Content: "对账任务排查"}); err != nil {
+		t.Fatalf("Failed to save entry: %v", err)
+	}
+
+	entries := []models.WorkEntry{
+		{Timestamp: day.Add(18 * time.Hour), Content: "修复并上线"},
+		{Timestamp: day.Add(11 * time.Hour), Content: "对账任务排查 "},
+		{Timestamp: day.Add(24*time.Hour + 10*time.Hour), Content: "周会"},
+		{Timestamp: day.Add(18 * time.Hour), Content: "修复并上线"},
+	}
+
+	result, err := Import(store, entries, true)
+	if err != nil {
+		t.Fatalf("Dry-run import failed: %v", err)
+	}
+	if len(result.Entries) != 2 || result.Duplicates != 2 || len(result.Dates) != 2 {
+		t.Fatalf("Unexpected dry-run result: entries=%d duplicates=%d dates=%d",
+			len(result.Entries), result.Duplicates, len(result.Dates))
+	}
+	if data, _ := store.GetDailyData(day); len(data.Entries) != 1 {
+		t.Fatalf("Dry-run should not write, got %d entries", len(data.Entries))
+	}
+
+	if _, err := Import(store, entries, false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	data, _ := store.GetDailyData(day)
+	if len(data.Entries) != 2 || data.Entries[1].Content != "修复并上线" {
+		t.Fatalf("Unexpected entries after import: %+v", data.Entries)
+	}
+
+	// 重复导入不产生新记录
+	again, err := Import(store, entries, false)
+	if err != nil {
+		t.Fatalf("Re-import failed: %v", err)
+	}
+	if len(again.Entries) != 0 || again.Duplicates != 4 {
+		t.Errorf("Expected all entries to be duplicates, got %d new, %d duplicates", len(again.Entries), again.Duplicates)
+	}
+}
+
+// TestImportSummaryFlags 测试导入后的日报标记：新记录清除已生成标记；--mark-summarized 重复导入时也标记所有日期
+func TestImportSummaryFlags(t *testing.T) {
+	store := newTestStore(t)
+	day := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	next := day.AddDate(0, 0, 1)
+	store.SaveEntry(models.WorkEntry{Timestamp: day.Add(10 * time.Hour), Content: "对账任务排查"})
+	store.MarkSummaryGenerated(day)
+
+	entries := []models.WorkEntry{
+		{Timestamp: day.Add(18 * time.Hour), Content: "修复并上线"},
+		{Timestamp: next.Add(10 * time.Hour), Content: "周会"},
+	}
+	summarized := func(date time.Time) bool {
+		data, _ := store.GetDailyData(date)
+		return data.SummaryGenerated
+	}
+
+	result, err := Import(store, entries, false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if err := UpdateSummaryFlags(store, result, false); err != nil {
+		t.Fatalf("UpdateSummaryFlags failed: %v", err)
+	}
+	if summarized(day) {
+		t.Error("Importing into a summarized day should reset the summary flag")
+	}
+
+	// 第二次导入全部重复，仍然标记输入涉及的日期
+	again, err := Import(store, entries, false)
+	if err != nil {
+		t.Fatalf("Re-import failed: %v", err)
+	}
+	if len(again.Dates) != 0 || len(again.InputDates) != 2 {
+		t.Fatalf("Unexpected re-import dates: new=%d input=%d", len(again.Dates), len(again.InputDates))
+	}
+	if err := UpdateSummaryFlags(store, again, true); err != nil {
+		t.Fatalf("UpdateSummaryFlags failed: %v", err)
+	}
+	if !summarized(day) || !summarized(next) {
+		t.Error("--mark-summarized on re-import should mark all dates in the input")
+	}
+}
+
+// TestExportRoundTrip 测试导出的 JSON 可以原样导入并保留 ID
+func TestExportRoundTrip(t *testing.T) {
+	source := newTestStore(t)
+	day := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: day.Add(11 * time.Hour), Content: "@billing 对账任务排查 #bug"},
+		{Timestamp: day.Add(12 * time.Hour), Content: "需求评审"},
+	} {
+		if err := source.SaveEntry(entry); err != nil {
+			t.Fatalf("Failed to save entry: %v", err)
+		}
+	}
+
+	opts := export.Options{From: day, To: day, DefaultWindow: time.Hour, MaxWindow: 2 * time.Hour}
+	rows, err := export.Collect(source, opts)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := export.Write(&buf, export.FormatJSON, rows, opts); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	result := parse(t, FormatExport, ParserOptions{}, buf.String())
+	target := newTestStore(t)
+	if _, err := Import(target, result.Entries, false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	want, _ := source.GetDailyData(day)
+	got, _ := target.GetDailyData(day)
+	if len(got.Entries) != len(want.Entries) {
+		t.Fatalf("Expected %d entries, got %d", len(want.Entries), len(got.Entries))
+	}
+	for i := range want.Entries {
+		if got.Entries[i].ID != want.Entries[i].ID || got.Entries[i].Content != want.Entries[i].Content ||
+			got.Entries[i].Project != want.Entries[i].Project {
+			t.Errorf("Entry %d mismatch: got %+v, want %+v", i, got.Entries[i], want.Entries[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"humg.top/daily_summary/internal/export"
+	"humg.top/daily_summary/internal/models"
+)
+
+func init() {
+	Register(FormatJSONL, func(opts ParserOptions) Parser {
+		return &JSONLParser{TimeLayout: opts.TimeLayout}
+	})
+	Register(FormatExport, func(opts ParserOptions) Parser {
+		return &ExportParser{}
+	})
+}
+
+// JSONLParser JSON Lines 解析器
+// 每行一个对象：{"timestamp": "2026-01-19 11:00", "content": "...", "project": "...", "tags": ["..."]}
+type JSONLParser struct {
+	TimeLayout string
+}
+
+// jsonlRecord JSON Lines 中的一行
+type jsonlRecord struct {
+	Timestamp string   `json:"timestamp"`
+	Content   string   `json:"content"`
+	Project   string   `json:"project"`
+	Tags      []string `json:"tags"`
+}
+
+// Parse 解析 JSON Lines（空行会被忽略）
+func (p *JSONLParser) Parse(r io.Reader) (*ParseResult, error) {
+	result := &ParseResult{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record jsonlRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+		if strings.TrimSpace(record.Content) == "" {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: errors.New("empty content")})
+			continue
+		}
+
+		timestamp, err := parseTime(record.Timestamp, p.TimeLayout)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		result.Entries = append(result.Entries, models.WorkEntry{
+			Timestamp: timestamp,
+			Content:   withMeta(strings.TrimSpace(record.Content), record.Project, record.Tags),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read jsonl: %w", err)
+	}
+	return result, nil
+}
+
+// ExportParser 解析本工具 export --format json 的输出（保留原记录 ID）
+type ExportParser struct{}
+
+// Parse 解析导出文件
+func (p *ExportParser) Parse(r io.Reader) (*ParseResult, error) {
+	var file export.File
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode export file: %w", err)
+	}
+	if file.Version == 0 || file.Version > export.FileVersion {
+		return nil, fmt.Errorf("unsupported export file version: %d", file.Version)
+	}
+
+	result := &ParseResult{}
+	for i, item := range file.Entries {
+		if item.Timestamp.IsZero() || strings.TrimSpace(item.Content) == "" {
+			result.Errors = append(result.Errors, RowError{Line: i + 1, Err: errors.New("missing timestamp or content")})
+			continue
+		}
+		result.Entries = append(result.Entries, models.WorkEntry{
+			ID:        item.ID,
+			Timestamp: item.Timestamp,
+			Content:   item.Content,
+		})
+	}
+	return result, nil
+}
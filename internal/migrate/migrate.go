@@ -0,0 +1,314 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/fileutil"
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// versionFile 数据目录下记录数据格式版本的文件
+const versionFile = ".schema_version"
+
+// pendingBackupFile 数据目录下记录正在执行的迁移步骤备份的文件，该步成功后删除
+// 该步失败后重试（如 serve 被 launchd 反复重启）时复用这份备份，不再每次复制全部数据
+const pendingBackupFile = ".migration_backup"
+
+// ErrNewerSchema 数据格式版本高于当前程序支持的版本（数据由更新版本的程序写入）
+var ErrNewerSchema = errors.New("data schema is newer than supported")
+
+// Env 迁移执行环境
+type Env struct {
+	DataDir    string // 每日 JSON 数据目录
+	SummaryDir string // 总结目录
+}
+
+// Migration 单个迁移步骤，将数据从 Version-1 升级到 Version
+// Apply 必须是幂等的：中途失败后重新执行不会破坏已迁移的数据
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(env Env) error
+}
+
+// Step 已执行的迁移步骤
+type Step struct {
+	Migration
+	Backup string // 执行前的备份目录
+}
+
+// Status 数据格式版本状态
+type Status struct {
+	Current int         // 当前数据版本
+	Latest  int         // 程序支持的最新版本
+	Pending []Migration // 待执行的迁移
+}
+
+// Migrator 数据迁移器
+type Migrator struct {
+	env        Env
+	backupDir  string
+	migrations []Migration
+	now        func() time.Time
+}
+
+// New 创建迁移器，备份保存在 backupDir 下
+func New(dataDir, summaryDir, backupDir string) *Migrator {
+	return &Migrator{
+		env:        Env{DataDir: dataDir, SummaryDir: summaryDir},
+		backupDir:  backupDir,
+		migrations: migrations,
+		now:        time.Now,
+	}
+}
+
+// Latest 返回程序支持的最新数据版本
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status 读取当前数据版本并计算待执行的迁移
+func (m *Migrator) Status() (*Status, error) {
+	current, err := m.currentVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Current: current, Latest: m.Latest()}
+	if current > status.Latest {
+		return status, fmt.Errorf("%w: data is v%d, this program supports up to v%d", ErrNewerSchema, current, status.Latest)
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > current {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// Run 依次执行待执行的迁移，每一步执行前备份数据目录和总结目录
+// 迁移期间持有数据目录锁，避免与 add/edit 等命令同时修改数据文件；
+// 某一步失败时数据停留在上一步完成后的版本，可从该步的备份恢复，重试同一步时复用该备份
+func (m *Migrator) Run() ([]Step, error) {
+	if err := os.MkdirAll(m.env.DataDir, 0755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+
+	lock, err := fileutil.Lock(filepath.Join(m.env.DataDir, storage.DataLockFile))
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	// 没有需要迁移的数据（全新安装），直接记录为最新版本
+	if _, err := os.Stat(filepath.Join(m.env.DataDir, versionFile)); os.IsNotExist(err) && len(status.Pending) == 0 {
+		return nil, m.writeVersion(status.Latest)
+	}
+
+	var steps []Step
+	for _, migration := range status.Pending {
+		backup, err := m.backup(migration.Version)
+		if err != nil {
+			return steps, fmt.Errorf("backup before migration v%d: %w", migration.Version, err)
+		}
+
+		if err := migration.Apply(m.env); err != nil {
+			return steps, fmt.Errorf("migration v%d (%s) failed, backup at %s: %w", migration.Version, migration.Description, backup, err)
+		}
+		if err := m.writeVersion(migration.Version); err != nil {
+			return steps, err
+		}
+		if err := os.Remove(filepath.Join(m.env.DataDir, pendingBackupFile)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove %s: %v", pendingBackupFile, err)
+		}
+
+		steps = append(steps, Step{Migration: migration, Backup: backup})
+	}
+
+	return steps, nil
+}
+
+// currentVersion 读取数据目录的版本号
+// 没有版本文件时：存在旧格式数据视为 v0，否则（全新安装或只有新版本写入的数据）视为最新版本
+func (m *Migrator) currentVersion() (int, error) {
+	data, err := os.ReadFile(filepath.Join(m.env.DataDir, versionFile))
+	if err == nil {
+		version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("parse schema version file: %w", err)
+		}
+		return version, nil
+	}
+	if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("read schema version file: %w", err)
+	}
+
+	legacy, err := hasLegacyData(m.env, m.Latest())
+	if err != nil {
+		return 0, err
+	}
+	if legacy {
+		return 0, nil
+	}
+	return m.Latest(), nil
+}
+
+// writeVersion 写入数据目录的版本号
+func (m *Migrator) writeVersion(version int) error {
+	path := filepath.Join(m.env.DataDir, versionFile)
+	if err := fileutil.WriteFileAtomic(path, []byte(strconv.Itoa(version)+"\n"), 0644); err != nil {
+		return fmt.Errorf("write schema version file: %w", err)
+	}
+	return nil
+}
+
+// hasLegacyData 判断是否存在需要迁移的旧格式数据：
+// 总结目录根下的日报文件，或没有记录当前版本号的每日数据文件
+func hasLegacyData(env Env, latest int) (bool, error) {
+	files, err := os.ReadDir(env.SummaryDir)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("read summary directory: %w", err)
+	}
+	for _, file := range files {
+		if _, ok := dateFileName(file.Name()); ok && !file.IsDir() && filepath.Ext(file.Name()) == ".md" {
+			return true, nil
+		}
+	}
+
+	files, err = os.ReadDir(env.DataDir)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("read data directory: %w", err)
+	}
+	for _, file := range files {
+		if _, ok := dateFileName(file.Name()); !ok || file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(env.DataDir, file.Name()))
+		if err != nil {
+			return false, fmt.Errorf("read %s: %w", file.Name(), err)
+		}
+		// 无法解析的文件不视为旧格式数据（迁移时跳过，由 fsck 处理）
+		var dailyData models.DailyData
+		if err := json.Unmarshal(data, &dailyData); err == nil && dailyData.SchemaVersion < latest {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dateFileName 判断文件名是否为 YYYY-MM-DD.json 或 YYYY-MM-DD.md，返回日期部分
+func dateFileName(name string) (string, bool) {
+	ext := filepath.Ext(name)
+	if ext != ".json" && ext != ".md" {
+		return "", false
+	}
+	dateStr := strings.TrimSuffix(name, ext)
+	if _, err := time.Parse("2006-01-02", dateStr); err != nil {
+		return "", false
+	}
+	return dateStr, true
+}
+
+// backup 将数据目录和总结目录复制到 backupDir/migrate-v<版本>-<时间>/
+// 同一步之前失败过时直接返回当时的备份（其中是该步执行前的数据）
+func (m *Migrator) backup(version int) (string, error) {
+	marker := filepath.Join(m.env.DataDir, pendingBackupFile)
+	prefix := fmt.Sprintf("migrate-v%d-", version)
+	if data, err := os.ReadFile(marker); err == nil {
+		dir := strings.TrimSpace(string(data))
+		if strings.HasPrefix(filepath.Base(dir), prefix) {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				log.Printf("Reusing backup %s from previous failed migration v%d", dir, version)
+				return dir, nil
+			}
+		}
+	}
+
+	dir := filepath.Join(m.backupDir, prefix+m.now().Format("20060102-150405"))
+	if err := copyDir(m.env.DataDir, filepath.Join(dir, "data")); err != nil {
+		return "", err
+	}
+	if err := copyDir(m.env.SummaryDir, filepath.Join(dir, "summaries")); err != nil {
+		return "", err
+	}
+	if err := fileutil.WriteFileAtomic(marker, []byte(dir+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", pendingBackupFile, err)
+	}
+	return dir, nil
+}
+
+// copyDir 递归复制目录（跳过锁文件和写入中的临时文件），源目录不存在时不做任何事
+func copyDir(src, dst string) error {
+	files, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read directory %s: %w", src, err)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if name == storage.DataLockFile || name == pendingBackupFile || strings.Contains(name, ".tmp-") {
+			continue
+		}
+		srcPath := filepath.Join(src, name)
+		dstPath := filepath.Join(dst, name)
+		if file.IsDir() {
+			if err := copyDir(srcPath, dstPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(srcPath, dstPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile 复制单个文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// newTestMigrator 创建使用临时目录的迁移器
+func newTestMigrator(t *testing.T) (*Migrator, Env) {
+	tmpDir := t.TempDir()
+	env := Env{
+		DataDir:    filepath.Join(tmpDir, "data"),
+		SummaryDir: filepath.Join(tmpDir, "summaries"),
+	}
+	os.MkdirAll(env.DataDir, 0755)
+	os.MkdirAll(env.SummaryDir, 0755)
+	return New(env.DataDir, env.SummaryDir, filepath.Join(tmpDir, "backups")), env
+}
+
+// TestLatestMatchesModels 测试迁移的最新版本与 models.CurrentSchemaVersion 一致
+func TestLatestMatchesModels(t *testing.T) {
+	m, _ := newTestMigrator(t)
+	if m.Latest() != models.CurrentSchemaVersion {
+		t.Errorf("Latest migration v%d does not match models.CurrentSchemaVersion v%d", m.Latest(), models.CurrentSchemaVersion)
+	}
+}
+
+// TestRunLegacyData 测试将旧格式数据升级到最新版本
+func TestRunLegacyData(t *testing.T) {
+	m, env := newTestMigrator(t)
+
+	legacy := `{
+  "date": "2026-01-19",
+  "entries": [
+    {"timestamp": "2026-01-19T18:00:00+08:00", "content": "@billing 修复并上线 #bug"},
+    {"timestamp": "2026-01-19T11:00:00+08:00", "content": "对账任务排查"}
+  ],
+  "summary_generated": true
+}`
+	os.WriteFile(filepath.Join(env.DataDir, "2026-01-19.json"), []byte(legacy), 0644)
+	os.WriteFile(filepath.Join(env.SummaryDir, "2026-01-19.md"), []byte("# 工作总结"), 0644)
+
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Current != 0 || len(status.Pending) != m.Latest() {
+		t.Fatalf("Expected v0 with %d pending migrations, got v%d with %d", m.Latest(), status.Current, len(status.Pending))
+	}
+
+	steps, err := m.Run()
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(steps) != m.Latest() {
+		t.Fatalf("Expected %d steps, got %d", m.Latest(), len(steps))
+	}
+
+	// 第一步的备份保留迁移前的原始文件
+	backup, err := os.ReadFile(filepath.Join(steps[0].Backup, "data", "2026-01-19.json"))
+	if err != nil || string(backup) != legacy {
+		t.Errorf("Backup does not contain original data: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(steps[0].Backup, "summaries", "2026-01-19.md")); err != nil {
+		t.Errorf("Backup does not contain original summary: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(env.SummaryDir, "daily", "2026-01-19.md")); err != nil {
+		t.Errorf("Summary was not moved to daily/: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(env.DataDir, "2026-01-19.json"))
+	var dailyData models.DailyData
+	if err := json.Unmarshal(data, &dailyData); err != nil {
+		t.Fatalf("Failed to unmarshal migrated data: %v", err)
+	}
+	if dailyData.SchemaVersion != m.Latest() || !dailyData.SummaryGenerated {
+		t.Errorf("Unexpected migrated data: version=%d summary_generated=%v", dailyData.SchemaVersion, dailyData.SummaryGenerated)
+	}
+	first, second := dailyData.Entries[0], dailyData.Entries[1]
+	if first.Content != "对账任务排查" || second.Project != "billing" || !second.HasTag("bug") {
+		t.Errorf("Entries not sorted or meta not parsed: %+v", dailyData.Entries)
+	}
+	if first.ID != models.LegacyEntryID(models.WorkEntry{Timestamp: first.Timestamp, Content: first.Content}) {
+		t.Errorf("Expected legacy ID to be persisted, got %q", first.ID)
+	}
+
+	// 迁移后的 ID 与迁移前存储层读取到的一致
+	store := storage.NewJSONStorage(env.DataDir, env.SummaryDir)
+	if entry, err := store.GetEntry(first.ID); err != nil || entry.Content != first.Content {
+		t.Errorf("GetEntry(%s) after migration failed: %v", first.ID, err)
+	}
+
+	// 再次执行不做任何事
+	steps, err = m.Run()
+	if err != nil || len(steps) != 0 {
+		t.Errorf("Expected no-op on second run, got %d steps, err=%v", len(steps), err)
+	}
+}
+
+// TestRunFreshDataDir 测试全新数据目录直接记录为最新版本，不产生备份
+func TestRunFreshDataDir(t *testing.T) {
+	m, env := newTestMigrator(t)
+
+	steps, err := m.Run()
+	if err != nil || len(steps) != 0 {
+		t.Fatalf("Expected no steps for fresh data dir, got %d, err=%v", len(steps), err)
+	}
+	version, _ := os.ReadFile(filepath.Join(env.DataDir, versionFile))
+	if string(version) != "3\n" {
+		t.Errorf("Expected version file to contain latest version, got %q", version)
+	}
+	if _, err := os.Stat(m.backupDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup for fresh data dir")
+	}
+
+	// 只有新版本写入的数据文件时同样不需要迁移
+	m, env = newTestMigrator(t)
+	store := storage.NewJSONStorage(env.DataDir, env.SummaryDir)
+	store.SaveEntry(models.WorkEntry{Timestamp: time.Now(), Content: "新记录"})
+	if status, err := m.Status(); err != nil || len(status.Pending) != 0 {
+		t.Errorf("Expected no pending migrations for current data, got %+v, err=%v", status, err)
+	}
+}
+
+// TestNewerSchema 测试数据版本高于程序支持的版本时拒绝迁移
+func TestNewerSchema(t *testing.T) {
+	m, env := newTestMigrator(t)
+	os.WriteFile(filepath.Join(env.DataDir, versionFile), []byte("99\n"), 0644)
+
+	if _, err := m.Run(); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("Expected ErrNewerSchema, got %v", err)
+	}
+}
+
+// TestRunFailureKeepsVersion 测试某一步失败时版本停留在上一步
+func TestRunFailureKeepsVersion(t *testing.T) {
+	m, env := newTestMigrator(t)
+	os.WriteFile(filepath.Join(env.DataDir, "2026-01-19.json"), []byte("{}"), 0644)
+	m.now = func() time.Time { return time.Date(2026, 1, 20, 8, 0, 0, 0, time.Local) }
+	m.migrations = []Migration{
+		{Version: 1, Description: "ok", Apply: func(env Env) error { return nil }},
+		{Version: 2, Description: "broken", Apply: func(env Env) error { return errors.New("boom") }},
+	}
+
+	steps, err := m.Run()
+	if err == nil || len(steps) != 1 {
+		t.Fatalf("Expected failure after 1 step, got %d steps, err=%v", len(steps), err)
+	}
+	status, err := m.Status()
+	if err != nil || status.Current != 1 {
+		t.Errorf("Expected version to stay at v1, got %+v, err=%v", status, err)
+	}
+	if _, err := os.Stat(filepath.Join(m.backupDir, "migrate-v2-20260120-080000", "data", "2026-01-19.json")); err != nil {
+		t.Errorf("Expected backup for failed step: %v", err)
+	}
+
+	// 重试同一步时复用之前的备份，不再复制全部数据
+	m.now = func() time.Time { return time.Date(2026, 1, 20, 8, 5, 0, 0, time.Local) }
+	if _, err := m.Run(); err == nil {
+		t.Fatal("Expected retry to fail again")
+	}
+	backups, _ := os.ReadDir(m.backupDir)
+	if len(backups) != 2 {
+		t.Errorf("Expected backups for v1 and v2 only, got %d", len(backups))
+	}
+}
+
+// TestRunSkipsCorruptFiles 测试无法解析的数据文件不阻止迁移，保持原样留给 fsck 处理
+func TestRunSkipsCorruptFiles(t *testing.T) {
+	m, env := newTestMigrator(t)
+	corrupt := `{"date": "2026-01-18", "entries": [`
+	os.WriteFile(filepath.Join(env.DataDir, "2026-01-18.json"), []byte(corrupt), 0644)
+	os.WriteFile(filepath.Join(env.DataDir, "2026-01-19.json"), []byte(`{"date": "2026-01-19", "entries": [{"timestamp": "2026-01-19T11:00:00+08:00", "content": "对账"}]}`), 0644)
+
+	steps, err := m.Run()
+	if err != nil || len(steps) != m.Latest() {
+		t.Fatalf("Expected migration to succeed, got %d steps, err=%v", len(steps), err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(env.DataDir, "2026-01-18.json")); string(data) != corrupt {
+		t.Errorf("Corrupt file should be left untouched, got %q", data)
+	}
+	var dailyData models.DailyData
+	data, _ := os.ReadFile(filepath.Join(env.DataDir, "2026-01-19.json"))
+	if err := json.Unmarshal(data, &dailyData); err != nil || dailyData.SchemaVersion != m.Latest() || dailyData.Entries[0].ID == "" {
+		t.Errorf("Expected valid file to be migrated, got %+v, err=%v", dailyData, err)
+	}
+	if _, err := os.Stat(filepath.Join(env.DataDir, pendingBackupFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed after successful migration", pendingBackupFile)
+	}
+
+	// 只剩无法解析的文件时不视为旧格式数据
+	m, env = newTestMigrator(t)
+	os.WriteFile(filepath.Join(env.DataDir, "2026-01-18.json"), []byte(corrupt), 0644)
+	if status, err := m.Status(); err != nil || len(status.Pending) != 0 {
+		t.Errorf("Expected no pending migrations, got %+v, err=%v", status, err)
+	}
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"humg.top/daily_summary/internal/fileutil"
+	"humg.top/daily_summary/internal/models"
+)
+
+// migrations 所有迁移步骤，按版本号递增排列
+// 新增数据格式变化时在末尾追加一步，并同步更新 models.CurrentSchemaVersion
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "将总结目录根下的日报移动到 daily/ 子目录",
+		Apply:       moveDailySummaries,
+	},
+	{
+		Version:     2,
+		Description: "为没有 ID 的工作记录写入稳定 ID",
+		Apply: func(env Env) error {
+			return rewriteDailyFiles(env.DataDir, 2, func(dailyData *models.DailyData) {
+				for i := range dailyData.Entries {
+					if dailyData.Entries[i].ID == "" {
+						dailyData.Entries[i].ID = models.LegacyEntryID(dailyData.Entries[i])
+					}
+				}
+			})
+		},
+	},
+	{
+		Version:     3,
+		Description: "解析工作记录中的 #标签 和 @项目，并按时间排序",
+		Apply: func(env Env) error {
+			return rewriteDailyFiles(env.DataDir, 3, func(dailyData *models.DailyData) {
+				for i := range dailyData.Entries {
+					if dailyData.Entries[i].Tags == nil && dailyData.Entries[i].Project == "" {
+						dailyData.Entries[i].ParseMeta()
+					}
+				}
+				sort.SliceStable(dailyData.Entries, func(i, j int) bool {
+					return dailyData.Entries[i].Timestamp.Before(dailyData.Entries[j].Timestamp)
+				})
+			})
+		},
+	},
+}
+
+// moveDailySummaries 将 summaryDir/YYYY-MM-DD.md 移动到 summaryDir/daily/（旧版目录结构）
+// daily/ 中已存在同名文件时保留两份，不覆盖
+func moveDailySummaries(env Env) error {
+	files, err := os.ReadDir(env.SummaryDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read summary directory: %w", err)
+	}
+
+	dailyDir := filepath.Join(env.SummaryDir, "daily")
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".md" {
+			continue
+		}
+		if _, ok := dateFileName(file.Name()); !ok {
+			continue
+		}
+
+		if err := os.MkdirAll(dailyDir, 0755); err != nil {
+			return fmt.Errorf("create daily directory: %w", err)
+		}
+		target := filepath.Join(dailyDir, file.Name())
+		if _, err := os.Stat(target); err == nil {
+			log.Printf("Skip moving summary %s: target already exists", file.Name())
+			continue
+		}
+		if err := os.Rename(filepath.Join(env.SummaryDir, file.Name()), target); err != nil {
+			return fmt.Errorf("move summary %s: %w", file.Name(), err)
+		}
+	}
+	return nil
+}
+
+// rewriteDailyFiles 对数据目录中版本低于 version 的每日数据文件执行 update，并写回为 version 版本
+// 无法解析的文件（如写入中断）记录警告后跳过，保持原样，由 fsck 检查和隔离
+func rewriteDailyFiles(dataDir string, version int, update func(dailyData *models.DailyData)) error {
+	files, err := os.ReadDir(dataDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read data directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		if _, ok := dateFileName(file.Name()); !ok {
+			continue
+		}
+
+		path := filepath.Join(dataDir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file.Name(), err)
+		}
+
+		var dailyData models.DailyData
+		if err := json.Unmarshal(data, &dailyData); err != nil {
+			log.Printf("Warning: skip corrupt data file %s during migration v%d: %v (run fsck to repair)", file.Name(), version, err)
+			continue
+		}
+		if dailyData.SchemaVersion >= version {
+			continue
+		}
+
+		update(&dailyData)
+		dailyData.SchemaVersion = version
+
+		data, err = json.MarshalIndent(dailyData, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", file.Name(), err)
+		}
+		if err := fileutil.WriteFileAtomic(path, data, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", file.Name(), err)
+		}
+	}
+	return nil
+}
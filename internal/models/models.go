@@ -1,25 +1,80 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
 
 // WorkEntry 表示单次工作记录
 type WorkEntry struct {
-	Timestamp time.Time `json:"timestamp"` // 记录时间
-	Content   string    `json:"content"`   // 工作内容
+	ID        string    `json:"id,omitempty"`      // 记录 ID（格式：YYYYMMDD-xxxxxx，创建后不再改变）
+	Timestamp time.Time `json:"timestamp"`         // 记录时间
+	Content   string    `json:"content"`           // 工作内容
+	Tags      []string  `json:"tags,omitempty"`    // 从内容中解析出的 #标签（小写）
+	Project   string    `json:"project,omitempty"` // 从内容中解析出的 @项目（小写）
 }
 
+// NewEntryID 为新记录生成 ID：日期前缀 + 6 位随机十六进制
+// 日期前缀使存储层可以直接定位记录所在的日期
+func NewEntryID(timestamp time.Time) string {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		// 随机数不可用时退化为基于纳秒时间戳的 ID
+		return LegacyEntryID(WorkEntry{Timestamp: timestamp, Content: fmt.Sprint(time.Now().UnixNano())})
+	}
+	return timestamp.Format("20060102") + "-" + hex.EncodeToString(buf)
+}
+
+// LegacyEntryID 为没有 ID 的历史记录生成确定性 ID
+// 基于时间戳和内容计算，未写回文件前多次读取得到的 ID 保持一致
+func LegacyEntryID(entry WorkEntry) string {
+	sum := sha1.Sum([]byte(entry.Timestamp.Format(time.RFC3339Nano) + "\x00" + entry.Content))
+	return entry.Timestamp.Format("20060102") + "-" + hex.EncodeToString(sum[:3])
+}
+
+// EntryIDDate 从记录 ID 中解析记录所在的日期
+func EntryIDDate(id string) (time.Time, error) {
+	if len(id) != 15 || id[8] != '-' {
+		return time.Time{}, fmt.Errorf("invalid entry id: %s", id)
+	}
+	date, err := time.Parse("20060102", id[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid entry id: %s", id)
+	}
+	return date, nil
+}
+
+// CurrentSchemaVersion 当前数据格式版本
+// 数据目录和每个 DailyData 文件都会记录版本号，旧版本数据由 internal/migrate 升级
+const CurrentSchemaVersion = 3
+
 // DailyData 表示一天的所有工作记录
 type DailyData struct {
-	Date             string      `json:"date"`               // 格式: YYYY-MM-DD
-	Entries          []WorkEntry `json:"entries"`            // 工作记录列表
-	SummaryGenerated bool        `json:"summary_generated"`  // 是否已生成总结
+	SchemaVersion    int         `json:"schema_version,omitempty"` // 数据格式版本（旧文件没有该字段，视为 0）
+	Date             string      `json:"date"`                     // 格式: YYYY-MM-DD
+	Entries          []WorkEntry `json:"entries"`                  // 工作记录列表
+	SummaryGenerated bool        `json:"summary_generated"`        // 是否已生成总结
 }
 
 // SummaryMetadata 总结的元数据
 type SummaryMetadata struct {
-	GeneratedAt time.Time `json:"generated_at"` // 生成时间
-	Date        string    `json:"date"`         // 总结对应的日期
-	EntryCount  int       `json:"entry_count"`  // 记录条数
+	GeneratedAt time.Time `json:"generated_at"`       // 生成时间
+	Date        string    `json:"date"`               // 总结对应的日期
+	EntryCount  int       `json:"entry_count"`        // 记录条数
+	Provider    string    `json:"provider,omitempty"` // 生成总结的 AI 提供商
+}
+
+// CommandProviderConfig 通用命令行 AI 提供商配置，无需修改代码即可接入任意本地模型 CLI
+type CommandProviderConfig struct {
+	Command     string            `yaml:"command" json:"command"`           // 可执行文件
+	Args        []string          `yaml:"args" json:"args"`                 // 参数模板，支持 {{prompt}}（提示词）和 {{prompt_file}}（提示词临时文件路径）
+	Stdin       bool              `yaml:"stdin" json:"stdin"`               // 是否通过标准输入传递提示词
+	WorkDir     string            `yaml:"work_dir" json:"work_dir"`         // 执行目录（相对路径基于 work_dir，默认 work_dir）
+	Env         map[string]string `yaml:"env" json:"env"`                   // 额外的环境变量（值中的 $VAR 会被展开）
+	OutputRegex string            `yaml:"output_regex" json:"output_regex"` // 从标准输出提取总结的正则（有捕获组时取第一个捕获组），为空时使用全部输出
 }
 
 // Config 应用配置
@@ -30,12 +85,60 @@ type Config struct {
 	HourlyInterval int    `yaml:"hourly_interval" json:"hourly_interval"`   // 小时间隔（默认1）
 	MinuteInterval int    `yaml:"minute_interval" json:"minute_interval"`   // 分钟间隔（如果设置则优先使用）
 	SummaryTime    string `yaml:"summary_time" json:"summary_time"`         // 生成总结的时间（默认"00:00"）
+
+	// 存储配置
+	StorageBackend string `yaml:"storage_backend" json:"storage_backend"` // 存储后端："json" 或 "sqlite"（默认 json）
+	SQLitePath     string `yaml:"sqlite_path" json:"sqlite_path"`         // SQLite 数据库文件路径（默认 run/daily_summary.db）
 	
 	// AI 总结生成配置
-	AIProvider     string `yaml:"ai_provider" json:"ai_provider"`           // AI 提供商："codex"、"claude" 或 "coco"（默认 codex）
+	AIProvider     string `yaml:"ai_provider" json:"ai_provider"`           // AI 提供商："codex"、"claude"、"coco" 或 "openai"（默认 codex）
 	CodexPath      string `yaml:"codex_path" json:"codex_path"`             // Codex CLI 路径
 	ClaudeCodePath string `yaml:"claude_code_path" json:"claude_code_path"` // Claude Code CLI 路径
 	CocoPath       string `yaml:"coco_path" json:"coco_path"`               // Coco CLI 路径
+
+	// CLI 提供商的提示词传递方式：arg（命令行参数）、stdin（标准输入）或 file（临时文件）
+	// 默认均为 stdin，如 {coco: file}
+	PromptModes map[string]string `yaml:"prompt_modes" json:"prompt_modes"`
+
+	// 通过配置定义的命令行 AI 提供商（名称 -> 配置），名称可用于 ai_provider / ai_providers
+	CommandProviders map[string]CommandProviderConfig `yaml:"command_providers" json:"command_providers"`
+
+	// AI 调用超时（秒），超时后终止 CLI 进程组或取消 HTTP 请求；0 表示不限制
+	AITimeout  int            `yaml:"ai_timeout" json:"ai_timeout"`   // 默认超时（默认 600）
+	AITimeouts map[string]int `yaml:"ai_timeouts" json:"ai_timeouts"` // 按提供商覆盖默认超时，如 {codex: 900, openai: 120}
+
+	// 多提供商回退：按顺序尝试，限流等临时性失败先按退避重试，仍失败则切换到下一个
+	AIProviders    []string `yaml:"ai_providers" json:"ai_providers"`         // 提供商列表（设置后优先于 ai_provider），如 [codex, openai]
+	AIRetries      int      `yaml:"ai_retries" json:"ai_retries"`             // 每个提供商临时性失败的重试次数（默认 2）
+	AIRetryBackoff int      `yaml:"ai_retry_backoff" json:"ai_retry_backoff"` // 首次重试等待秒数，之后每次翻倍（默认 30）
+
+	// 所有 AI 提供商都失败时按规则离线生成日报（作为提供商链的最后一个，周报/月报不受影响），默认开启
+	OfflineFallback bool `yaml:"offline_fallback" json:"offline_fallback"`
+
+	// AI 输出校验：保存前去掉代码块标记和开场白，检查必需章节（周报还检查 HTML 是否完整）
+	SummarySections []string `yaml:"summary_sections" json:"summary_sections"`   // 每日总结必需的章节标题（不设置时使用默认章节，[] 表示不检查）
+	WeeklySections  []string `yaml:"weekly_sections" json:"weekly_sections"`     // 周报必需的章节标题（同上）
+	MonthlySections []string `yaml:"monthly_sections" json:"monthly_sections"`   // 月报必需的章节标题（同上）
+	AIOutputRetries int      `yaml:"ai_output_retries" json:"ai_output_retries"` // 校验未通过时附上问题重新生成的次数（默认 1，0 表示直接失败）
+
+	// 提示词模板：默认使用编译进程序的内置模板，不依赖运行目录
+	TemplateDir     string `yaml:"template_dir" json:"template_dir"`         // 自定义模板目录，其中与内置模板同名的文件（summary_prompt.md 等）优先使用
+	DailyTemplate   string `yaml:"daily_template" json:"daily_template"`     // 日报模板路径（优先于 template_dir）
+	WeeklyTemplate  string `yaml:"weekly_template" json:"weekly_template"`   // 周报模板路径（同上）
+	MonthlyTemplate string `yaml:"monthly_template" json:"monthly_template"` // 月报模板路径（同上）
+
+	// OpenAI 兼容接口配置（ai_provider: openai）
+	OpenAIBaseURL     string   `yaml:"openai_base_url" json:"openai_base_url"`       // 接口地址（默认 https://api.openai.com/v1）
+	OpenAIModel       string   `yaml:"openai_model" json:"openai_model"`             // 模型名称（必填）
+	OpenAIAPIKeyEnv   string   `yaml:"openai_api_key_env" json:"openai_api_key_env"` // 存放 API Key 的环境变量名（默认 OPENAI_API_KEY）
+	OpenAITemperature *float64 `yaml:"openai_temperature" json:"openai_temperature"` // 采样温度（不设置时使用服务端默认值）
+	OpenAIMaxTokens   int      `yaml:"openai_max_tokens" json:"openai_max_tokens"`   // 最大生成 token 数（0 表示使用服务端默认值）
+
+	// Ollama 本地模型配置（ai_provider: ollama），总结在本机生成，工作记录不会离开本机
+	OllamaHost        string   `yaml:"ollama_host" json:"ollama_host"`               // 服务地址（默认 OLLAMA_HOST 环境变量或 http://localhost:11434）
+	OllamaModel       string   `yaml:"ollama_model" json:"ollama_model"`             // 模型名称（必填，如 qwen2.5:14b）
+	OllamaTemperature *float64 `yaml:"ollama_temperature" json:"ollama_temperature"` // 采样温度（不设置时使用模型默认值）
+	OllamaNumCtx      int      `yaml:"ollama_num_ctx" json:"ollama_num_ctx"`         // 上下文长度（0 表示使用模型默认值）
 	
 	DialogTimeout        int    `yaml:"dialog_timeout" json:"dialog_timeout"`                           // 对话框超时（秒）
 	EnableLogging        bool   `yaml:"enable_logging" json:"enable_logging"`                           // 是否启用日志
@@ -46,4 +149,23 @@ type Config struct {
 	EnableWeeklySummary  bool   `yaml:"enable_weekly_summary" json:"enable_weekly_summary"`             // 是否启用周度总结（默认 false）
 	WeeklySummaryTime    string `yaml:"weekly_summary_time" json:"weekly_summary_time"`                 // 周度总结时间，格式 "HH:MM"（默认 "09:00"）
 	WeeklySummaryDay     int    `yaml:"weekly_summary_day" json:"weekly_summary_day"`                   // 周度总结星期几，1=周一...7=周日（默认 1）
+
+	// 月度总结配置（基于上个月的日报和周报生成）
+	EnableMonthlySummary bool   `yaml:"enable_monthly_summary" json:"enable_monthly_summary"` // 是否启用月度总结（默认 false）
+	MonthlySummaryTime   string `yaml:"monthly_summary_time" json:"monthly_summary_time"`     // 月度总结时间，格式 "HH:MM"（默认 "10:00"）
+	MonthlySummaryDay    int    `yaml:"monthly_summary_day" json:"monthly_summary_day"`       // 每月几号生成上月总结，1-28（默认 1）
+
+	// 自定义报告配置（report 命令）
+	ReportChunkChars int `yaml:"report_chunk_chars" json:"report_chunk_chars"` // 内容超过该字符数时分段预先总结再汇总（默认 40000，0 表示不分段）
+
+	// 工时统计：按相邻记录的时间计算每条记录的耗时（日报末尾的工时表、提示词中的耗时、export 导出）
+	WorkBreaks     []string `yaml:"work_breaks" json:"work_breaks"`           // 不计入耗时的休息时段，如 ["12:00-14:00"]（不设置时为 12:00-14:00、18:00-19:30，[] 表示不扣除）
+	SupplementTags []string `yaml:"supplement_tags" json:"supplement_tags"`   // 补充记录的标签，这类记录不计时（不设置时为 补充、supplement、遗漏、missing、回顾、review）
+	MaxEntryWindow int      `yaml:"max_entry_window" json:"max_entry_window"` // 单条记录耗时上限（分钟，默认为提醒间隔的 2 倍）
+
+	// 备份配置
+	EnableBackup    bool   `yaml:"enable_backup" json:"enable_backup"`       // 是否启用每日自动备份（默认 false）
+	BackupTime      string `yaml:"backup_time" json:"backup_time"`           // 自动备份时间，格式 "HH:MM"（默认 "03:00"）
+	BackupDir       string `yaml:"backup_dir" json:"backup_dir"`             // 备份目录（默认 run/backups）
+	BackupRetention int    `yaml:"backup_retention" json:"backup_retention"` // 保留的备份个数（默认 7，0 表示不清理）
 }
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 分组方式
+const (
+	GroupByProject = "project" // 按 @项目 分组
+	GroupByTag     = "tag"     // 按 #标签 分组
+)
+
+// entryTokenPattern 匹配记录中的 #标签 和 @项目
+// 标记必须位于行首或空白/标点之后，避免把邮箱地址、URL 片段识别为项目
+var entryTokenPattern = regexp.MustCompile(`(?:^|[\s(（，,。；;：:、])([#@])([\p{L}\p{N}_\-/]+)`)
+
+// ParseEntryMeta 从记录内容中解析 #标签 和 @项目
+// 标签和项目统一转为小写；纯数字的 #123 视为 issue 编号而不是标签；出现多个 @项目 时取第一个
+func ParseEntryMeta(content string) (tags []string, project string) {
+	seen := make(map[string]bool)
+	for _, match := range entryTokenPattern.FindAllStringSubmatch(content, -1) {
+		name := strings.ToLower(strings.Trim(match[2], "-/"))
+		if name == "" {
+			continue
+		}
+
+		switch match[1] {
+		case "#":
+			if isDigits(name) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			tags = append(tags, name)
+		case "@":
+			if project == "" {
+				project = name
+			}
+		}
+	}
+	return tags, project
+}
+
+// ParseMeta 根据记录内容重新计算 Tags 和 Project 字段
+func (e *WorkEntry) ParseMeta() {
+	e.Tags, e.Project = ParseEntryMeta(e.Content)
+}
+
+// HasTag 判断记录是否带有指定标签（忽略大小写和前导 #）
+func (e WorkEntry) HasTag(tag string) bool {
+	tag = NormalizeTag(tag)
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTag 规范化用户输入的标签（去掉前导 # 并转为小写）
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// NormalizeProject 规范化用户输入的项目名（去掉前导 @ 并转为小写）
+func NormalizeProject(project string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(project), "@"))
+}
+
+// EntryFilter 工作记录过滤条件
+type EntryFilter struct {
+	Tags    []string // 必须同时带有的标签
+	Project string   // 所属项目
+}
+
+// IsEmpty 判断是否没有任何过滤条件
+func (f EntryFilter) IsEmpty() bool {
+	return len(f.Tags) == 0 && f.Project == ""
+}
+
+// Match 判断记录是否满足过滤条件
+func (f EntryFilter) Match(entry WorkEntry) bool {
+	if f.Project != "" && entry.Project != NormalizeProject(f.Project) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !entry.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply 返回满足过滤条件的记录
+func (f EntryFilter) Apply(entries []WorkEntry) []WorkEntry {
+	if f.IsEmpty() {
+		return entries
+	}
+	result := make([]WorkEntry, 0, len(entries))
+	for _, entry := range entries {
+		if f.Match(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// String 返回过滤条件的可读描述（如 "@billing #oncall"）
+func (f EntryFilter) String() string {
+	var parts []string
+	if f.Project != "" {
+		parts = append(parts, "@"+NormalizeProject(f.Project))
+	}
+	for _, tag := range f.Tags {
+		parts = append(parts, "#"+NormalizeTag(tag))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseEntryFilter 解析过滤表达式（如 "@billing #oncall"）
+// 以空白或逗号分隔，#xxx 为标签（需同时满足），@xxx 为项目（最多一个）
+func ParseEntryFilter(expr string) (EntryFilter, error) {
+	var filter EntryFilter
+	fields := strings.FieldsFunc(expr, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '，'
+	})
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "#") && len(field) > 1:
+			filter.Tags = append(filter.Tags, NormalizeTag(field))
+		case strings.HasPrefix(field, "@") && len(field) > 1:
+			if filter.Project != "" && filter.Project != NormalizeProject(field) {
+				return EntryFilter{}, fmt.Errorf("filter %q has more than one project", expr)
+			}
+			filter.Project = NormalizeProject(field)
+		default:
+			return EntryFilter{}, fmt.Errorf("invalid filter term %q (expected #tag or @project)", field)
+		}
+	}
+	return filter, nil
+}
+
+// EntryGroup 按标签或项目分组后的记录
+type EntryGroup struct {
+	Name    string      // 标签或项目名，未标注时为空
+	Entries []WorkEntry // 组内记录（保持原有顺序）
+}
+
+// GroupEntries 按项目或标签对记录分组
+// 按标签分组时，带多个标签的记录会出现在多个分组中；未标注的记录归入名称为空的分组，排在最后
+func GroupEntries(entries []WorkEntry, by string) []EntryGroup {
+	index := make(map[string]int)
+	var groups []EntryGroup
+
+	add := func(name string, entry WorkEntry) {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, EntryGroup{Name: name})
+		}
+		groups[i].Entries = append(groups[i].Entries, entry)
+	}
+
+	for _, entry := range entries {
+		if by == GroupByTag {
+			if len(entry.Tags) == 0 {
+				add("", entry)
+			}
+			for _, tag := range entry.Tags {
+				add(tag, entry)
+			}
+		} else {
+			add(entry.Project, entry)
+		}
+	}
+
+	// 按首次出现的顺序排列，未标注分组放到最后
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name != "" && groups[j].Name == ""
+	})
+	return groups
+}
+
+// isDigits 判断字符串是否全部由数字组成
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
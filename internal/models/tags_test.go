@@ -0,0 +1,77 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseEntryMeta 测试从记录内容中解析标签和项目
+func TestParseEntryMeta(t *testing.T) {
+	tests := []struct {
+		content     string
+		wantTags    []string
+		wantProject string
+	}{
+		{"@Billing 修复对账任务超时 #Bug #oncall", []string{"bug", "oncall"}, "billing"},
+		{"#补充 上午参加需求评审", []string{"补充"}, ""},
+		{"修复 #123，回复 foo@example.com 的邮件", nil, ""},
+		{"联调（@payment-service）#bug #BUG", []string{"bug"}, "payment-service"},
+		{"@a 和 @b 两个项目", nil, "a"},
+	}
+
+	for _, tt := range tests {
+		tags, project := ParseEntryMeta(tt.content)
+		if !reflect.DeepEqual(tags, tt.wantTags) || project != tt.wantProject {
+			t.Errorf("ParseEntryMeta(%q) = %v, %q; want %v, %q", tt.content, tags, project, tt.wantTags, tt.wantProject)
+		}
+	}
+}
+
+// TestEntryFilterAndGroup 测试按标签/项目过滤与分组
+func TestEntryFilterAndGroup(t *testing.T) {
+	var entries []WorkEntry
+	for _, content := range []string{"@billing 对账 #bug", "@search 索引优化", "周会", "@billing 上线 #release #bug"} {
+		entry := WorkEntry{Content: content}
+		entry.ParseMeta()
+		entries = append(entries, entry)
+	}
+
+	filter := EntryFilter{Tags: []string{"#BUG"}, Project: "@Billing"}
+	if got := filter.Apply(entries); len(got) != 2 {
+		t.Errorf("Expected 2 entries matching %s, got %d", filter, len(got))
+	}
+
+	groups := GroupEntries(entries, GroupByProject)
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"billing", "search", ""}) {
+		t.Errorf("Unexpected project groups: %v", names)
+	}
+
+	tagGroups := GroupEntries(entries, GroupByTag)
+	if len(tagGroups) != 3 || tagGroups[0].Name != "bug" || len(tagGroups[0].Entries) != 2 || tagGroups[2].Name != "" {
+		t.Errorf("Unexpected tag groups: %+v", tagGroups)
+	}
+}
+
+// TestParseEntryFilter 测试解析过滤表达式
+func TestParseEntryFilter(t *testing.T) {
+	filter, err := ParseEntryFilter("@Billing #oncall,#Bug")
+	if err != nil {
+		t.Fatalf("ParseEntryFilter failed: %v", err)
+	}
+	if filter.Project != "billing" || !reflect.DeepEqual(filter.Tags, []string{"oncall", "bug"}) {
+		t.Errorf("Unexpected filter: %+v", filter)
+	}
+
+	if filter, err := ParseEntryFilter(""); err != nil || !filter.IsEmpty() {
+		t.Errorf("Expected empty filter, got %+v, %v", filter, err)
+	}
+	for _, expr := range []string{"billing", "@billing @search", "#"} {
+		if _, err := ParseEntryFilter(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
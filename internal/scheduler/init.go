@@ -15,6 +15,11 @@ func (s *Scheduler) InitializeTasksFromConfig(
 	enableWeeklySummary bool,
 	weeklySummaryTime string,
 	weeklySummaryDay int,
+	enableMonthlySummary bool,
+	monthlySummaryTime string,
+	monthlySummaryDay int,
+	enableBackup bool,
+	backupTime string,
 ) error {
 	// 每次启动时都根据配置重新初始化任务，确保配置与代码保持一致
 	log.Println("Initializing tasks from config...")
@@ -106,6 +111,50 @@ func (s *Scheduler) InitializeTasksFromConfig(
 			nextWeeklySummaryTime.Format("2006-01-02 15:04:05"))
 	}
 
+	// 创建月度总结任务配置（如果启用）
+	if enableMonthlySummary {
+		nextMonthlySummaryTime := CalculateNextMonthlySummaryTime(now, monthlySummaryDay, monthlySummaryTime)
+
+		monthlySummaryTask := &TaskConfig{
+			ID:      "monthly-summary",
+			Name:    "月度总结生成",
+			Type:    TaskTypeDaily,
+			Enabled: true,
+			Time:    monthlySummaryTime,
+			NextRun: nextMonthlySummaryTime,
+			Data: map[string]interface{}{
+				"day": monthlySummaryDay,
+			},
+		}
+
+		if err := s.upsertTask(monthlySummaryTask); err != nil {
+			return err
+		}
+		log.Printf("Initialized task: %s (day: %d, time: %s, next run: %s)",
+			monthlySummaryTask.Name, monthlySummaryDay, monthlySummaryTime,
+			nextMonthlySummaryTime.Format("2006-01-02 15:04:05"))
+	}
+
+	// 创建自动备份任务配置（如果启用）
+	if enableBackup {
+		nextBackupTime := CalculateNextSummaryTime(now, backupTime)
+
+		backupTask := &TaskConfig{
+			ID:      "backup",
+			Name:    "数据自动备份",
+			Type:    TaskTypeDaily,
+			Enabled: true,
+			Time:    backupTime,
+			NextRun: nextBackupTime,
+		}
+
+		if err := s.upsertTask(backupTask); err != nil {
+			return err
+		}
+		log.Printf("Initialized task: %s (time: %s, next run: %s)",
+			backupTask.Name, backupTime, nextBackupTime.Format("2006-01-02 15:04:05"))
+	}
+
 	// 所有任务已通过 upsertTask 自动保存到文件
 	log.Println("Tasks initialized and saved to registry")
 	return nil
@@ -203,3 +252,32 @@ func calculateNextWeeklySummaryTime(from time.Time, weekday int, summaryTime str
 
 	return targetTime
 }
+
+// MonthlySummaryTime 返回指定月份的月度总结时间（day 限制在 1-28，保证每个月都存在）
+func MonthlySummaryTime(year int, month time.Month, day int, summaryTime string, loc *time.Location) time.Time {
+	var hour, minute int
+	if _, err := fmt.Sscanf(summaryTime, "%d:%d", &hour, &minute); err != nil {
+		hour, minute = 10, 0 // 默认 10:00
+	}
+
+	if day < 1 {
+		day = 1
+	} else if day > 28 {
+		day = 28
+	}
+
+	return time.Date(year, month, day, hour, minute, 0, 0, loc)
+}
+
+// CalculateNextMonthlySummaryTime 计算下次月度总结时间（导出函数，供 tasks 包调用）
+func CalculateNextMonthlySummaryTime(from time.Time, day int, summaryTime string) time.Time {
+	targetTime := MonthlySummaryTime(from.Year(), from.Month(), day, summaryTime, from.Location())
+
+	// 本月的总结时间已过，跳到下个月
+	if !targetTime.After(from) {
+		next := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location())
+		targetTime = MonthlySummaryTime(next.Year(), next.Month(), day, summaryTime, from.Location())
+	}
+
+	return targetTime
+}
@@ -6,9 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+
+	"humg.top/daily_summary/internal/fileutil"
 )
 
 // Registry 任务注册表管理器（基于文件的数据存储，确保数据一致性）
+// mu 只能保护同一进程内的并发访问；serve 守护进程与 add 等命令会同时修改 tasks.json，
+// 因此所有"读取-修改-写回"操作还需持有 tasks.json.lock 文件锁
 type Registry struct {
 	filePath string     // JSON 文件路径
 	mu       sync.Mutex // 文件操作互斥锁
@@ -60,14 +64,23 @@ func (r *Registry) save(registry *TaskRegistry) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// 写入文件
-	if err := os.WriteFile(r.filePath, data, 0644); err != nil {
+	// 原子写入文件（临时文件 + rename），避免崩溃时留下被截断的 tasks.json
+	if err := fileutil.WriteFileAtomic(r.filePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write tasks file: %w", err)
 	}
 
 	return nil
 }
 
+// lockFile 获取 tasks.json 的跨进程文件锁（调用者需持有 mu）
+func (r *Registry) lockFile() (*fileutil.FileLock, error) {
+	lock, err := fileutil.Lock(r.filePath + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock tasks file: %w", err)
+	}
+	return lock, nil
+}
+
 // Load 从文件加载任务配置（公开方法，用于初始化验证）
 func (r *Registry) Load() error {
 	r.mu.Lock()
@@ -119,6 +132,12 @@ func (r *Registry) PatchTask(id string, updateFunc func(task *TaskConfig)) error
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	lock, err := r.lockFile()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// 加载现有数据
 	registry, err := r.load()
 	if err != nil {
@@ -149,6 +168,12 @@ func (r *Registry) AddTask(config *TaskConfig) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	lock, err := r.lockFile()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// 加载现有数据
 	registry, err := r.load()
 	if err != nil {
@@ -174,6 +199,12 @@ func (r *Registry) RemoveTask(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	lock, err := r.lockFile()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// 加载现有数据
 	registry, err := r.load()
 	if err != nil {
@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -11,14 +13,17 @@ import (
 
 // Scheduler 通用调度器（基于短周期检查）
 type Scheduler struct {
-	registry      *Registry       // 任务注册表
-	tasks         map[string]Task // 任务实例映射
-	runningTasks  map[string]bool // 正在执行的任务标记
-	runningMu     sync.Mutex      // 保护 runningTasks 的互斥锁
-	checkLogger   *log.Logger     // 调度检查专用日志记录器
-	stopCh        chan struct{}   // 停止信号
-	checkInterval time.Duration   // 检查间隔
-	runDir        string          // 运行目录
+	registry      *Registry          // 任务注册表
+	tasks         map[string]Task    // 任务实例映射
+	runningTasks  map[string]bool    // 正在执行的任务标记
+	runningMu     sync.Mutex         // 保护 runningTasks 的互斥锁
+	checkLogger   *log.Logger        // 调度检查专用日志记录器
+	stopCh        chan struct{}      // 停止信号
+	loopDone      chan struct{}      // 调度循环退出信号
+	ctx           context.Context    // 传递给任务的上下文，Stop 时取消
+	cancel        context.CancelFunc // 取消 ctx
+	checkInterval time.Duration      // 检查间隔
+	runDir        string             // 运行目录
 }
 
 // NewScheduler 创建调度器
@@ -53,12 +58,17 @@ func NewScheduler(runDir string, maxLogSizeMB int) *Scheduler {
 		checkLogger = log.Default()
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Scheduler{
 		registry:      NewRegistry(runDir),
 		tasks:         make(map[string]Task),
 		runningTasks:  make(map[string]bool),
 		checkLogger:   checkLogger,
 		stopCh:        make(chan struct{}),
+		loopDone:      make(chan struct{}),
+		ctx:           ctx,
+		cancel:        cancel,
 		checkInterval: 1 * time.Minute, // 固定 1 分钟检查间隔
 		runDir:        runDir,
 	}
@@ -103,19 +113,23 @@ func (s *Scheduler) Start() error {
 	// 启动调度循环
 	go s.runScheduler()
 
-	// 等待停止信号
+	// 等待停止信号，并等待正在执行的任务响应取消后退出
 	<-s.stopCh
+	<-s.loopDone
 	log.Println("Scheduler stopped")
 	return nil
 }
 
-// Stop 停止调度器
+// Stop 停止调度器：取消正在执行的任务（如终止 AI 子进程）并通知调度循环退出
 func (s *Scheduler) Stop() {
+	s.cancel()
 	close(s.stopCh)
 }
 
 // runScheduler 调度循环
 func (s *Scheduler) runScheduler() {
+	defer close(s.loopDone)
+
 	ticker := time.NewTicker(s.checkInterval)
 	defer ticker.Stop()
 
@@ -140,6 +154,11 @@ func (s *Scheduler) checkAndRunTasks() {
 	s.checkLogger.Printf("[CHECK] Starting task check at %s", now.Format("2006-01-02 15:04:05"))
 
 	for _, config := range configs {
+		// 调度器已停止，不再启动新任务
+		if s.ctx.Err() != nil {
+			return
+		}
+
 		// 记录每个任务的检查状态
 		if !config.Enabled {
 			s.checkLogger.Printf("[SKIP] Task %s (%s): disabled", config.ID, config.Name)
@@ -197,13 +216,20 @@ func (s *Scheduler) checkAndRunTasks() {
 		// 执行任务
 		s.checkLogger.Printf("[EXECUTE] Task %s (%s): starting execution", config.ID, config.Name)
 		log.Printf("Executing task: %s (%s)", task.ID(), task.Name())
-		err := task.Execute()
+		err := task.Execute(s.ctx)
 
 		// 清除执行状态标记
 		s.runningMu.Lock()
 		delete(s.runningTasks, config.ID)
 		s.runningMu.Unlock()
 
+		// 因调度器停止而中断的任务不记录为失败，下次启动时重新执行
+		if err != nil && errors.Is(err, context.Canceled) && s.ctx.Err() != nil {
+			s.checkLogger.Printf("[EXECUTE] Task %s (%s): canceled by shutdown", config.ID, config.Name)
+			log.Printf("Task %s canceled by shutdown", config.ID)
+			return
+		}
+
 		if err != nil {
 			s.checkLogger.Printf("[EXECUTE] Task %s (%s): execution failed - %v", config.ID, config.Name, err)
 		} else {
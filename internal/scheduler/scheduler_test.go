@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -248,6 +249,32 @@ func TestCalculateNextSummaryTime(t *testing.T) {
 	}
 }
 
+// TestCalculateNextMonthlySummaryTime 测试计算下次月度总结时间
+func TestCalculateNextMonthlySummaryTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		day      int
+		expected time.Time
+	}{
+		{"before this month", time.Date(2026, 9, 1, 9, 0, 0, 0, time.Local), 1, time.Date(2026, 9, 1, 10, 0, 0, 0, time.Local)},
+		{"after this month", time.Date(2026, 9, 1, 10, 30, 0, 0, time.Local), 1, time.Date(2026, 10, 1, 10, 0, 0, 0, time.Local)},
+		{"year rollover", time.Date(2026, 12, 20, 0, 0, 0, 0, time.Local), 5, time.Date(2027, 1, 5, 10, 0, 0, 0, time.Local)},
+		{"day clamped to 28", time.Date(2027, 2, 1, 0, 0, 0, 0, time.Local), 31, time.Date(2027, 2, 28, 10, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := CalculateNextMonthlySummaryTime(tt.from, tt.day, "10:00")
+			if !next.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s",
+					tt.expected.Format("2006-01-02 15:04:05"),
+					next.Format("2006-01-02 15:04:05"))
+			}
+		})
+	}
+}
+
 // TestTwoStageScheduling 测试两段式调度判断
 func TestTwoStageScheduling(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -280,6 +307,67 @@ func TestTwoStageScheduling(t *testing.T) {
 	}
 }
 
+// TestStopCancelsRunningTask 测试 Stop 取消正在执行的任务，且被取消的执行不记录为失败
+func TestStopCancelsRunningTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	sched := NewScheduler(tmpDir, 0)
+
+	config := &TaskConfig{
+		ID:      "blocking-task",
+		Name:    "Blocking Task",
+		Type:    TaskTypeInterval,
+		Enabled: true,
+	}
+	if err := sched.registry.AddTask(config); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	task := &mockBlockingTask{started: make(chan struct{})}
+	sched.RegisterTask(task)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sched.checkAndRunTasks()
+	}()
+
+	<-task.started
+	sched.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("checkAndRunTasks did not return after Stop")
+	}
+
+	if task.onExecutedCalled {
+		t.Error("OnExecuted should not be called for a task canceled by shutdown")
+	}
+	if latest := sched.registry.GetTask("blocking-task"); latest == nil || !latest.LastRun.IsZero() || latest.LastError != "" {
+		t.Errorf("Canceled task should not update registry, got %+v", latest)
+	}
+}
+
+// mockBlockingTask 模拟耗时任务，阻塞直到 ctx 被取消
+type mockBlockingTask struct {
+	started          chan struct{}
+	onExecutedCalled bool
+}
+
+func (m *mockBlockingTask) ID() string   { return "blocking-task" }
+func (m *mockBlockingTask) Name() string { return "Blocking Task" }
+func (m *mockBlockingTask) ShouldRun(now time.Time, config *TaskConfig) (bool, func(*TaskConfig)) {
+	return true, nil
+}
+func (m *mockBlockingTask) Execute(ctx context.Context) error {
+	close(m.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (m *mockBlockingTask) OnExecuted(now time.Time, config *TaskConfig, err error) {
+	m.onExecutedCalled = true
+}
+
 // mockAlwaysRunTask 模拟任务，ShouldRun 总是返回 true
 type mockAlwaysRunTask struct {
 	executed bool
@@ -288,5 +376,5 @@ type mockAlwaysRunTask struct {
 func (m *mockAlwaysRunTask) ID() string                                                            { return "test-task" }
 func (m *mockAlwaysRunTask) Name() string                                                          { return "Mock Task" }
 func (m *mockAlwaysRunTask) ShouldRun(now time.Time, config *TaskConfig) (bool, func(*TaskConfig)) { return true, nil }
-func (m *mockAlwaysRunTask) Execute() error                                                        { m.executed = true; return nil }
+func (m *mockAlwaysRunTask) Execute(ctx context.Context) error                                     { m.executed = true; return nil }
 func (m *mockAlwaysRunTask) OnExecuted(now time.Time, config *TaskConfig, err error)              {}
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"time"
 )
 
@@ -19,8 +20,8 @@ type Task interface {
 	//     该函数将在持有锁的情况下对最新配置进行修改。
 	ShouldRun(now time.Time, config *TaskConfig) (shouldRun bool, updateFunc func(latest *TaskConfig))
 
-	// Execute 执行任务
-	Execute() error
+	// Execute 执行任务；ctx 在调度器停止时取消，耗时任务应及时响应并返回
+	Execute(ctx context.Context) error
 
 	// OnExecuted 任务执行后的回调（用于更新下次执行时间等）
 	OnExecuted(now time.Time, config *TaskConfig, err error)
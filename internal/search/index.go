@@ -0,0 +1,338 @@
+package search
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/fileutil"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// indexVersion 索引格式版本，格式变化时递增以触发全量重建
+const indexVersion = 2
+
+// 文档类型
+const (
+	KindEntry   = "entry"   // 工作记录
+	KindDaily   = "daily"   // 日报
+	KindWeekly  = "weekly"  // 周报
+	KindMonthly = "monthly" // 月报
+)
+
+// Document 索引中的一个可搜索文档
+type Document struct {
+	ID      string   // 文档 ID（entry:<记录ID> / daily:<日期> / weekly:<文件名> / monthly:<文件名>）
+	Kind    string   // 文档类型
+	Date    string   // 日期（YYYY-MM-DD；周报为周末日期，月报为月末日期）
+	Time    string   // 记录时间（HH:MM，仅工作记录）
+	Text    string   // 可搜索文本
+	Tags    []string // 标签（仅工作记录）
+	Project string   // 项目（仅工作记录）
+	Ref     string   // 引用：工作记录 ID 或总结文件路径
+}
+
+// sourceState 数据源（某一天的记录或某个总结文件）的索引状态
+type sourceState struct {
+	Stamp  string   // 版本戳，变化时需要重新索引
+	DocIDs []string // 该数据源产生的文档
+}
+
+// Index 增量式全文索引（持久化到磁盘）
+// 记录按日期、总结按文件跟踪版本戳，每次更新只重新读取发生变化的部分
+type Index struct {
+	Version  int
+	Sources  map[string]*sourceState
+	Docs     map[string]*Document
+	Postings map[string]map[string]bool // 二元组 -> 文档 ID 集合
+
+	path  string
+	dirty bool
+}
+
+// UpdateStats 增量更新统计
+type UpdateStats struct {
+	Updated int // 重新索引的数据源数量
+	Removed int // 已删除的数据源数量
+}
+
+// Open 加载索引文件；文件不存在或版本不兼容时返回空索引
+func Open(path string) (*Index, error) {
+	idx := newIndex(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("read search index: %w", err)
+	}
+
+	var loaded Index
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&loaded); err != nil {
+		log.Printf("Warning: search index is corrupted, rebuilding: %v", err)
+		return idx, nil
+	}
+	if loaded.Version != indexVersion {
+		log.Printf("Search index version changed (%d -> %d), rebuilding", loaded.Version, indexVersion)
+		return idx, nil
+	}
+
+	loaded.path = path
+	if loaded.Sources == nil {
+		loaded.Sources = make(map[string]*sourceState)
+	}
+	if loaded.Docs == nil {
+		loaded.Docs = make(map[string]*Document)
+	}
+	if loaded.Postings == nil {
+		loaded.Postings = make(map[string]map[string]bool)
+	}
+	return &loaded, nil
+}
+
+// newIndex 创建空索引
+func newIndex(path string) *Index {
+	return &Index{
+		Version:  indexVersion,
+		Sources:  make(map[string]*sourceState),
+		Docs:     make(map[string]*Document),
+		Postings: make(map[string]map[string]bool),
+		path:     path,
+	}
+}
+
+// Refresh 加锁后加载索引、增量更新并保存
+// 索引路径旁的 .lock 文件保证多个 search 命令同时运行时不会互相覆盖
+func Refresh(path string, store storage.Storage, summaryDir string) (*Index, *UpdateStats, error) {
+	lock, err := fileutil.Lock(path + ".lock")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lock.Unlock()
+
+	idx, err := Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats, err := idx.Update(store, summaryDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := idx.Save(); err != nil {
+		return nil, nil, err
+	}
+
+	return idx, stats, nil
+}
+
+// Update 增量更新索引：对比数据源版本戳，只重新索引新增或修改的部分
+func (idx *Index) Update(store storage.Storage, summaryDir string) (*UpdateStats, error) {
+	stats := &UpdateStats{}
+	seen := make(map[string]bool)
+
+	// 工作记录：按日期跟踪
+	stamps, err := store.GetDateStamps()
+	if err != nil {
+		return nil, fmt.Errorf("get date stamps: %w", err)
+	}
+
+	for dateStr, stamp := range stamps {
+		key := "entries:" + dateStr
+		seen[key] = true
+
+		if state, ok := idx.Sources[key]; ok && state.Stamp == stamp {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			continue
+		}
+		dailyData, err := store.GetDailyData(date)
+		if err != nil {
+			log.Printf("Warning: skip unreadable data for %s: %v", dateStr, err)
+			continue
+		}
+
+		docs := make([]*Document, 0, len(dailyData.Entries))
+		for _, entry := range dailyData.Entries {
+			docs = append(docs, &Document{
+				ID:      "entry:" + entry.ID,
+				Kind:    KindEntry,
+				Date:    dateStr,
+				Time:    entry.Timestamp.Format("15:04"),
+				Text:    entry.Content,
+				Tags:    entry.Tags,
+				Project: entry.Project,
+				Ref:     entry.ID,
+			})
+		}
+		idx.replaceSource(key, stamp, docs)
+		stats.Updated++
+	}
+
+	// 总结文件：按文件跟踪
+	summarySources := []struct {
+		kind   string
+		dir    string
+		prefix string
+		layout string // 文件名中的日期格式
+	}{
+		{KindDaily, filepath.Join(summaryDir, "daily"), "", "2006-01-02"},
+		{KindWeekly, filepath.Join(summaryDir, "weekly"), "weekly-", "2006-01-02"},
+		{KindMonthly, filepath.Join(summaryDir, "monthly"), "monthly-", "2006-01"},
+	}
+
+	for _, src := range summarySources {
+		files, err := os.ReadDir(src.dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read summary directory: %w", err)
+		}
+
+		for _, file := range files {
+			name := file.Name()
+			ext := filepath.Ext(name)
+			if file.IsDir() || (ext != ".md" && ext != ".html") || !strings.HasPrefix(name, src.prefix) {
+				continue
+			}
+
+			dateStr := strings.TrimSuffix(strings.TrimPrefix(name, src.prefix), ext)
+			date, err := time.Parse(src.layout, dateStr)
+			if err != nil {
+				continue
+			}
+			if src.kind == KindMonthly {
+				dateStr = date.AddDate(0, 1, -1).Format("2006-01-02")
+			}
+
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+
+			path := filepath.Join(src.dir, name)
+			key := "file:" + src.kind + "/" + name
+			stamp := fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+			seen[key] = true
+
+			if state, ok := idx.Sources[key]; ok && state.Stamp == stamp {
+				continue
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("Warning: skip unreadable summary %s: %v", path, err)
+				continue
+			}
+
+			text := string(content)
+			if ext == ".html" {
+				text = htmlToText(text)
+			}
+
+			idx.replaceSource(key, stamp, []*Document{{
+				ID:   src.kind + ":" + name,
+				Kind: src.kind,
+				Date: dateStr,
+				Text: text,
+				Ref:  path,
+			}})
+			stats.Updated++
+		}
+	}
+
+	// 清理已不存在的数据源
+	for key := range idx.Sources {
+		if !seen[key] {
+			idx.replaceSource(key, "", nil)
+			delete(idx.Sources, key)
+			stats.Removed++
+		}
+	}
+
+	if stats.Updated > 0 || stats.Removed > 0 {
+		idx.dirty = true
+	}
+
+	return stats, nil
+}
+
+// replaceSource 用新文档替换数据源原有的文档
+func (idx *Index) replaceSource(key, stamp string, docs []*Document) {
+	if state, ok := idx.Sources[key]; ok {
+		for _, id := range state.DocIDs {
+			idx.removeDoc(id)
+		}
+	}
+
+	state := &sourceState{Stamp: stamp}
+	for _, doc := range docs {
+		idx.addDoc(doc)
+		state.DocIDs = append(state.DocIDs, doc.ID)
+	}
+	idx.Sources[key] = state
+}
+
+// addDoc 添加文档并更新倒排表
+func (idx *Index) addDoc(doc *Document) {
+	idx.Docs[doc.ID] = doc
+	for gram := range grams(doc.Text) {
+		posting, ok := idx.Postings[gram]
+		if !ok {
+			posting = make(map[string]bool)
+			idx.Postings[gram] = posting
+		}
+		posting[doc.ID] = true
+	}
+}
+
+// removeDoc 删除文档并更新倒排表
+func (idx *Index) removeDoc(id string) {
+	doc, ok := idx.Docs[id]
+	if !ok {
+		return
+	}
+	for gram := range grams(doc.Text) {
+		if posting, ok := idx.Postings[gram]; ok {
+			delete(posting, id)
+			if len(posting) == 0 {
+				delete(idx.Postings, gram)
+			}
+		}
+	}
+	delete(idx.Docs, id)
+}
+
+// Save 将索引原子写入磁盘（无变化时跳过）
+func (idx *Index) Save() error {
+	if !idx.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("create index directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(idx); err != nil {
+		return fmt.Errorf("encode search index: %w", err)
+	}
+
+	if err := fileutil.WriteFileAtomic(idx.path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write search index: %w", err)
+	}
+
+	idx.dirty = false
+	return nil
+}
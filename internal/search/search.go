@@ -0,0 +1,183 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// snippetRadius 结果片段在匹配位置前后保留的字符数
+const snippetRadius = 30
+
+// Query 搜索条件
+type Query struct {
+	Text    string   // 搜索内容（普通模式下按空白切分为多个词，全部匹配才算命中）
+	Regex   bool     // 是否将 Text 作为正则表达式
+	From    string   // 起始日期（YYYY-MM-DD，包含），为空表示不限
+	To      string   // 结束日期（YYYY-MM-DD，包含），为空表示不限
+	Tags    []string // 标签过滤（仅匹配带有全部标签的工作记录）
+	Project string   // 项目过滤（仅匹配属于该项目的工作记录）
+	Kinds   []string // 文档类型过滤（entry/daily/weekly/monthly），为空表示全部
+	Limit   int      // 最大结果数，0 表示不限
+}
+
+// Result 搜索结果
+type Result struct {
+	Doc     *Document
+	Snippet string // 匹配位置附近的文本片段
+}
+
+// Search 执行搜索，结果按日期从新到旧排序
+func (idx *Index) Search(q Query) ([]Result, error) {
+	matcher, terms, err := buildMatcher(q)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make(map[string]bool)
+	for _, kind := range q.Kinds {
+		kinds[kind] = true
+	}
+	tags := make([]string, 0, len(q.Tags))
+	for _, tag := range q.Tags {
+		tags = append(tags, models.NormalizeTag(tag))
+	}
+	project := models.NormalizeProject(q.Project)
+
+	var results []Result
+	for _, doc := range idx.candidates(terms) {
+		if len(kinds) > 0 && !kinds[doc.Kind] {
+			continue
+		}
+		if (q.From != "" && doc.Date < q.From) || (q.To != "" && doc.Date > q.To) {
+			continue
+		}
+		if len(tags) > 0 && !hasAllTags(doc, tags) {
+			continue
+		}
+		if project != "" && doc.Project != project {
+			continue
+		}
+
+		start, end, ok := matcher(doc.Text)
+		if !ok {
+			continue
+		}
+		results = append(results, Result{
+			Doc:     doc,
+			Snippet: snippet(doc.Text, start, end, snippetRadius),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].Doc, results[j].Doc
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		if a.Time != b.Time {
+			return a.Time > b.Time
+		}
+		return a.ID < b.ID
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	return results, nil
+}
+
+// buildMatcher 根据查询构建匹配函数，返回匹配的字符（rune）区间
+// 普通模式下同时返回用于倒排表筛选的查询词；正则模式无法使用倒排表，需全量扫描
+func buildMatcher(q Query) (func(text string) (int, int, bool), []string, error) {
+	if q.Regex {
+		re, err := regexp.Compile("(?i)" + q.Text)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return func(text string) (int, int, bool) {
+			loc := re.FindStringIndex(text)
+			if loc == nil {
+				return 0, 0, false
+			}
+			return utf8.RuneCountInString(text[:loc[0]]), utf8.RuneCountInString(text[:loc[1]]), true
+		}, nil, nil
+	}
+
+	terms := strings.Fields(strings.ToLower(q.Text))
+	return func(text string) (int, int, bool) {
+		lower := strings.ToLower(text)
+		first, firstEnd := -1, -1
+		for _, term := range terms {
+			pos := strings.Index(lower, term)
+			if pos < 0 {
+				return 0, 0, false
+			}
+			if first < 0 || pos < first {
+				first, firstEnd = pos, pos+len(term)
+			}
+		}
+		if first < 0 {
+			// 空查询（仅按日期/标签过滤）匹配所有文档
+			return 0, 0, true
+		}
+		return utf8.RuneCountInString(lower[:first]), utf8.RuneCountInString(lower[:firstEnd]), true
+	}, terms, nil
+}
+
+// candidates 通过倒排表筛选可能匹配的文档；无法利用索引时返回全部文档
+func (idx *Index) candidates(terms []string) []*Document {
+	var candidateIDs map[string]bool
+	for _, term := range terms {
+		for gram := range grams(term) {
+			posting := idx.Postings[gram]
+			if candidateIDs == nil {
+				candidateIDs = make(map[string]bool, len(posting))
+				for id := range posting {
+					candidateIDs[id] = true
+				}
+				continue
+			}
+			for id := range candidateIDs {
+				if !posting[id] {
+					delete(candidateIDs, id)
+				}
+			}
+		}
+	}
+
+	docs := make([]*Document, 0)
+	if candidateIDs == nil {
+		for _, doc := range idx.Docs {
+			docs = append(docs, doc)
+		}
+		return docs
+	}
+	for id := range candidateIDs {
+		if doc, ok := idx.Docs[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// hasAllTags 判断文档是否带有全部指定标签
+func hasAllTags(doc *Document, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, docTag := range doc.Tags {
+			if docTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
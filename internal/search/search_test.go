@@ -0,0 +1,99 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// TestIndexSearchAndIncrementalUpdate 测试索引构建、搜索过滤与增量更新
+func TestIndexSearchAndIncrementalUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	summaryDir := filepath.Join(tmpDir, "summaries")
+	indexPath := filepath.Join(tmpDir, "index", "search.gob")
+	os.MkdirAll(dataDir, 0755)
+	os.MkdirAll(filepath.Join(summaryDir, "weekly"), 0755)
+
+	store := storage.NewJSONStorage(dataDir, summaryDir)
+	day1 := time.Date(2026, 1, 19, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 1, 20, 15, 30, 0, 0, time.Local)
+	store.SaveEntry(models.WorkEntry{Timestamp: day1, Content: "排查 Payment Service 超时问题 #oncall"})
+	store.SaveEntry(models.WorkEntry{Timestamp: day2, Content: "支付服务灰度发布"})
+	store.SaveSummary(day1, "## 主要完成的任务\n- 支付服务超时排查", models.SummaryMetadata{GeneratedAt: day1, EntryCount: 1})
+	os.WriteFile(filepath.Join(summaryDir, "weekly", "weekly-2026-01-25.html"),
+		[]byte("<html><style>.x{color:red}</style><body><h1>周报</h1><p>支付服务上线</p></body></html>"), 0644)
+
+	idx, stats, err := Refresh(indexPath, store, summaryDir)
+	if err != nil {
+		t.Fatalf("Failed to refresh index: %v", err)
+	}
+	if stats.Updated != 4 {
+		t.Errorf("Expected 4 sources indexed, got %d", stats.Updated)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{"case insensitive", Query{Text: "payment"}, 1},
+		{"cjk across kinds", Query{Text: "支付服务"}, 3},
+		{"date range", Query{Text: "支付服务", From: "2026-01-20", To: "2026-01-24"}, 1},
+		{"kind filter", Query{Text: "支付服务", Kinds: []string{KindWeekly}}, 1},
+		{"tag filter", Query{Tags: []string{"#oncall"}}, 1},
+		{"regex", Query{Text: `超时.*#on`, Regex: true}, 1},
+		{"html markup not indexed", Query{Text: "color"}, 0},
+		{"multiple terms", Query{Text: "灰度 支付"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(results) != tt.want {
+				t.Errorf("Expected %d results, got %d: %+v", tt.want, len(results), results)
+			}
+		})
+	}
+
+	// 无变化时不应重新索引
+	_, stats, err = Refresh(indexPath, store, summaryDir)
+	if err != nil {
+		t.Fatalf("Failed to refresh index: %v", err)
+	}
+	if stats.Updated != 0 || stats.Removed != 0 {
+		t.Errorf("Expected no changes, got %+v", stats)
+	}
+
+	// 修改记录并删除周报后，只更新变化的部分
+	data, _ := store.GetDailyData(day2)
+	entry := data.Entries[0]
+	entry.Content = "支付服务全量发布"
+	if err := store.UpdateEntry(entry); err != nil {
+		t.Fatalf("Failed to update entry: %v", err)
+	}
+	os.Remove(filepath.Join(summaryDir, "weekly", "weekly-2026-01-25.html"))
+
+	idx, stats, err = Refresh(indexPath, store, summaryDir)
+	if err != nil {
+		t.Fatalf("Failed to refresh index: %v", err)
+	}
+	if stats.Updated != 1 || stats.Removed != 1 {
+		t.Errorf("Expected 1 updated and 1 removed, got %+v", stats)
+	}
+
+	results, _ := idx.Search(Query{Text: "灰度"})
+	if len(results) != 0 {
+		t.Errorf("Stale content should be removed from index, got %+v", results)
+	}
+	results, _ = idx.Search(Query{Text: "全量"})
+	if len(results) != 1 || results[0].Doc.Ref != entry.ID {
+		t.Errorf("Updated content should be searchable, got %+v", results)
+	}
+}
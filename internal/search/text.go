@@ -0,0 +1,70 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]+>`)
+	spacePattern     = regexp.MustCompile(`[ \t\r\f\v]+`)
+	blankLinePattern = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// htmlToText 去除 HTML 中的样式、脚本和标签，只保留可读文本
+func htmlToText(content string) string {
+	text := htmlBlockPattern.ReplaceAllString(content, " ")
+	text = htmlTagPattern.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(text)
+	text = spacePattern.ReplaceAllString(text, " ")
+	text = blankLinePattern.ReplaceAllString(text, "\n")
+	return strings.TrimSpace(text)
+}
+
+// grams 将文本切分为二元组（bigram），用于倒排索引
+// 只在连续的字母/数字序列内切分，中英文统一处理，不依赖分词
+// 任何包含查询词的文本必然包含查询词的全部二元组，因此可以用它们的交集筛选候选文档
+func grams(text string) map[string]bool {
+	result := make(map[string]bool)
+
+	var prev rune
+	hasPrev := false
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			hasPrev = false
+			continue
+		}
+		if hasPrev {
+			result[string([]rune{prev, r})] = true
+		}
+		prev = r
+		hasPrev = true
+	}
+
+	return result
+}
+
+// snippet 截取匹配位置附近的文本片段
+func snippet(text string, start, end int, radius int) string {
+	runes := []rune(text)
+	from := start - radius
+	if from < 0 {
+		from = 0
+	}
+	to := end + radius
+	if to > len(runes) {
+		to = len(runes)
+	}
+
+	result := strings.Join(strings.Fields(string(runes[from:to])), " ")
+	if from > 0 {
+		result = "…" + result
+	}
+	if to < len(runes) {
+		result += "…"
+	}
+	return result
+}
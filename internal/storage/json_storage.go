@@ -6,11 +6,19 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"humg.top/daily_summary/internal/fileutil"
 	"humg.top/daily_summary/internal/models"
 )
 
+// DataLockFile 数据目录下的锁文件名
+// serve 守护进程与 add/edit 等命令可能同时修改同一天的数据文件，
+// 所有"读取-修改-写回"操作都需要持有该锁
+const DataLockFile = ".daily_summary.lock"
+
 // JSONStorage JSON 文件存储实现
 type JSONStorage struct {
 	dataDir    string
@@ -25,8 +33,19 @@ func NewJSONStorage(dataDir, summaryDir string) *JSONStorage {
 	}
 }
 
+// lock 获取数据目录的跨进程排他锁
+func (s *JSONStorage) lock() (*fileutil.FileLock, error) {
+	return fileutil.Lock(filepath.Join(s.dataDir, DataLockFile))
+}
+
 // SaveEntry 保存工作记录
 func (s *JSONStorage) SaveEntry(entry models.WorkEntry) error {
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// 获取当天的数据文件路径
 	date := entry.Timestamp.Format("2006-01-02")
 	filePath := filepath.Join(s.dataDir, fmt.Sprintf("%s.json", date))
@@ -49,15 +68,21 @@ func (s *JSONStorage) SaveEntry(entry models.WorkEntry) error {
 	}
 
 	// 添加新记录
-	dailyData.Entries = append(dailyData.Entries, entry)
+	if entry.ID == "" {
+		entry.ID = models.NewEntryID(entry.Timestamp)
+	}
+	entry.ParseMeta()
+	fillLegacyFields(&dailyData)
+	insertEntry(&dailyData, entry)
 
-	// 保存回文件
+	// 保存回文件（写入的数据已是当前格式）
+	dailyData.SchemaVersion = models.CurrentSchemaVersion
 	data, err = json.MarshalIndent(dailyData, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal daily data: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
 		return fmt.Errorf("write daily data file: %w", err)
 	}
 
@@ -84,10 +109,119 @@ func (s *JSONStorage) GetDailyData(date time.Time) (*models.DailyData, error) {
 	if err := json.Unmarshal(data, &dailyData); err != nil {
 		return nil, fmt.Errorf("unmarshal daily data: %w", err)
 	}
+	fillLegacyFields(&dailyData)
 
 	return &dailyData, nil
 }
 
+// GetEntry 根据 ID 获取工作记录
+func (s *JSONStorage) GetEntry(id string) (*models.WorkEntry, error) {
+	date, err := models.EntryIDDate(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+
+	dailyData, err := s.GetDailyData(date)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range dailyData.Entries {
+		if dailyData.Entries[i].ID == id {
+			return &dailyData.Entries[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+}
+
+// UpdateEntry 更新工作记录内容
+func (s *JSONStorage) UpdateEntry(entry models.WorkEntry) error {
+	return s.modifyEntry(entry.ID, func(dailyData *models.DailyData, index int) {
+		dailyData.Entries[index].Content = entry.Content
+		dailyData.Entries[index].ParseMeta()
+	})
+}
+
+// DeleteEntry 删除工作记录
+func (s *JSONStorage) DeleteEntry(id string) error {
+	return s.modifyEntry(id, func(dailyData *models.DailyData, index int) {
+		dailyData.Entries = append(dailyData.Entries[:index], dailyData.Entries[index+1:]...)
+	})
+}
+
+// modifyEntry 定位记录所在的数据文件，执行修改并写回
+// 修改后如果该日已生成总结，重置 SummaryGenerated 以便下次重新生成
+func (s *JSONStorage) modifyEntry(id string, modify func(dailyData *models.DailyData, index int)) error {
+	date, err := models.EntryIDDate(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrEntryNotFound, err)
+	}
+
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	dateStr := date.Format("2006-01-02")
+	filePath := filepath.Join(s.dataDir, fmt.Sprintf("%s.json", dateStr))
+
+	dailyData, err := s.GetDailyData(date)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i := range dailyData.Entries {
+		if dailyData.Entries[i].ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+	}
+
+	modify(dailyData, index)
+	dailyData.SummaryGenerated = false
+	dailyData.SchemaVersion = models.CurrentSchemaVersion
+
+	data, err := json.MarshalIndent(dailyData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal daily data: %w", err)
+	}
+
+	if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
+		return fmt.Errorf("write daily data file: %w", err)
+	}
+
+	return nil
+}
+
+// insertEntry 按时间顺序插入记录（补录或导入较早的记录时不会打乱顺序；时间相同时排在后面）
+func insertEntry(dailyData *models.DailyData, entry models.WorkEntry) {
+	i := sort.Search(len(dailyData.Entries), func(i int) bool {
+		return dailyData.Entries[i].Timestamp.After(entry.Timestamp)
+	})
+	dailyData.Entries = append(dailyData.Entries, models.WorkEntry{})
+	copy(dailyData.Entries[i+1:], dailyData.Entries[i:])
+	dailyData.Entries[i] = entry
+}
+
+// fillLegacyFields 为历史记录补充确定性 ID 以及标签/项目（下次写回文件时持久化）
+func fillLegacyFields(dailyData *models.DailyData) {
+	for i := range dailyData.Entries {
+		entry := &dailyData.Entries[i]
+		if entry.ID == "" {
+			entry.ID = models.LegacyEntryID(*entry)
+		}
+		if entry.Tags == nil && entry.Project == "" {
+			entry.ParseMeta()
+		}
+	}
+}
+
 // GetLastEntry 获取最后一条工作记录
 func (s *JSONStorage) GetLastEntry() (*models.WorkEntry, error) {
 	// 获取今天和昨天的数据
@@ -141,12 +275,27 @@ func (s *JSONStorage) SaveSummary(date time.Time, summary string, metadata model
 	dateStr := date.Format("2006-01-02")
 	filePath := filepath.Join(dailyDir, fmt.Sprintf("%s.md", dateStr))
 
-	// 构建 Markdown 内容
-	content := fmt.Sprintf(`# 工作总结 - %s
+	content := formatDailySummary(dateStr, summary, metadata)
+
+	if err := fileutil.WriteFileAtomic(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write summary file: %w", err)
+	}
+
+	return nil
+}
+
+// formatDailySummary 构建日报 Markdown 文件内容（JSON 与 SQLite 存储共用）
+func formatDailySummary(dateStr, summary string, metadata models.SummaryMetadata) string {
+	var provider string
+	if metadata.Provider != "" {
+		provider = fmt.Sprintf("生成方式: %s\n", metadata.Provider)
+	}
+
+	return fmt.Sprintf(`# 工作总结 - %s
 
 生成时间: %s
 记录条数: %d
-
+%s
 ---
 
 %s
@@ -154,14 +303,9 @@ func (s *JSONStorage) SaveSummary(date time.Time, summary string, metadata model
 		dateStr,
 		metadata.GeneratedAt.Format("2006-01-02 15:04:05"),
 		metadata.EntryCount,
+		provider,
 		summary,
 	)
-
-	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
-		return fmt.Errorf("write summary file: %w", err)
-	}
-
-	return nil
 }
 
 // GetSummary 获取总结
@@ -179,6 +323,12 @@ func (s *JSONStorage) GetSummary(date time.Time) (string, error) {
 
 // MarkSummaryGenerated 标记指定日期的总结已生成
 func (s *JSONStorage) MarkSummaryGenerated(date time.Time) error {
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	dateStr := date.Format("2006-01-02")
 	filePath := filepath.Join(s.dataDir, fmt.Sprintf("%s.json", dateStr))
 
@@ -200,17 +350,50 @@ func (s *JSONStorage) MarkSummaryGenerated(date time.Time) error {
 		if err := json.Unmarshal(data, &dailyData); err != nil {
 			return fmt.Errorf("unmarshal daily data: %w", err)
 		}
+		fillLegacyFields(&dailyData)
 		// 标记为已生成
 		dailyData.SummaryGenerated = true
 	}
 
-	// 保存回文件
+	// 保存回文件（写入的数据已是当前格式）
+	dailyData.SchemaVersion = models.CurrentSchemaVersion
 	data, err = json.MarshalIndent(dailyData, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal daily data: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
+		return fmt.Errorf("write daily data file: %w", err)
+	}
+
+	return nil
+}
+
+// ResetSummaryGenerated 清除指定日期的总结已生成标记
+func (s *JSONStorage) ResetSummaryGenerated(date time.Time) error {
+	lock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	dailyData, err := s.GetDailyData(date)
+	if err != nil {
+		return err
+	}
+	if !dailyData.SummaryGenerated {
+		return nil
+	}
+
+	dailyData.SummaryGenerated = false
+	dailyData.SchemaVersion = models.CurrentSchemaVersion
+	data, err := json.MarshalIndent(dailyData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal daily data: %w", err)
+	}
+
+	filePath := filepath.Join(s.dataDir, fmt.Sprintf("%s.json", date.Format("2006-01-02")))
+	if err := fileutil.WriteFileAtomic(filePath, data, 0644); err != nil {
 		return fmt.Errorf("write daily data file: %w", err)
 	}
 
@@ -255,8 +438,12 @@ func (s *JSONStorage) SaveWeeklySummary(weekEndDate time.Time, summary string, m
 	filePath := filepath.Join(weeklyDir, filename)
 
 	// 直接保存 AI 生成的 HTML 内容
-	// AI 已经按照 Prompt 要求生成了完整的 HTML 文档
-	if err := os.WriteFile(filePath, []byte(summary), 0644); err != nil {
+	// AI 已经按照 Prompt 要求生成了完整的 HTML 文档，生成它的提供商以注释形式记录在末尾
+	content := summary
+	if metadata.Provider != "" {
+		content = strings.TrimRight(summary, "\n") + fmt.Sprintf("\n<!-- provider: %s -->\n", metadata.Provider)
+	}
+	if err := fileutil.WriteFileAtomic(filePath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("write weekly summary file: %w", err)
 	}
 
@@ -266,15 +453,183 @@ func (s *JSONStorage) SaveWeeklySummary(weekEndDate time.Time, summary string, m
 	return nil
 }
 
+// GetWeeklySummariesInRange 获取周末日期在范围内的周报
+func (s *JSONStorage) GetWeeklySummariesInRange(startDate, endDate time.Time) (map[string]string, error) {
+	result := make(map[string]string)
+
+	current := startDate
+	for !current.After(endDate) {
+		dateStr := current.Format("2006-01-02")
+		filePath := filepath.Join(s.summaryDir, "weekly", fmt.Sprintf("weekly-%s.html", dateStr))
+
+		data, err := os.ReadFile(filePath)
+		if err == nil && len(data) > 0 {
+			result[dateStr] = string(data)
+		}
+
+		current = current.AddDate(0, 0, 1)
+	}
+
+	return result, nil
+}
+
+// SaveMonthlySummary 保存月度总结
+func (s *JSONStorage) SaveMonthlySummary(month time.Time, summary string, metadata models.SummaryMetadata) error {
+	// 月报目录：summaryDir/monthly
+	monthlyDir := filepath.Join(s.summaryDir, "monthly")
+
+	if err := os.MkdirAll(monthlyDir, 0755); err != nil {
+		return fmt.Errorf("create monthly directory: %w", err)
+	}
+
+	// 文件名：monthly-YYYY-MM.md
+	monthStr := month.Format("2006-01")
+	filePath := filepath.Join(monthlyDir, fmt.Sprintf("monthly-%s.md", monthStr))
+
+	content := formatMonthlySummary(monthStr, summary, metadata)
+	if err := fileutil.WriteFileAtomic(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write monthly summary file: %w", err)
+	}
+
+	log.Printf("✓ 月报已生成并保存到: %s", filePath)
+	return nil
+}
+
+// formatMonthlySummary 构建月报 Markdown 文件内容（JSON 与 SQLite 存储共用）
+func formatMonthlySummary(monthStr, summary string, metadata models.SummaryMetadata) string {
+	var provider string
+	if metadata.Provider != "" {
+		provider = fmt.Sprintf("生成方式: %s\n", metadata.Provider)
+	}
+
+	return fmt.Sprintf(`# 月度总结 - %s
+
+生成时间: %s
+日报篇数: %d
+%s
+---
+
+%s
+`,
+		monthStr,
+		metadata.GeneratedAt.Format("2006-01-02 15:04:05"),
+		metadata.EntryCount,
+		provider,
+		summary,
+	)
+}
+
+// GetMonthlySummary 获取月度总结
+func (s *JSONStorage) GetMonthlySummary(month time.Time) (string, error) {
+	filePath := filepath.Join(s.summaryDir, "monthly", fmt.Sprintf("monthly-%s.md", month.Format("2006-01")))
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("read monthly summary file: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// SaveReport 保存自定义日期范围的报告
+func (s *JSONStorage) SaveReport(filename string, summary string, metadata models.SummaryMetadata) error {
+	// 报告目录：summaryDir/reports
+	reportsDir := filepath.Join(s.summaryDir, "reports")
+
+	if err := os.MkdirAll(reportsDir, 0755); err != nil {
+		return fmt.Errorf("create reports directory: %w", err)
+	}
+
+	filePath := filepath.Join(reportsDir, filepath.Base(filename))
+
+	var content string
+	if strings.HasSuffix(filename, ".html") {
+		// 与周报相同：直接保存 HTML 文档，提供商以注释形式记录在末尾
+		content = summary
+		if metadata.Provider != "" {
+			content = strings.TrimRight(summary, "\n") + fmt.Sprintf("\n<!-- provider: %s -->\n", metadata.Provider)
+		}
+	} else {
+		var provider string
+		if metadata.Provider != "" {
+			provider = fmt.Sprintf("生成方式: %s\n", metadata.Provider)
+		}
+		content = fmt.Sprintf(`# 工作报告 - %s
+
+生成时间: %s
+记录条数: %d
+%s
+---
+
+%s
+`,
+			metadata.Date,
+			metadata.GeneratedAt.Format("2006-01-02 15:04:05"),
+			metadata.EntryCount,
+			provider,
+			summary,
+		)
+	}
+
+	if err := fileutil.WriteFileAtomic(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write report file: %w", err)
+	}
+
+	log.Printf("✓ 报告已生成并保存到: %s", filePath)
+	return nil
+}
+
 // GetUngeneratedDates 获取所有有数据但未生成日报的日期
 func (s *JSONStorage) GetUngeneratedDates(endDate time.Time) ([]time.Time, error) {
 	var ungeneratedDates []time.Time
 
-	// 读取 dataDir 目录下的所有 JSON 文件
+	// 读取 dataDir 目录下的所有日期数据文件
+	dates, err := s.listDates()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, date := range dates {
+		// 只检查 endDate 之前的日期（不包含 endDate）
+		if !date.Before(endDate) {
+			continue
+		}
+
+		// 读取该日期的数据
+		dailyData, err := s.GetDailyData(date)
+		if err != nil {
+			// 跳过读取失败的文件（可通过 fsck 命令检查和修复）
+			log.Printf("Warning: skip unreadable data file for %s: %v (run 'daily_summary fsck' to check)", date.Format("2006-01-02"), err)
+			continue
+		}
+
+		// 检查是否有记录且未生成总结
+		if len(dailyData.Entries) > 0 && !dailyData.SummaryGenerated {
+			ungeneratedDates = append(ungeneratedDates, date)
+		}
+	}
+
+	// 按日期从旧到新排序
+	// time.Time 可以直接比较，使用冒泡排序保持简单
+	for i := 0; i < len(ungeneratedDates); i++ {
+		for j := i + 1; j < len(ungeneratedDates); j++ {
+			if ungeneratedDates[i].After(ungeneratedDates[j]) {
+				ungeneratedDates[i], ungeneratedDates[j] = ungeneratedDates[j], ungeneratedDates[i]
+			}
+		}
+	}
+
+	return ungeneratedDates, nil
+}
+
+// listDates 列出 dataDir 中所有数据文件对应的日期（按文件名顺序，即从旧到新）
+func (s *JSONStorage) listDates() ([]time.Time, error) {
+	var dates []time.Time
+
 	entries, err := os.ReadDir(s.dataDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return ungeneratedDates, nil
+			return dates, nil
 		}
 		return nil, fmt.Errorf("read data directory: %w", err)
 	}
@@ -298,33 +653,29 @@ func (s *JSONStorage) GetUngeneratedDates(endDate time.Time) ([]time.Time, error
 			continue
 		}
 
-		// 只检查 endDate 之前的日期（不包含 endDate）
-		if !date.Before(endDate) {
-			continue
-		}
+		dates = append(dates, date)
+	}
 
-		// 读取该日期的数据
-		dailyData, err := s.GetDailyData(date)
-		if err != nil {
-			// 跳过读取失败的文件
-			continue
-		}
+	return dates, nil
+}
 
-		// 检查是否有记录且未生成总结
-		if len(dailyData.Entries) > 0 && !dailyData.SummaryGenerated {
-			ungeneratedDates = append(ungeneratedDates, date)
-		}
+// GetDateStamps 获取所有数据文件的版本戳（修改时间 + 文件大小）
+func (s *JSONStorage) GetDateStamps() (map[string]string, error) {
+	stamps := make(map[string]string)
+
+	dates, err := s.listDates()
+	if err != nil {
+		return nil, err
 	}
 
-	// 按日期从旧到新排序
-	// time.Time 可以直接比较，使用冒泡排序保持简单
-	for i := 0; i < len(ungeneratedDates); i++ {
-		for j := i + 1; j < len(ungeneratedDates); j++ {
-			if ungeneratedDates[i].After(ungeneratedDates[j]) {
-				ungeneratedDates[i], ungeneratedDates[j] = ungeneratedDates[j], ungeneratedDates[i]
-			}
+	for _, date := range dates {
+		dateStr := date.Format("2006-01-02")
+		info, err := os.Stat(filepath.Join(s.dataDir, fmt.Sprintf("%s.json", dateStr)))
+		if err != nil {
+			continue
 		}
+		stamps[dateStr] = fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
 	}
 
-	return ungeneratedDates, nil
+	return stamps, nil
 }
@@ -0,0 +1,745 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // 纯 Go 实现的 SQLite 驱动，无需 CGO
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// sqliteSchema SQLite 表结构
+// days: 每日状态（是否已生成总结）
+// entries: 工作记录，按时间戳排序（相同时间按写入顺序）
+// summaries: 日报/周报/月报内容（kind 为 daily、weekly 或 monthly，月报的 date 为 YYYY-MM）
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS days (
+	date              TEXT PRIMARY KEY,
+	summary_generated INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS entries (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	date      TEXT NOT NULL,
+	timestamp TEXT NOT NULL,
+	content   TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_entries_date ON entries(date);
+
+CREATE TABLE IF NOT EXISTS summaries (
+	kind         TEXT NOT NULL,
+	date         TEXT NOT NULL,
+	content      TEXT NOT NULL,
+	generated_at TEXT NOT NULL,
+	entry_count  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (kind, date)
+);
+`
+
+// sqliteMigrations 数据库结构升级步骤，第 i 个元素将 user_version 从 i 升级到 i+1
+var sqliteMigrations = []func(tx *sql.Tx) error{
+	// v1: 工作记录增加稳定 ID（uid），并为已有记录补充 ID
+	func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`ALTER TABLE entries ADD COLUMN uid TEXT`); err != nil {
+			return err
+		}
+		rows, err := tx.Query(`SELECT id, timestamp, content FROM entries`)
+		if err != nil {
+			return err
+		}
+		ids := make(map[int64]string)
+		for rows.Next() {
+			var rowID int64
+			var timestamp, content string
+			if err := rows.Scan(&rowID, &timestamp, &content); err != nil {
+				rows.Close()
+				return err
+			}
+			ts, _ := time.Parse(time.RFC3339Nano, timestamp)
+			ids[rowID] = models.LegacyEntryID(models.WorkEntry{Timestamp: ts, Content: content})
+		}
+		rows.Close()
+		for rowID, uid := range ids {
+			if _, err := tx.Exec(`UPDATE entries SET uid = ? WHERE id = ?`, uid, rowID); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_entries_uid ON entries(uid)`)
+		return err
+	},
+	// v2: 每日数据增加修订号（revision），每次修改该日数据时递增，用作版本戳
+	func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE days ADD COLUMN revision INTEGER NOT NULL DEFAULT 0`)
+		return err
+	},
+	// v3: 工作记录增加标签（空格分隔）和项目，并从已有记录内容中解析
+	func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			`ALTER TABLE entries ADD COLUMN tags TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE entries ADD COLUMN project TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_entries_project ON entries(project)`,
+		} {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		rows, err := tx.Query(`SELECT id, content FROM entries`)
+		if err != nil {
+			return err
+		}
+		contents := make(map[int64]string)
+		for rows.Next() {
+			var rowID int64
+			var content string
+			if err := rows.Scan(&rowID, &content); err != nil {
+				rows.Close()
+				return err
+			}
+			contents[rowID] = content
+		}
+		rows.Close()
+		for rowID, content := range contents {
+			tags, project := models.ParseEntryMeta(content)
+			if _, err := tx.Exec(`UPDATE entries SET tags = ?, project = ? WHERE id = ?`,
+				strings.Join(tags, " "), project, rowID); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	// v4: 总结记录生成它的 AI 提供商
+	func(tx *sql.Tx) error {
+		_, err := tx.Exec(`ALTER TABLE summaries ADD COLUMN provider TEXT NOT NULL DEFAULT ''`)
+		return err
+	},
+}
+
+// entryColumns 查询工作记录时的列顺序（与 scanEntry 对应）
+const entryColumns = `uid, timestamp, content, tags, project`
+
+// SQLiteStorage SQLite 数据库存储实现
+// 工作记录与总结均保存在数据库中，总结同时写入 summaryDir 下的 Markdown/HTML 文件，方便直接查看
+type SQLiteStorage struct {
+	db    *sql.DB
+	files *JSONStorage // 复用 JSON 存储的总结文件写入逻辑
+}
+
+// NewSQLiteStorage 创建 SQLite 存储实例（数据库文件不存在时自动创建）
+func NewSQLiteStorage(dbPath, summaryDir string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("create database directory: %w", err)
+	}
+
+	// WAL 模式 + busy_timeout，允许 serve 进程与 add 命令并发读写
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", dbPath)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init sqlite schema: %w", err)
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate sqlite schema: %w", err)
+	}
+
+	return &SQLiteStorage{
+		db:    db,
+		files: NewJSONStorage("", summaryDir),
+	}, nil
+}
+
+// SnapshotSQLite 将数据库一致地复制到 dst（VACUUM INTO，不受正在进行的写入和 WAL 文件影响）
+// dst 必须不存在
+func SnapshotSQLite(dbPath, dst string) error {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)", dbPath)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("open sqlite database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`VACUUM INTO ?`, dst); err != nil {
+		return fmt.Errorf("snapshot sqlite database: %w", err)
+	}
+	return nil
+}
+
+// migrateSQLite 按 user_version 依次执行未应用的升级步骤
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("read user_version: %w", err)
+	}
+
+	for version < len(sqliteMigrations) {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		if err := sqliteMigrations[version](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration to v%d: %w", version+1, err)
+		}
+		// PRAGMA 不支持参数绑定
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("update user_version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration: %w", err)
+		}
+		version++
+		log.Printf("SQLite schema migrated to v%d", version)
+	}
+
+	return nil
+}
+
+// Close 关闭数据库连接
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// SaveEntry 保存工作记录
+func (s *SQLiteStorage) SaveEntry(entry models.WorkEntry) error {
+	date := entry.Timestamp.Format("2006-01-02")
+	if entry.ID == "" {
+		entry.ID = models.NewEntryID(entry.Timestamp)
+	}
+	entry.ParseMeta()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO days (date, revision) VALUES (?, 1)
+		ON CONFLICT(date) DO UPDATE SET revision = revision + 1`, date); err != nil {
+		return fmt.Errorf("insert day: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO entries (uid, date, timestamp, content, tags, project) VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.ID, date, entry.Timestamp.Format(time.RFC3339Nano), entry.Content,
+		strings.Join(entry.Tags, " "), entry.Project); err != nil {
+		return fmt.Errorf("insert entry: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetEntry 根据 ID 获取工作记录
+func (s *SQLiteStorage) GetEntry(id string) (*models.WorkEntry, error) {
+	rows, err := s.db.Query(`SELECT `+entryColumns+` FROM entries WHERE uid = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query entry: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("query entry: %w", err)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+	}
+
+	entry, err := scanEntry(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// UpdateEntry 更新工作记录内容
+func (s *SQLiteStorage) UpdateEntry(entry models.WorkEntry) error {
+	entry.ParseMeta()
+	return s.modifyEntry(entry.ID, `UPDATE entries SET content = ?, tags = ?, project = ? WHERE uid = ?`,
+		entry.Content, strings.Join(entry.Tags, " "), entry.Project, entry.ID)
+}
+
+// DeleteEntry 删除工作记录
+func (s *SQLiteStorage) DeleteEntry(id string) error {
+	return s.modifyEntry(id, `DELETE FROM entries WHERE uid = ?`, id)
+}
+
+// modifyEntry 在事务中执行对单条记录的修改，并重置该日期的 summary_generated
+func (s *SQLiteStorage) modifyEntry(id, query string, args ...interface{}) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var date string
+	err = tx.QueryRow(`SELECT date FROM entries WHERE uid = ?`, id).Scan(&date)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", ErrEntryNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("query entry: %w", err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("modify entry: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE days SET summary_generated = 0, revision = revision + 1 WHERE date = ?`, date); err != nil {
+		return fmt.Errorf("reset summary flag: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetDailyData 获取指定日期的工作记录
+func (s *SQLiteStorage) GetDailyData(date time.Time) (*models.DailyData, error) {
+	dateStr := date.Format("2006-01-02")
+
+	dailyData := &models.DailyData{
+		Date:    dateStr,
+		Entries: []models.WorkEntry{},
+	}
+
+	var generated int
+	err := s.db.QueryRow(`SELECT summary_generated FROM days WHERE date = ?`, dateStr).Scan(&generated)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("query day: %w", err)
+	}
+	dailyData.SummaryGenerated = generated != 0
+
+	rows, err := s.db.Query(`SELECT `+entryColumns+` FROM entries WHERE date = ? ORDER BY timestamp, id`, dateStr)
+	if err != nil {
+		return nil, fmt.Errorf("query entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		dailyData.Entries = append(dailyData.Entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate entries: %w", err)
+	}
+
+	return dailyData, nil
+}
+
+// GetLastEntry 获取最后一条工作记录
+func (s *SQLiteStorage) GetLastEntry() (*models.WorkEntry, error) {
+	rows, err := s.db.Query(`SELECT ` + entryColumns + ` FROM entries ORDER BY date DESC, timestamp DESC, id DESC LIMIT 1`)
+	if err != nil {
+		return nil, fmt.Errorf("query last entry: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	entry, err := scanEntry(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SaveSummary 保存总结
+func (s *SQLiteStorage) SaveSummary(date time.Time, summary string, metadata models.SummaryMetadata) error {
+	dateStr := date.Format("2006-01-02")
+	content := formatDailySummary(dateStr, summary, metadata)
+
+	if err := s.saveSummaryRow("daily", dateStr, content, metadata); err != nil {
+		return err
+	}
+
+	// 同步写入 Markdown 文件
+	return s.files.SaveSummary(date, summary, metadata)
+}
+
+// GetSummary 获取总结
+func (s *SQLiteStorage) GetSummary(date time.Time) (string, error) {
+	dateStr := date.Format("2006-01-02")
+
+	var content string
+	err := s.db.QueryRow(`SELECT content FROM summaries WHERE kind = 'daily' AND date = ?`, dateStr).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("summary not found: %s", dateStr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("query summary: %w", err)
+	}
+
+	return content, nil
+}
+
+// MarkSummaryGenerated 标记指定日期的总结已生成
+func (s *SQLiteStorage) MarkSummaryGenerated(date time.Time) error {
+	dateStr := date.Format("2006-01-02")
+
+	_, err := s.db.Exec(`INSERT INTO days (date, summary_generated) VALUES (?, 1)
+		ON CONFLICT(date) DO UPDATE SET summary_generated = 1`, dateStr)
+	if err != nil {
+		return fmt.Errorf("mark summary generated: %w", err)
+	}
+
+	return nil
+}
+
+// ResetSummaryGenerated 清除指定日期的总结已生成标记
+func (s *SQLiteStorage) ResetSummaryGenerated(date time.Time) error {
+	_, err := s.db.Exec(`UPDATE days SET summary_generated = 0, revision = revision + 1
+		WHERE date = ? AND summary_generated != 0`, date.Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("reset summary generated: %w", err)
+	}
+
+	return nil
+}
+
+// GetDailySummariesInRange 获取日期范围内的每日总结
+func (s *SQLiteStorage) GetDailySummariesInRange(startDate, endDate time.Time) (map[string]string, error) {
+	result := make(map[string]string)
+
+	rows, err := s.db.Query(`SELECT date, content FROM summaries
+		WHERE kind = 'daily' AND date >= ? AND date <= ? AND content != ''`,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dateStr, content string
+		if err := rows.Scan(&dateStr, &content); err != nil {
+			return nil, fmt.Errorf("scan summary: %w", err)
+		}
+		result[dateStr] = content
+	}
+
+	return result, rows.Err()
+}
+
+// SaveWeeklySummary 保存周度总结
+func (s *SQLiteStorage) SaveWeeklySummary(weekEndDate time.Time, summary string, metadata models.SummaryMetadata) error {
+	if err := s.saveSummaryRow("weekly", weekEndDate.Format("2006-01-02"), summary, metadata); err != nil {
+		return err
+	}
+
+	// 同步写入 HTML 文件
+	return s.files.SaveWeeklySummary(weekEndDate, summary, metadata)
+}
+
+// GetWeeklySummariesInRange 获取周末日期在范围内的周报
+func (s *SQLiteStorage) GetWeeklySummariesInRange(startDate, endDate time.Time) (map[string]string, error) {
+	result := make(map[string]string)
+
+	rows, err := s.db.Query(`SELECT date, content FROM summaries
+		WHERE kind = 'weekly' AND date >= ? AND date <= ? AND content != ''`,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("query weekly summaries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dateStr, content string
+		if err := rows.Scan(&dateStr, &content); err != nil {
+			return nil, fmt.Errorf("scan weekly summary: %w", err)
+		}
+		result[dateStr] = content
+	}
+
+	return result, rows.Err()
+}
+
+// SaveMonthlySummary 保存月度总结
+func (s *SQLiteStorage) SaveMonthlySummary(month time.Time, summary string, metadata models.SummaryMetadata) error {
+	monthStr := month.Format("2006-01")
+	content := formatMonthlySummary(monthStr, summary, metadata)
+
+	if err := s.saveSummaryRow("monthly", monthStr, content, metadata); err != nil {
+		return err
+	}
+
+	// 同步写入 Markdown 文件
+	return s.files.SaveMonthlySummary(month, summary, metadata)
+}
+
+// GetMonthlySummary 获取月度总结
+func (s *SQLiteStorage) GetMonthlySummary(month time.Time) (string, error) {
+	monthStr := month.Format("2006-01")
+
+	var content string
+	err := s.db.QueryRow(`SELECT content FROM summaries WHERE kind = 'monthly' AND date = ?`, monthStr).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("monthly summary not found: %s", monthStr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("query monthly summary: %w", err)
+	}
+
+	return content, nil
+}
+
+// SaveReport 保存自定义日期范围的报告
+// 报告按需生成、随时可以重新生成，只写入 summaries/reports 下的文件，不存入数据库
+func (s *SQLiteStorage) SaveReport(filename string, summary string, metadata models.SummaryMetadata) error {
+	return s.files.SaveReport(filename, summary, metadata)
+}
+
+// GetUngeneratedDates 获取所有有数据但未生成日报的日期
+func (s *SQLiteStorage) GetUngeneratedDates(endDate time.Time) ([]time.Time, error) {
+	var ungeneratedDates []time.Time
+
+	rows, err := s.db.Query(`SELECT d.date FROM days d
+		WHERE d.summary_generated = 0 AND d.date < ?
+		AND EXISTS (SELECT 1 FROM entries e WHERE e.date = d.date)
+		ORDER BY d.date`, endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("query ungenerated dates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dateStr string
+		if err := rows.Scan(&dateStr); err != nil {
+			return nil, fmt.Errorf("scan date: %w", err)
+		}
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			continue
+		}
+		ungeneratedDates = append(ungeneratedDates, date)
+	}
+
+	return ungeneratedDates, rows.Err()
+}
+
+// GetDateStamps 获取所有有记录的日期及其修订号
+func (s *SQLiteStorage) GetDateStamps() (map[string]string, error) {
+	stamps := make(map[string]string)
+
+	rows, err := s.db.Query(`SELECT d.date, d.revision FROM days d
+		WHERE EXISTS (SELECT 1 FROM entries e WHERE e.date = d.date)`)
+	if err != nil {
+		return nil, fmt.Errorf("query date stamps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dateStr string
+		var revision int64
+		if err := rows.Scan(&dateStr, &revision); err != nil {
+			return nil, fmt.Errorf("scan date stamp: %w", err)
+		}
+		stamps[dateStr] = fmt.Sprintf("r%d", revision)
+	}
+
+	return stamps, rows.Err()
+}
+
+// saveSummaryRow 写入或覆盖一条总结记录
+func (s *SQLiteStorage) saveSummaryRow(kind, dateStr, content string, metadata models.SummaryMetadata) error {
+	_, err := s.db.Exec(`INSERT INTO summaries (kind, date, content, generated_at, entry_count, provider)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, date) DO UPDATE SET
+			content = excluded.content,
+			generated_at = excluded.generated_at,
+			entry_count = excluded.entry_count,
+			provider = excluded.provider`,
+		kind, dateStr, content, metadata.GeneratedAt.Format(time.RFC3339), metadata.EntryCount, metadata.Provider)
+	if err != nil {
+		return fmt.Errorf("save %s summary: %w", kind, err)
+	}
+	return nil
+}
+
+// scanEntry 从查询结果中解析一条工作记录
+func scanEntry(rows *sql.Rows) (models.WorkEntry, error) {
+	var uid sql.NullString
+	var timestamp, content, tags, project string
+	if err := rows.Scan(&uid, &timestamp, &content, &tags, &project); err != nil {
+		return models.WorkEntry{}, fmt.Errorf("scan entry: %w", err)
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return models.WorkEntry{}, fmt.Errorf("parse entry timestamp %q: %w", timestamp, err)
+	}
+
+	return models.WorkEntry{
+		ID:        uid.String,
+		Timestamp: ts,
+		Content:   content,
+		Tags:      strings.Fields(tags),
+		Project:   project,
+	}, nil
+}
+
+// ImportResult JSON 数据导入结果
+type ImportResult struct {
+	Days             int // 导入的天数
+	Entries          int // 新增的工作记录条数
+	SkippedEntries   int // 已存在而跳过的记录条数
+	DailySummaries   int // 导入的日报数
+	WeeklySummaries  int // 导入的周报数
+	MonthlySummaries int // 导入的月报数
+}
+
+// ImportFromJSON 从 JSON 数据目录和 Markdown/HTML 总结目录一次性导入历史数据
+// 导入是幂等的：相同日期、时间戳和内容的记录不会重复写入，总结按日期覆盖
+func (s *SQLiteStorage) ImportFromJSON(dataDir, summaryDir string) (*ImportResult, error) {
+	result := &ImportResult{}
+	src := NewJSONStorage(dataDir, summaryDir)
+
+	dates, err := src.listDates()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, date := range dates {
+		dailyData, err := src.GetDailyData(date)
+		if err != nil {
+			log.Printf("Warning: skip unreadable data file for %s: %v", date.Format("2006-01-02"), err)
+			continue
+		}
+
+		imported, skipped, err := s.importDay(dailyData)
+		if err != nil {
+			return result, fmt.Errorf("import %s: %w", dailyData.Date, err)
+		}
+
+		result.Days++
+		result.Entries += imported
+		result.SkippedEntries += skipped
+	}
+
+	dailyCount, err := s.importSummaryFiles(filepath.Join(summaryDir, "daily"), "daily", "", ".md", "2006-01-02")
+	if err != nil {
+		return result, err
+	}
+	result.DailySummaries = dailyCount
+
+	weeklyCount, err := s.importSummaryFiles(filepath.Join(summaryDir, "weekly"), "weekly", "weekly-", ".html", "2006-01-02")
+	if err != nil {
+		return result, err
+	}
+	result.WeeklySummaries = weeklyCount
+
+	monthlyCount, err := s.importSummaryFiles(filepath.Join(summaryDir, "monthly"), "monthly", "monthly-", ".md", "2006-01")
+	if err != nil {
+		return result, err
+	}
+	result.MonthlySummaries = monthlyCount
+
+	return result, nil
+}
+
+// importDay 在一个事务内导入一天的数据
+func (s *SQLiteStorage) importDay(dailyData *models.DailyData) (imported, skipped int, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	generated := 0
+	if dailyData.SummaryGenerated {
+		generated = 1
+	}
+	if _, err := tx.Exec(`INSERT INTO days (date, summary_generated, revision) VALUES (?, ?, 1)
+		ON CONFLICT(date) DO UPDATE SET
+			summary_generated = MAX(summary_generated, excluded.summary_generated),
+			revision = revision + 1`,
+		dailyData.Date, generated); err != nil {
+		return 0, 0, fmt.Errorf("insert day: %w", err)
+	}
+
+	for _, entry := range dailyData.Entries {
+		timestamp := entry.Timestamp.Format(time.RFC3339Nano)
+
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(1) FROM entries WHERE uid = ? OR (date = ? AND timestamp = ? AND content = ?)`,
+			entry.ID, dailyData.Date, timestamp, entry.Content).Scan(&exists)
+		if err != nil {
+			return 0, 0, fmt.Errorf("check entry: %w", err)
+		}
+		if exists > 0 {
+			skipped++
+			continue
+		}
+
+		entry.ParseMeta()
+		if _, err := tx.Exec(`INSERT INTO entries (uid, date, timestamp, content, tags, project) VALUES (?, ?, ?, ?, ?, ?)`,
+			entry.ID, dailyData.Date, timestamp, entry.Content, strings.Join(entry.Tags, " "), entry.Project); err != nil {
+			return 0, 0, fmt.Errorf("insert entry: %w", err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit: %w", err)
+	}
+	return imported, skipped, nil
+}
+
+// importSummaryFiles 导入目录下形如 <prefix><日期><ext> 的总结文件，日期格式为 layout（月报为 YYYY-MM）
+func (s *SQLiteStorage) importSummaryFiles(dir, kind, prefix, ext, layout string) (int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read %s summary directory: %w", kind, err)
+	}
+
+	count := 0
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || filepath.Ext(name) != ext {
+			continue
+		}
+
+		dateStr := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(layout, dateStr); err != nil {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return count, fmt.Errorf("read summary file %s: %w", path, err)
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return count, fmt.Errorf("stat summary file %s: %w", path, err)
+		}
+
+		metadata := models.SummaryMetadata{
+			GeneratedAt: info.ModTime(),
+			Date:        dateStr,
+		}
+		if err := s.saveSummaryRow(kind, dateStr, string(content), metadata); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// TestSQLiteStorageEntries 测试 SQLite 存储的记录读写与未生成日期查询
+func TestSQLiteStorageEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewSQLiteStorage(filepath.Join(tmpDir, "test.db"), filepath.Join(tmpDir, "summaries"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite storage: %v", err)
+	}
+	defer store.Close()
+
+	day1 := time.Date(2026, 1, 20, 10, 0, 0, 0, time.Local)
+	day2 := time.Date(2026, 1, 21, 9, 30, 0, 0, time.Local)
+
+	entries := []models.WorkEntry{
+		{Timestamp: day1, Content: "需求评审"},
+		{Timestamp: day1.Add(time.Hour), Content: "接口开发"},
+		{Timestamp: day2, Content: "代码评审"},
+	}
+	for _, entry := range entries {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("Failed to save entry: %v", err)
+		}
+	}
+
+	data, err := store.GetDailyData(day1)
+	if err != nil {
+		t.Fatalf("Failed to get daily data: %v", err)
+	}
+	if len(data.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(data.Entries))
+	}
+	if data.Entries[1].Content != "接口开发" || !data.Entries[1].Timestamp.Equal(day1.Add(time.Hour)) {
+		t.Errorf("Unexpected second entry: %+v", data.Entries[1])
+	}
+
+	last, err := store.GetLastEntry()
+	if err != nil || last == nil {
+		t.Fatalf("Failed to get last entry: %v", err)
+	}
+	if last.Content != "代码评审" {
+		t.Errorf("Expected last entry 代码评审, got %s", last.Content)
+	}
+
+	if err := store.MarkSummaryGenerated(day1); err != nil {
+		t.Fatalf("Failed to mark summary generated: %v", err)
+	}
+
+	dates, err := store.GetUngeneratedDates(time.Date(2026, 1, 22, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Failed to get ungenerated dates: %v", err)
+	}
+	if len(dates) != 1 || dates[0].Format("2006-01-02") != "2026-01-21" {
+		t.Errorf("Expected [2026-01-21], got %v", dates)
+	}
+}
+
+// TestSQLiteImportFromJSON 测试从 JSON 数据和 Markdown 总结导入
+func TestSQLiteImportFromJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataDir := filepath.Join(tmpDir, "data")
+	summaryDir := filepath.Join(tmpDir, "summaries")
+
+	os.MkdirAll(dataDir, 0755)
+
+	jsonStore := NewJSONStorage(dataDir, summaryDir)
+	day := time.Date(2026, 1, 20, 10, 0, 0, 0, time.Local)
+	if err := jsonStore.SaveEntry(models.WorkEntry{Timestamp: day, Content: "需求评审"}); err != nil {
+		t.Fatalf("Failed to save json entry: %v", err)
+	}
+	if err := jsonStore.SaveSummary(day, "## 主要完成的任务", models.SummaryMetadata{GeneratedAt: day, EntryCount: 1}); err != nil {
+		t.Fatalf("Failed to save json summary: %v", err)
+	}
+	if err := jsonStore.MarkSummaryGenerated(day); err != nil {
+		t.Fatalf("Failed to mark json summary: %v", err)
+	}
+
+	store, err := NewSQLiteStorage(filepath.Join(tmpDir, "test.db"), summaryDir)
+	if err != nil {
+		t.Fatalf("Failed to create sqlite storage: %v", err)
+	}
+	defer store.Close()
+
+	result, err := store.ImportFromJSON(dataDir, summaryDir)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Entries != 1 || result.DailySummaries != 1 {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+
+	// 再次导入不应产生重复记录
+	result, err = store.ImportFromJSON(dataDir, summaryDir)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if result.Entries != 0 || result.SkippedEntries != 1 {
+		t.Errorf("Expected idempotent import, got %+v", result)
+	}
+
+	data, err := store.GetDailyData(day)
+	if err != nil {
+		t.Fatalf("Failed to get daily data: %v", err)
+	}
+	if len(data.Entries) != 1 || !data.SummaryGenerated {
+		t.Errorf("Unexpected imported data: %+v", data)
+	}
+
+	want, _ := jsonStore.GetSummary(day)
+	got, err := store.GetSummary(day)
+	if err != nil || got != want {
+		t.Errorf("Imported summary mismatch: err=%v", err)
+	}
+}
@@ -1,16 +1,32 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
 	"time"
 
 	"humg.top/daily_summary/internal/models"
 )
 
+// ErrEntryNotFound 指定 ID 的工作记录不存在
+var ErrEntryNotFound = errors.New("entry not found")
+
 // Storage 数据存储接口
 type Storage interface {
-	// SaveEntry 保存单条工作记录
+	// SaveEntry 保存单条工作记录（ID 为空时自动生成）
 	SaveEntry(entry models.WorkEntry) error
 
+	// GetEntry 根据 ID 获取工作记录，不存在时返回 ErrEntryNotFound
+	GetEntry(id string) (*models.WorkEntry, error)
+
+	// UpdateEntry 根据 entry.ID 更新工作记录内容
+	// 如果该日期已生成总结，会将 SummaryGenerated 重置为 false，以便重新生成
+	UpdateEntry(entry models.WorkEntry) error
+
+	// DeleteEntry 根据 ID 删除工作记录（同样会重置该日期的 SummaryGenerated）
+	DeleteEntry(id string) error
+
 	// GetDailyData 获取指定日期的所有工作记录
 	GetDailyData(date time.Time) (*models.DailyData, error)
 
@@ -26,6 +42,10 @@ type Storage interface {
 	// MarkSummaryGenerated 标记指定日期的总结已生成
 	MarkSummaryGenerated(date time.Time) error
 
+	// ResetSummaryGenerated 清除指定日期的总结已生成标记（如导入了新记录），以便重新生成
+	// 该日期没有数据或尚未生成总结时不做任何事
+	ResetSummaryGenerated(date time.Time) error
+
 	// GetDailySummariesInRange 获取日期范围内的每日总结
 	// 返回 map[string]string，key 为日期（YYYY-MM-DD），value 为总结内容
 	GetDailySummariesInRange(startDate, endDate time.Time) (map[string]string, error)
@@ -33,8 +53,55 @@ type Storage interface {
 	// SaveWeeklySummary 保存周度总结
 	SaveWeeklySummary(weekEndDate time.Time, summary string, metadata models.SummaryMetadata) error
 
+	// GetWeeklySummariesInRange 获取周末日期（周日）在范围内的周报
+	// 返回 map[string]string，key 为周末日期（YYYY-MM-DD），value 为周报内容
+	GetWeeklySummariesInRange(startDate, endDate time.Time) (map[string]string, error)
+
+	// SaveMonthlySummary 保存月度总结（month 为该月任意一天）
+	SaveMonthlySummary(month time.Time, summary string, metadata models.SummaryMetadata) error
+
+	// GetMonthlySummary 获取指定月份的月度总结
+	GetMonthlySummary(month time.Time) (string, error)
+
+	// SaveReport 保存自定义日期范围的报告到 summaries/reports/filename
+	// filename 以 .html 结尾时直接保存 HTML 文档，否则保存为带元数据头的 Markdown；同名文件会被覆盖
+	SaveReport(filename string, summary string, metadata models.SummaryMetadata) error
+
 	// GetUngeneratedDates 获取所有有数据但未生成日报的日期
 	// 返回日期列表，按时间从旧到新排序
 	// endDate: 检查的截止日期（不包含），通常为今天
 	GetUngeneratedDates(endDate time.Time) ([]time.Time, error)
+
+	// GetDateStamps 获取所有有数据的日期及其版本戳
+	// 返回 map[string]string，key 为日期（YYYY-MM-DD），value 为版本戳；
+	// 该日数据发生任何修改后版本戳都会变化，用于增量索引等场景判断数据是否需要重新读取
+	GetDateStamps() (map[string]string, error)
+}
+
+// 存储后端类型
+const (
+	BackendJSON   = "json"   // 每天一个 JSON 文件（默认）
+	BackendSQLite = "sqlite" // 单个 SQLite 数据库文件
+)
+
+// New 根据配置创建存储实例
+// storage_backend 为空或 json 时使用 JSONStorage，为 sqlite 时使用 SQLiteStorage
+func New(cfg *models.Config) (Storage, error) {
+	switch cfg.StorageBackend {
+	case "", BackendJSON:
+		return NewJSONStorage(cfg.DataDir, cfg.SummaryDir), nil
+	case BackendSQLite:
+		return NewSQLiteStorage(SQLitePath(cfg), cfg.SummaryDir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s (supported: json, sqlite)", cfg.StorageBackend)
+	}
+}
+
+// SQLitePath 返回 SQLite 数据库文件路径
+// 未配置 sqlite_path 时默认放在 data_dir 的父目录（即 run/daily_summary.db）
+func SQLitePath(cfg *models.Config) string {
+	if cfg.SQLitePath != "" {
+		return cfg.SQLitePath
+	}
+	return filepath.Join(filepath.Dir(cfg.DataDir), "daily_summary.db")
 }
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// newTestStorages 创建 JSON 与 SQLite 两种存储实例，用于验证接口行为一致
+func newTestStorages(t *testing.T) map[string]Storage {
+	tmpDir := t.TempDir()
+
+	jsonDataDir := filepath.Join(tmpDir, "json", "data")
+	os.MkdirAll(jsonDataDir, 0755)
+
+	sqliteStore, err := NewSQLiteStorage(filepath.Join(tmpDir, "sqlite", "test.db"), filepath.Join(tmpDir, "sqlite", "summaries"))
+	if err != nil {
+		t.Fatalf("Failed to create sqlite storage: %v", err)
+	}
+	t.Cleanup(func() { sqliteStore.Close() })
+
+	return map[string]Storage{
+		BackendJSON:   NewJSONStorage(jsonDataDir, filepath.Join(tmpDir, "json", "summaries")),
+		BackendSQLite: sqliteStore,
+	}
+}
+
+// TestEntryEditAndDelete 测试记录 ID、修改和删除，以及总结标记的重置
+func TestEntryEditAndDelete(t *testing.T) {
+	for name, store := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			day := time.Date(2026, 1, 20, 10, 0, 0, 0, time.Local)
+			for i, content := range []string{"需求评审", "接口开发", "代码评审"} {
+				entry := models.WorkEntry{Timestamp: day.Add(time.Duration(i) * time.Hour), Content: content}
+				if err := store.SaveEntry(entry); err != nil {
+					t.Fatalf("Failed to save entry: %v", err)
+				}
+			}
+
+			data, err := store.GetDailyData(day)
+			if err != nil {
+				t.Fatalf("Failed to get daily data: %v", err)
+			}
+			for _, entry := range data.Entries {
+				if entry.ID == "" {
+					t.Fatalf("Entry should have an ID: %+v", entry)
+				}
+			}
+
+			if err := store.MarkSummaryGenerated(day); err != nil {
+				t.Fatalf("Failed to mark summary generated: %v", err)
+			}
+
+			// 修改第二条记录
+			target := data.Entries[1]
+			target.Content = "接口开发与联调"
+			if err := store.UpdateEntry(target); err != nil {
+				t.Fatalf("Failed to update entry: %v", err)
+			}
+
+			got, err := store.GetEntry(target.ID)
+			if err != nil {
+				t.Fatalf("Failed to get entry: %v", err)
+			}
+			if got.Content != "接口开发与联调" || !got.Timestamp.Equal(target.Timestamp) {
+				t.Errorf("Unexpected updated entry: %+v", got)
+			}
+
+			data, _ = store.GetDailyData(day)
+			if data.SummaryGenerated {
+				t.Error("SummaryGenerated should be reset after update")
+			}
+
+			// 删除第一条记录
+			if err := store.DeleteEntry(data.Entries[0].ID); err != nil {
+				t.Fatalf("Failed to delete entry: %v", err)
+			}
+			data, _ = store.GetDailyData(day)
+			if len(data.Entries) != 2 || data.Entries[0].ID != target.ID {
+				t.Errorf("Unexpected entries after delete: %+v", data.Entries)
+			}
+
+			if _, err := store.GetEntry("20260120-ffffff"); !errors.Is(err, ErrEntryNotFound) {
+				t.Errorf("Expected ErrEntryNotFound, got %v", err)
+			}
+
+			// 显式清除标记（导入新记录时使用），没有数据的日期不受影响
+			store.MarkSummaryGenerated(day)
+			if err := store.ResetSummaryGenerated(day); err != nil {
+				t.Fatalf("Failed to reset summary generated: %v", err)
+			}
+			if data, _ = store.GetDailyData(day); data.SummaryGenerated || len(data.Entries) != 2 {
+				t.Errorf("Expected summary flag to be reset without touching entries: %+v", data)
+			}
+			if err := store.ResetSummaryGenerated(day.AddDate(0, 0, 1)); err != nil {
+				t.Errorf("Reset on a date without data should be a no-op: %v", err)
+			}
+		})
+	}
+}
+
+// TestEntryTagsAndProject 测试保存和修改记录时解析标签与项目
+func TestEntryTagsAndProject(t *testing.T) {
+	for name, store := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			day := time.Date(2026, 1, 20, 10, 0, 0, 0, time.Local)
+			if err := store.SaveEntry(models.WorkEntry{Timestamp: day, Content: "@Billing 对账任务超时 #bug #oncall"}); err != nil {
+				t.Fatalf("Failed to save entry: %v", err)
+			}
+
+			data, err := store.GetDailyData(day)
+			if err != nil {
+				t.Fatalf("Failed to get daily data: %v", err)
+			}
+			entry := data.Entries[0]
+			if entry.Project != "billing" || len(entry.Tags) != 2 || !entry.HasTag("#oncall") {
+				t.Fatalf("Unexpected parsed entry: %+v", entry)
+			}
+
+			entry.Content = "@search 索引重建"
+			if err := store.UpdateEntry(entry); err != nil {
+				t.Fatalf("Failed to update entry: %v", err)
+			}
+			got, err := store.GetEntry(entry.ID)
+			if err != nil {
+				t.Fatalf("Failed to get entry: %v", err)
+			}
+			if got.Project != "search" || len(got.Tags) != 0 {
+				t.Errorf("Tags and project should follow updated content: %+v", got)
+			}
+		})
+	}
+}
+
+// TestJSONStorageLegacyEntryTags 测试没有 tags/project 字段的历史数据在读取时补充解析
+func TestJSONStorageLegacyEntryTags(t *testing.T) {
+	dataDir := t.TempDir()
+	legacy := `{"date":"2026-01-20","entries":[{"timestamp":"2026-01-20T10:00:00+08:00","content":"@billing 发布 #release"}],"summary_generated":false}`
+	if err := os.WriteFile(filepath.Join(dataDir, "2026-01-20.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	store := NewJSONStorage(dataDir, t.TempDir())
+	data, err := store.GetDailyData(time.Date(2026, 1, 20, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("Failed to get daily data: %v", err)
+	}
+	entry := data.Entries[0]
+	if entry.Project != "billing" || !entry.HasTag("release") {
+		t.Errorf("Legacy entry should have parsed tags and project: %+v", entry)
+	}
+}
+
+// TestSummaryProvider 测试日报记录生成它的 AI 提供商
+func TestSummaryProvider(t *testing.T) {
+	date := time.Date(2026, 1, 19, 0, 0, 0, 0, time.Local)
+	for name, store := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			metadata := models.SummaryMetadata{GeneratedAt: time.Now(), Date: "2026-01-19", EntryCount: 3, Provider: "openai"}
+			if err := store.SaveSummary(date, "## 总结", metadata); err != nil {
+				t.Fatalf("SaveSummary failed: %v", err)
+			}
+
+			content, err := store.GetSummary(date)
+			if err != nil {
+				t.Fatalf("GetSummary failed: %v", err)
+			}
+			if !strings.Contains(content, "生成方式: openai\n") {
+				t.Errorf("Summary should record provider, got:\n%s", content)
+			}
+		})
+	}
+}
+
+// TestWeeklyAndMonthlySummaries 测试按范围读取周报和月报的保存读取
+func TestWeeklyAndMonthlySummaries(t *testing.T) {
+	for name, store := range newTestStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			metadata := models.SummaryMetadata{GeneratedAt: time.Now()}
+			for _, weekEnd := range []string{"2026-08-30", "2026-09-06", "2026-10-04", "2026-10-11"} {
+				date, _ := time.Parse("2006-01-02", weekEnd)
+				if err := store.SaveWeeklySummary(date, "<html>"+weekEnd+"</html>", metadata); err != nil {
+					t.Fatalf("SaveWeeklySummary failed: %v", err)
+				}
+			}
+
+			weekly, err := store.GetWeeklySummariesInRange(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("GetWeeklySummariesInRange failed: %v", err)
+			}
+			if len(weekly) != 2 || !strings.Contains(weekly["2026-09-06"], "2026-09-06") || weekly["2026-10-04"] == "" {
+				t.Errorf("Unexpected weekly summaries: %v", weekly)
+			}
+
+			month := time.Date(2026, 9, 15, 0, 0, 0, 0, time.Local)
+			if _, err := store.GetMonthlySummary(month); err == nil {
+				t.Error("Expected error for missing monthly summary")
+			}
+
+			metadata = models.SummaryMetadata{GeneratedAt: time.Now(), Date: "2026-09", EntryCount: 21, Provider: "codex"}
+			if err := store.SaveMonthlySummary(month, "## 本月完成情况", metadata); err != nil {
+				t.Fatalf("SaveMonthlySummary failed: %v", err)
+			}
+
+			content, err := store.GetMonthlySummary(time.Date(2026, 9, 1, 0, 0, 0, 0, time.Local))
+			if err != nil {
+				t.Fatalf("GetMonthlySummary failed: %v", err)
+			}
+			for _, want := range []string{"# 月度总结 - 2026-09", "日报篇数: 21", "生成方式: codex", "## 本月完成情况"} {
+				if !strings.Contains(content, want) {
+					t.Errorf("Monthly summary should contain %q, got:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Provider 带名称的 AI 客户端
+type Provider struct {
+	Name   string
+	Client AIClient
+}
+
+// RetryPolicy 临时性失败的重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 每个提供商的最大重试次数（不含首次调用）
+	Backoff    time.Duration // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoff time.Duration // 单次等待的上限，0 表示不限制
+}
+
+// Chain 按顺序尝试多个提供商：临时性失败（限流等）按退避策略重试，
+// 其余失败或重试耗尽后切换到下一个提供商
+type Chain struct {
+	providers []Provider
+	retry     RetryPolicy
+	sleep     func(ctx context.Context, d time.Duration) error // 可在测试中替换
+}
+
+// NewChain 创建提供商链
+func NewChain(providers []Provider, retry RetryPolicy) (*Chain, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no AI provider configured")
+	}
+	return &Chain{
+		providers: providers,
+		retry:     retry,
+		sleep:     sleepContext,
+	}, nil
+}
+
+// Names 返回提供商名称（按尝试顺序）
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// GenerateSummary 生成总结（实现 AIClient）
+func (c *Chain) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	summary, _, err := c.Generate(ctx, prompt, nil)
+	return summary, err
+}
+
+// StreamSummary 以流式方式生成总结（实现 StreamingClient）
+func (c *Chain) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	summary, _, err := c.Generate(ctx, prompt, onChunk)
+	return summary, err
+}
+
+// Generate 依次尝试各提供商，返回总结和实际生成总结的提供商名称
+// onChunk 不为 nil 时以流式方式调用提供商（见 StreamSummary），某次尝试输出了部分内容后失败时，
+// 重试或切换提供商前会输出一行提示，避免与下一次的输出混在一起
+// 所有提供商都失败时返回汇总错误；ctx 取消时立即返回，不再切换提供商
+func (c *Chain) Generate(ctx context.Context, prompt string, onChunk func(string)) (string, string, error) {
+	var errs []error
+	for i, p := range c.providers {
+		summary, err := c.generateWithRetry(ctx, p, prompt, onChunk)
+		if err == nil {
+			return summary, p.Name, nil
+		}
+		if ctx.Err() != nil {
+			return "", "", err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		if i < len(c.providers)-1 {
+			log.Printf("AI provider %s failed, falling back to %s: %v", p.Name, c.providers[i+1].Name, err)
+		}
+	}
+
+	if len(errs) == 1 {
+		return "", "", errs[0]
+	}
+	return "", "", fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
+
+// generateWithRetry 调用单个提供商，临时性失败时按退避策略重试
+func (c *Chain) generateWithRetry(ctx context.Context, p Provider, prompt string, onChunk func(string)) (string, error) {
+	backoff := c.retry.Backoff
+	for attempt := 0; ; attempt++ {
+		var streamed bool
+		var attemptChunk func(string)
+		if onChunk != nil {
+			attemptChunk = func(s string) {
+				streamed = true
+				onChunk(s)
+			}
+		}
+
+		summary, err := StreamSummary(ctx, p.Client, prompt, attemptChunk)
+		if err != nil && streamed {
+			onChunk(fmt.Sprintf("\n\n[%s 输出中断: %v]\n\n", p.Name, err))
+		}
+		if err == nil && strings.TrimSpace(summary) == "" {
+			err = errors.New("empty response")
+		}
+		if err == nil {
+			return summary, nil
+		}
+		if !IsTransient(err) || attempt >= c.retry.MaxRetries || ctx.Err() != nil {
+			return "", err
+		}
+
+		log.Printf("AI provider %s failed (attempt %d/%d), retrying in %s: %v",
+			p.Name, attempt+1, c.retry.MaxRetries+1, backoff, err)
+		if err := c.sleep(ctx, backoff); err != nil {
+			return "", err
+		}
+		backoff *= 2
+		if c.retry.MaxBackoff > 0 && backoff > c.retry.MaxBackoff {
+			backoff = c.retry.MaxBackoff
+		}
+	}
+}
+
+// sleepContext 等待指定时间，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
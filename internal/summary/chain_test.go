@@ -0,0 +1,173 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedClient 按顺序返回预设结果的客户端
+type scriptedClient struct {
+	results []error // 每次调用的错误，nil 表示成功
+	calls   int
+}
+
+func (c *scriptedClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.calls > len(c.results) {
+		return "", errors.New("unexpected call")
+	}
+	if err := c.results[c.calls-1]; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("summary from call %d", c.calls), nil
+}
+
+// newTestChain 创建不实际等待的提供商链，记录每次退避时间
+func newTestChain(t *testing.T, providers []Provider, retry RetryPolicy) (*Chain, *[]time.Duration) {
+	chain, err := NewChain(providers, retry)
+	if err != nil {
+		t.Fatalf("NewChain failed: %v", err)
+	}
+	var waits []time.Duration
+	chain.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return chain, &waits
+}
+
+// TestChainRetriesTransientErrors 测试临时性失败按指数退避重试
+func TestChainRetriesTransientErrors(t *testing.T) {
+	rateLimited := &TransientError{Err: errors.New("rate limit")}
+	codex := &scriptedClient{results: []error{rateLimited, rateLimited, nil}}
+
+	chain, waits := newTestChain(t, []Provider{{Name: "codex", Client: codex}},
+		RetryPolicy{MaxRetries: 2, Backoff: time.Second, MaxBackoff: time.Minute})
+
+	summary, provider, err := chain.Generate(context.Background(), "prompt", nil)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if provider != "codex" || summary != "summary from call 3" {
+		t.Errorf("Unexpected result: %q from %q", summary, provider)
+	}
+	if fmt.Sprint(*waits) != "[1s 2s]" {
+		t.Errorf("Expected exponential backoff [1s 2s], got %v", *waits)
+	}
+}
+
+// TestChainFallsBack 测试失败后切换到下一个提供商
+func TestChainFallsBack(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		firstCalls int // 第一个提供商被调用的次数
+	}{
+		{"retries exhausted", &TransientError{Err: errors.New("rate limit")}, 2},
+		{"permanent failure", errors.New("invalid api key"), 1},
+		{"unavailable", fmt.Errorf("%w: codex not found", ErrUnavailable), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codex := &scriptedClient{results: []error{tt.err, tt.err}}
+			openai := &scriptedClient{results: []error{nil}}
+
+			chain, _ := newTestChain(t, []Provider{{Name: "codex", Client: codex}, {Name: "openai", Client: openai}},
+				RetryPolicy{MaxRetries: 1, Backoff: time.Second})
+
+			_, provider, err := chain.Generate(context.Background(), "prompt", nil)
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			if provider != "openai" {
+				t.Errorf("Expected summary from openai, got %q", provider)
+			}
+			if codex.calls != tt.firstCalls {
+				t.Errorf("Expected %d calls to codex, got %d", tt.firstCalls, codex.calls)
+			}
+		})
+	}
+}
+
+// TestChainAllFail 测试所有提供商失败时返回汇总错误，空结果视为失败
+func TestChainAllFail(t *testing.T) {
+	empty := AIClient(aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		return "  \n", nil
+	}))
+	broken := &scriptedClient{results: []error{errors.New("boom")}}
+
+	chain, _ := newTestChain(t, []Provider{{Name: "codex", Client: empty}, {Name: "openai", Client: broken}}, RetryPolicy{})
+
+	_, _, err := chain.Generate(context.Background(), "prompt", nil)
+	if err == nil {
+		t.Fatal("Expected error when all providers fail")
+	}
+	for _, want := range []string{"all AI providers failed", "codex: empty response", "openai: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error should contain %q, got %v", want, err)
+		}
+	}
+}
+
+// TestChainStopsOnCancel 测试 ctx 取消后不再重试或切换提供商
+func TestChainStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	codex := AIClient(aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		cancel()
+		return "", fmt.Errorf("execute codex: %w", ctx.Err())
+	}))
+	openai := &scriptedClient{results: []error{nil}}
+
+	chain, _ := newTestChain(t, []Provider{{Name: "codex", Client: codex}, {Name: "openai", Client: openai}},
+		RetryPolicy{MaxRetries: 3, Backoff: time.Second})
+
+	if _, _, err := chain.Generate(ctx, "prompt", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if openai.calls != 0 {
+		t.Error("Should not fall back after cancellation")
+	}
+}
+
+// TestChainStream 测试流式输出：不支持流式的客户端一次性输出完整结果，部分输出后失败时输出中断提示
+func TestChainStream(t *testing.T) {
+	partial := AIClient(&streamingFunc{stream: func(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+		onChunk("## 今")
+		return "", errors.New("connection reset")
+	}})
+	buffered := &scriptedClient{results: []error{nil}}
+
+	chain, _ := newTestChain(t, []Provider{{Name: "ollama", Client: partial}, {Name: "codex", Client: buffered}}, RetryPolicy{})
+
+	var output strings.Builder
+	summary, provider, err := chain.Generate(context.Background(), "prompt", func(s string) { output.WriteString(s) })
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if provider != "codex" || summary != "summary from call 1" {
+		t.Errorf("Unexpected result: %q from %q", summary, provider)
+	}
+
+	want := "## 今\n\n[ollama 输出中断: connection reset]\n\nsummary from call 1"
+	if output.String() != want {
+		t.Errorf("Unexpected streamed output:\n%q\nwant:\n%q", output.String(), want)
+	}
+}
+
+// streamingFunc 将函数适配为 StreamingClient
+type streamingFunc struct {
+	stream func(ctx context.Context, prompt string, onChunk func(string)) (string, error)
+}
+
+func (f *streamingFunc) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return f.stream(ctx, prompt, func(string) {})
+}
+
+func (f *streamingFunc) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return f.stream(ctx, prompt, onChunk)
+}
@@ -2,16 +2,33 @@ package summary
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+
+	"humg.top/daily_summary/internal/models"
 )
 
+func init() {
+	RegisterProvider("claude", func(cfg *models.Config, name string) (AIClient, error) {
+		client, err := NewClaudeClient(cfg.ClaudeCodePath)
+		if err != nil {
+			return nil, err
+		}
+		if client.promptMode, err = promptModeFor(cfg, name, client.promptMode); err != nil {
+			return nil, err
+		}
+		return client, nil
+	})
+}
+
 // ClaudeClient Claude Code CLI 客户端
 type ClaudeClient struct {
 	claudeCodePath string
-	workDir        string // 临时工作目录
+	workDir        string     // 临时工作目录
+	promptMode     PromptMode // 提示词传递方式（默认 stdin）
 }
 
 // NewClaudeClient 创建 Claude 客户端
@@ -26,62 +43,66 @@ func NewClaudeClient(claudeCodePath string) (*ClaudeClient, error) {
 	return &ClaudeClient{
 		claudeCodePath: claudeCodePath,
 		workDir:        workDir,
+		promptMode:     PromptModeStdin,
 	}, nil
 }
 
+// SetPromptMode 设置提示词传递方式
+func (c *ClaudeClient) SetPromptMode(mode PromptMode) {
+	c.promptMode = mode
+}
+
 // GenerateSummary 调用 Claude Code 生成总结
-func (c *ClaudeClient) GenerateSummary(prompt string) (string, error) {
-	// 将提示词写入临时文件
-	promptFile := filepath.Join(c.workDir, "prompt.txt")
-	if err := os.WriteFile(promptFile, []byte(prompt), 0644); err != nil {
-		return "", fmt.Errorf("write prompt file: %w", err)
-	}
-	defer os.Remove(promptFile)
+func (c *ClaudeClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.run(ctx, prompt, nil)
+}
+
+// StreamSummary 生成总结，标准输出实时转交给 onChunk（实现 StreamingClient）
+func (c *ClaudeClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return c.run(ctx, prompt, onChunk)
+}
 
+// run 执行 CLI，onChunk 不为 nil 时同时转交标准输出
+func (c *ClaudeClient) run(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
 	// 检查 claude-code 是否存在
-	if _, err := exec.LookPath(c.claudeCodePath); err != nil {
-		// 如果 claude-code 不存在，使用简单模板生成总结
-		return c.generateFallbackSummary(prompt), nil
+	if err := lookCommand(c.claudeCodePath); err != nil {
+		return "", err
 	}
 
-	// 调用 claude-code CLI
-	cmd := exec.Command(c.claudeCodePath, "--prompt", prompt)
+	delivery, err := newPromptDelivery(c.promptMode, prompt, c.workDir)
+	if err != nil {
+		return "", err
+	}
+	defer delivery.cleanup()
+
+	// 调用 claude-code CLI：arg 模式沿用 --prompt {prompt}，
+	// 其余模式使用非交互的 -p（只输出最终回答），提示词来自标准输入或文件
+	var args []string
+	if c.promptMode == PromptModeArg {
+		args = []string{"--prompt", prompt}
+	} else {
+		args = []string{"-p"}
+		if arg := delivery.arg(prompt); arg != "" {
+			args = append(args, arg)
+		}
+	}
+	cmd := newCommand(ctx, c.claudeCodePath, args...)
 	cmd.Dir = c.workDir
+	delivery.attach(cmd, prompt)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
+	if onChunk != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, chunkWriter(onChunk))
+	}
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("execute claude-code: %w, stderr: %s", err, stderr.String())
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("execute claude-code: %w", ctx.Err())
+		}
+		return "", commandError("claude-code", err, stderr.String())
 	}
 
 	return stdout.String(), nil
 }
-
-// generateFallbackSummary 生成回退总结（当 claude-code 不可用时）
-func (c *ClaudeClient) generateFallbackSummary(prompt string) string {
-	_ = prompt // 回退方案使用简单模板，不需要解析 prompt
-	return `## 主要完成的任务
-
-（由于 Claude Code CLI 不可用，这是一个自动生成的简单总结）
-
-根据今天的工作记录，完成了多项任务。
-
-## 关键进展
-
-请查看原始工作记录以了解详细信息。
-
-## 遇到的问题
-
-暂无记录
-
-## 明日计划
-
-根据今天的进展继续推进
-
----
-
-注意：这是一个简化的总结。要获得更详细的 AI 生成总结，请安装 Claude Code CLI。
-`
-}
@@ -1,6 +1,99 @@
 package summary
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
 // AIClient AI 客户端接口
 type AIClient interface {
-	GenerateSummary(prompt string) (string, error)
+	// GenerateSummary 生成总结；ctx 取消或超时时应尽快终止请求（CLI 客户端会终止子进程）
+	GenerateSummary(ctx context.Context, prompt string) (string, error)
+}
+
+// StreamingClient 支持流式输出的 AI 客户端
+type StreamingClient interface {
+	AIClient
+	// StreamSummary 生成总结，每收到一段内容时调用 onChunk，返回完整结果
+	StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error)
+}
+
+// StreamSummary 以流式方式调用客户端；客户端不支持流式输出时等待完整结果后一次性回调
+// onChunk 为 nil 时等同于 GenerateSummary
+func StreamSummary(ctx context.Context, client AIClient, prompt string, onChunk func(string)) (string, error) {
+	if onChunk == nil {
+		return client.GenerateSummary(ctx, prompt)
+	}
+	if streaming, ok := client.(StreamingClient); ok {
+		return streaming.StreamSummary(ctx, prompt, onChunk)
+	}
+
+	summary, err := client.GenerateSummary(ctx, prompt)
+	if err == nil {
+		onChunk(summary)
+	}
+	return summary, err
+}
+
+// timeoutClient 为每次生成设置超时的客户端包装
+type timeoutClient struct {
+	client  AIClient
+	timeout time.Duration
+}
+
+// WithTimeout 为客户端的每次调用设置超时，timeout <= 0 时原样返回
+func WithTimeout(client AIClient, timeout time.Duration) AIClient {
+	if timeout <= 0 {
+		return client
+	}
+	return &timeoutClient{client: client, timeout: timeout}
+}
+
+// GenerateSummary 在超时时间内调用被包装的客户端
+func (c *timeoutClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.StreamSummary(ctx, prompt, nil)
+}
+
+// StreamSummary 在超时时间内以流式方式调用被包装的客户端
+func (c *timeoutClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	summary, err := StreamSummary(callCtx, c.client, prompt, onChunk)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("AI generation timed out after %s: %w", c.timeout, context.DeadlineExceeded)
+	}
+	return summary, err
+}
+
+// chunkWriter 将写入的内容转交给 onChunk，用于把 CLI 的标准输出同时作为流式输出
+type chunkWriter func(string)
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	w(string(p))
+	return len(p), nil
+}
+
+// ErrUnavailable 提供商不可用（如 CLI 未安装），不会重试，直接切换到下一个提供商
+var ErrUnavailable = errors.New("AI provider unavailable")
+
+// TransientError 临时性失败（如限流、服务端过载），可以稍后重试
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient 判断错误是否为可重试的临时性失败
+func IsTransient(err error) bool {
+	var transient *TransientError
+	return errors.As(err, &transient)
 }
@@ -0,0 +1,67 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithTimeoutKillsProcessGroup 测试超时后终止整个 CLI 进程组（包括派生的子进程）
+func TestWithTimeoutKillsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not supported on windows")
+	}
+
+	// 模拟挂起的 codex：派生一个继承 stdout 的子进程后等待
+	script := filepath.Join(t.TempDir(), "codex")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 30 &\nwait\n"), 0755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	client, err := NewCodexClient(script, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCodexClient failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = WithTimeout(client, 200*time.Millisecond).GenerateSummary(context.Background(), "prompt")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("error should mention the timeout, got %v", err)
+	}
+	// 子进程仍持有 stdout 时 Wait 会阻塞到 commandWaitDelay，提前返回说明整个进程组已被终止
+	if elapsed >= commandWaitDelay {
+		t.Errorf("GenerateSummary returned after %s, child process was not killed", elapsed)
+	}
+}
+
+// TestWithTimeoutParentCanceled 测试调用方取消时返回 context.Canceled 而不是超时错误
+func TestWithTimeoutParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := WithTimeout(aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}), time.Minute)
+
+	if _, err := client.GenerateSummary(ctx, "prompt"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+// aiClientFunc 将函数适配为 AIClient
+type aiClientFunc func(ctx context.Context, prompt string) (string, error)
+
+func (f aiClientFunc) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return f(ctx, prompt)
+}
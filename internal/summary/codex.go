@@ -2,17 +2,39 @@ package summary
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
 )
 
+func init() {
+	RegisterProvider("codex", func(cfg *models.Config, name string) (AIClient, error) {
+		codexPath := cfg.CodexPath
+		if codexPath == "" {
+			codexPath = "codex"
+		}
+		client, err := NewCodexClient(codexPath, cfg.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+		if client.promptMode, err = promptModeFor(cfg, name, client.promptMode); err != nil {
+			return nil, err
+		}
+		return client, nil
+	})
+}
+
 // CodexClient Codex CLI 客户端
 type CodexClient struct {
-	codexPath string
-	workDir   string
+	codexPath  string
+	workDir    string
+	promptMode PromptMode // 提示词传递方式（默认 stdin）
 }
 
 // NewCodexClient 创建 Codex 客户端
@@ -25,81 +47,103 @@ func NewCodexClient(codexPath, projectDir string) (*CodexClient, error) {
 	}
 
 	return &CodexClient{
-		codexPath: codexPath,
-		workDir:   projectDir, // Use the project directory provided
+		codexPath:  codexPath,
+		workDir:    projectDir, // Use the project directory provided
+		promptMode: PromptModeStdin,
 	}, nil
 }
 
+// SetPromptMode 设置提示词传递方式
+func (c *CodexClient) SetPromptMode(mode PromptMode) {
+	c.promptMode = mode
+}
+
 // GenerateSummary 调用 Codex 生成总结
-func (c *CodexClient) GenerateSummary(prompt string) (string, error) {
+func (c *CodexClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.run(ctx, prompt, nil)
+}
+
+// StreamSummary 生成总结，标准输出（进度和回答过程）实时转交给 onChunk（实现 StreamingClient）
+// 返回值仍为 --output-last-message 中的最终回答，不包含进度输出
+func (c *CodexClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return c.run(ctx, prompt, onChunk)
+}
+
+// run 执行 codex exec，onChunk 不为 nil 时同时转交标准输出
+func (c *CodexClient) run(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
 	// 检查 codex 是否存在
 	codexPath := c.codexPath
 	if codexPath == "" {
 		codexPath = "codex"
 	}
 
-	if _, err := exec.LookPath(codexPath); err != nil {
-		// 如果 codex 不存在，使用回退总结
-		log.Println("Warning: codex not found, using fallback summary")
-		return c.generateFallbackSummary(), nil
+	if err := lookCommand(codexPath); err != nil {
+		return "", err
 	}
 
 	// 记录调用信息
-	log.Printf("调用 Codex: %s exec", codexPath)
+	log.Printf("调用 Codex: %s exec (prompt: %s)", codexPath, c.promptMode)
 	log.Printf("工作目录: %s", c.workDir)
 	log.Printf("Prompt 长度: %d 字符", len(prompt))
 
 	// 同时在控制台输出进度（方便 CLI 用户看到）
 	fmt.Printf("调用 Codex 生成总结...\n")
 
-	// 调用 codex exec "{prompt}"
-	cmd := exec.Command(codexPath, "exec", prompt)
+	delivery, err := newPromptDelivery(c.promptMode, prompt, c.workDir)
+	if err != nil {
+		return "", err
+	}
+	defer delivery.cleanup()
+
+	// 最终回答写入单独的文件，stdout 中的进度和工具调用输出不作为总结内容
+	lastMessageFile, err := os.CreateTemp("", "daily_summary_codex-*.md")
+	if err != nil {
+		return "", fmt.Errorf("create output file: %w", err)
+	}
+	lastMessageFile.Close()
+	defer os.Remove(lastMessageFile.Name())
+
+	// 调用 codex exec --output-last-message <file> {prompt}（stdin 模式下 prompt 为 "-"）
+	args := []string{"exec", "--output-last-message", lastMessageFile.Name()}
+	if arg := delivery.arg(prompt); arg != "" {
+		args = append(args, arg)
+	} else {
+		args = append(args, "-")
+	}
+	cmd := newCommand(ctx, codexPath, args...)
 	cmd.Dir = c.workDir // 设置命令执行目录为项目目录
+	delivery.attach(cmd, prompt)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
+	if onChunk != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, chunkWriter(onChunk))
+	}
 	cmd.Stderr = &stderr
 
 	log.Println("等待 Codex 响应...")
 	fmt.Println("正在等待 Codex 响应...")
 
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			log.Printf("Codex 已终止: %v", ctx.Err())
+			return "", fmt.Errorf("execute codex: %w", ctx.Err())
+		}
 		log.Printf("Codex 执行失败: %v", err)
 		if stderr.Len() > 0 {
 			log.Printf("错误输出: %s", stderr.String())
 		}
-		return "", fmt.Errorf("execute codex: %w, stderr: %s", err, stderr.String())
+		return "", commandError("codex", err, stderr.String())
 	}
 
 	response := stdout.String()
+	if lastMessage, err := os.ReadFile(lastMessageFile.Name()); err == nil && strings.TrimSpace(string(lastMessage)) != "" {
+		response = string(lastMessage)
+	} else {
+		log.Printf("Warning: codex did not write the last message, using stdout")
+	}
 	log.Printf("✓ Codex 响应成功，长度: %d 字符", len(response))
 	fmt.Printf("✓ Codex 响应成功 (长度: %d 字符)\n", len(response))
 
 	return response, nil
 }
-
-// generateFallbackSummary 生成回退总结（当 codex 不可用时）
-func (c *CodexClient) generateFallbackSummary() string {
-	return `## 主要完成的任务
-
-（由于 Codex CLI 不可用，这是一个自动生成的简单总结）
-
-根据今天的工作记录，完成了多项任务。
-
-## 关键进展
-
-请查看原始工作记录以了解详细信息。
-
-## 遇到的问题
-
-暂无记录
-
-## 明日计划
-
-根据今天的进展继续推进
-
----
-
-注意：这是一个简化的总结。要获得更详细的 AI 生成总结，请安装 Codex CLI。
-`
-}
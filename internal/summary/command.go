@@ -0,0 +1,54 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandWaitDelay ctx 取消后等待子进程输出管道关闭的最长时间，
+// 避免孙进程继承管道导致 Wait 一直阻塞
+const commandWaitDelay = 5 * time.Second
+
+// transientPatterns CLI 错误输出中表示限流或服务端过载的关键字（小写），出现时认为可以重试
+var transientPatterns = []string{
+	"rate limit",
+	"rate_limit",
+	"ratelimit",
+	"too many requests",
+	"overloaded",
+	"service unavailable",
+	"temporarily unavailable",
+	"try again later",
+}
+
+// newCommand 创建绑定 ctx 的 CLI 命令
+// 子进程在独立的进程组中运行，ctx 超时或取消时整个进程组被终止（包括 CLI 派生的子进程）
+func newCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = commandWaitDelay
+	return cmd
+}
+
+// lookCommand 检查 CLI 是否可用，不可用时返回 ErrUnavailable
+func lookCommand(path string) error {
+	if _, err := exec.LookPath(path); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// commandError 包装 CLI 执行失败的错误，错误输出包含限流等关键字时标记为可重试
+func commandError(name string, err error, stderr string) error {
+	wrapped := fmt.Errorf("execute %s: %w, stderr: %s", name, err, stderr)
+	lower := strings.ToLower(stderr)
+	for _, pattern := range transientPatterns {
+		if strings.Contains(lower, pattern) {
+			return &TransientError{Err: wrapped}
+		}
+	}
+	return wrapped
+}
@@ -0,0 +1,193 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// 参数模板中的占位符
+const (
+	placeholderPrompt     = "{{prompt}}"
+	placeholderPromptFile = "{{prompt_file}}"
+)
+
+// CommandConfig 通用命令行提供商配置
+type CommandConfig struct {
+	Command     string   // 可执行文件
+	Args        []string // 参数模板，支持 {{prompt}} 和 {{prompt_file}}
+	Stdin       bool     // 是否通过标准输入传递提示词
+	WorkDir     string   // 执行目录
+	Env         []string // 额外的环境变量（KEY=VALUE）
+	OutputRegex string   // 从标准输出提取总结的正则，为空时使用全部输出
+}
+
+// CommandClient 通用命令行 AI 客户端，调用方式完全由配置决定
+type CommandClient struct {
+	name        string
+	config      CommandConfig
+	outputRegex *regexp.Regexp
+	usesFile    bool // 参数中是否引用了 {{prompt_file}}
+}
+
+// NewCommandClient 创建通用命令行客户端，name 用于日志和错误信息
+func NewCommandClient(name string, config CommandConfig) (*CommandClient, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("command provider %s: command is required", name)
+	}
+
+	client := &CommandClient{name: name, config: config}
+	usesPrompt := false
+	for _, arg := range config.Args {
+		usesPrompt = usesPrompt || strings.Contains(arg, placeholderPrompt)
+		client.usesFile = client.usesFile || strings.Contains(arg, placeholderPromptFile)
+	}
+	if !usesPrompt && !client.usesFile && !config.Stdin {
+		return nil, fmt.Errorf("command provider %s: args must contain %s or %s, or stdin must be enabled",
+			name, placeholderPrompt, placeholderPromptFile)
+	}
+
+	if config.OutputRegex != "" {
+		re, err := regexp.Compile(config.OutputRegex)
+		if err != nil {
+			return nil, fmt.Errorf("command provider %s: invalid output_regex: %w", name, err)
+		}
+		client.outputRegex = re
+	}
+
+	return client, nil
+}
+
+// newCommandProvider 根据 command_providers 中的配置创建客户端
+func newCommandProvider(cfg *models.Config, name string, provider models.CommandProviderConfig) (AIClient, error) {
+	workDir := provider.WorkDir
+	if workDir == "" {
+		workDir = cfg.WorkDir
+	} else if !filepath.IsAbs(workDir) && cfg.WorkDir != "" {
+		workDir = filepath.Join(cfg.WorkDir, workDir)
+	}
+
+	// 按键排序，保证环境变量顺序稳定
+	keys := make([]string, 0, len(provider.Env))
+	for key := range provider.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+os.ExpandEnv(provider.Env[key]))
+	}
+
+	return NewCommandClient(name, CommandConfig{
+		Command:     provider.Command,
+		Args:        provider.Args,
+		Stdin:       provider.Stdin,
+		WorkDir:     workDir,
+		Env:         env,
+		OutputRegex: provider.OutputRegex,
+	})
+}
+
+// GenerateSummary 执行配置的命令生成总结
+func (c *CommandClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.run(ctx, prompt, nil)
+}
+
+// StreamSummary 生成总结，标准输出实时转交给 onChunk（实现 StreamingClient）
+// 配置了 output_regex 时输出需要提取后才是总结，等待完整结果后一次性回调
+func (c *CommandClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	if c.outputRegex == nil {
+		return c.run(ctx, prompt, onChunk)
+	}
+	summary, err := c.run(ctx, prompt, nil)
+	if err == nil {
+		onChunk(summary)
+	}
+	return summary, err
+}
+
+// run 执行 CLI，onChunk 不为 nil 时同时转交标准输出
+func (c *CommandClient) run(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	if err := lookCommand(c.config.Command); err != nil {
+		return "", err
+	}
+
+	var promptFile string
+	if c.usesFile {
+		delivery, err := newPromptDelivery(PromptModeFile, prompt, "")
+		if err != nil {
+			return "", err
+		}
+		defer delivery.cleanup()
+		promptFile = delivery.path
+	}
+
+	replacer := strings.NewReplacer(placeholderPrompt, prompt, placeholderPromptFile, promptFile)
+	args := make([]string, len(c.config.Args))
+	for i, arg := range c.config.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	log.Printf("调用 %s: %s", c.name, c.config.Command)
+	log.Printf("Prompt 长度: %d 字符", len(prompt))
+	fmt.Printf("调用 %s 生成总结...\n", c.name)
+
+	cmd := newCommand(ctx, c.config.Command, args...)
+	cmd.Dir = c.config.WorkDir
+	if len(c.config.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.config.Env...)
+	}
+	if c.config.Stdin {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	if onChunk != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, chunkWriter(onChunk))
+	}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("execute %s: %w", c.name, ctx.Err())
+		}
+		log.Printf("%s 执行失败: %v", c.name, err)
+		return "", commandError(c.name, err, stderr.String())
+	}
+
+	response, err := c.extract(stdout.String())
+	if err != nil {
+		return "", err
+	}
+	log.Printf("✓ %s 响应成功，长度: %d 字符", c.name, len(response))
+	fmt.Printf("✓ %s 响应成功 (长度: %d 字符)\n", c.name, len(response))
+
+	return response, nil
+}
+
+// extract 按 output_regex 从输出中提取总结（有捕获组时取第一个捕获组）
+func (c *CommandClient) extract(output string) (string, error) {
+	if c.outputRegex == nil {
+		return output, nil
+	}
+
+	match := c.outputRegex.FindStringSubmatch(output)
+	if match == nil {
+		return "", errors.New("output did not match output_regex")
+	}
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1]), nil
+	}
+	return strings.TrimSpace(match[0]), nil
+}
@@ -0,0 +1,84 @@
+package summary
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// TestCommandProvider 测试通过配置定义的命令行提供商
+func TestCommandProvider(t *testing.T) {
+	// 模拟 CLI：输出进度噪音，并把提示词（来自文件或标准输入）和环境变量包在 <answer> 中
+	script := writeScript(t, `echo "loading model..."
+if [ "$1" = "--input" ]; then prompt=$(cat "$2"); else prompt=$(cat); fi
+echo "<answer>$prompt ($LLM_MODEL)</answer>"
+echo "done"
+`)
+
+	tests := []struct {
+		name   string
+		config models.CommandProviderConfig
+	}{
+		{"prompt file", models.CommandProviderConfig{Args: []string{"--input", "{{prompt_file}}"}}},
+		{"stdin", models.CommandProviderConfig{Stdin: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Command = script
+			tt.config.Env = map[string]string{"LLM_MODEL": "qwen"}
+			tt.config.OutputRegex = `(?s)<answer>(.*)</answer>`
+			cfg := &models.Config{
+				WorkDir:          t.TempDir(),
+				CommandProviders: map[string]models.CommandProviderConfig{"local-llm": tt.config},
+			}
+
+			client, err := NewProvider(cfg, "local-llm")
+			if err != nil {
+				t.Fatalf("NewProvider failed: %v", err)
+			}
+			result, err := client.GenerateSummary(context.Background(), "总结今天的工作")
+			if err != nil {
+				t.Fatalf("GenerateSummary failed: %v", err)
+			}
+			if result != "总结今天的工作 (qwen)" {
+				t.Errorf("Unexpected result: %q", result)
+			}
+		})
+	}
+}
+
+// TestCommandProviderErrors 测试命令行提供商的配置校验和输出提取失败
+func TestCommandProviderErrors(t *testing.T) {
+	if _, err := NewCommandClient("llm", CommandConfig{Command: "llm", Args: []string{"--quiet"}}); err == nil {
+		t.Error("Expected error when the prompt is not passed to the command")
+	}
+	if _, err := NewCommandClient("llm", CommandConfig{Command: "llm", Stdin: true, OutputRegex: "("}); err == nil {
+		t.Error("Expected error for invalid output_regex")
+	}
+
+	script := writeScript(t, "echo 'no answer here'\n")
+	client, err := NewCommandClient("llm", CommandConfig{Command: script, Stdin: true, OutputRegex: "<answer>(.*)</answer>"})
+	if err != nil {
+		t.Fatalf("NewCommandClient failed: %v", err)
+	}
+	if _, err := client.GenerateSummary(context.Background(), "prompt"); err == nil || !strings.Contains(err.Error(), "output_regex") {
+		t.Errorf("Expected output_regex mismatch error, got %v", err)
+	}
+}
+
+// TestNewProviderUnknown 测试未知提供商的错误信息列出可用提供商
+func TestNewProviderUnknown(t *testing.T) {
+	cfg := &models.Config{CommandProviders: map[string]models.CommandProviderConfig{"local-llm": {}}}
+	_, err := NewProvider(cfg, "gemini")
+	if err == nil {
+		t.Fatal("Expected error for unknown provider")
+	}
+	for _, name := range []string{"codex", "claude", "coco", "openai", "local-llm"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Error should list %s, got %v", name, err)
+		}
+	}
+}
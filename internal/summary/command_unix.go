@@ -0,0 +1,16 @@
+//go:build !windows
+
+package summary
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让子进程成为新进程组的组长，取消时向整个进程组发送 SIGKILL
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package summary
+
+import "os/exec"
+
+// setProcessGroup Windows 上没有进程组，取消时只终止 CLI 进程本身（exec.CommandContext 默认行为）
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,143 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/timesheet"
+)
+
+// GenerateFilteredDailySummary 只基于满足过滤条件的记录生成当日总结
+// 结果直接返回，不保存也不修改 SummaryGenerated，避免覆盖当日的完整总结
+func (g *Generator) GenerateFilteredDailySummary(ctx context.Context, date time.Time, filter models.EntryFilter) (string, error) {
+	log.Printf("Generating filtered summary for %s (%s)", date.Format("2006-01-02"), filter.String())
+
+	preview, err := g.BuildDailyPrompt(date, filter)
+	if err != nil {
+		return "", err
+	}
+
+	summary, _, err := g.generate(withPromptData(ctx, preview.Data), preview.Prompt, g.dailyOutputSpec())
+	if err != nil {
+		return "", fmt.Errorf("generate summary: %w", err)
+	}
+	return appendTimeTable(summary, &preview.Data.TimeTable), nil
+}
+
+// GenerateFilteredWeeklySummary 只基于满足过滤条件的记录生成周报
+// 每日总结覆盖了当天全部工作，无法按标签拆分，因此直接使用每天过滤后的原始记录
+// 结果直接返回，不保存
+func (g *Generator) GenerateFilteredWeeklySummary(ctx context.Context, weekEndDate time.Time, filter models.EntryFilter) (string, error) {
+	weekStartDate := weekEndDate.AddDate(0, 0, -6)
+
+	log.Printf("Generating filtered weekly summary for week %s to %s (%s)",
+		weekStartDate.Format("2006-01-02"),
+		weekEndDate.Format("2006-01-02"),
+		filter.String())
+
+	preview, err := g.BuildWeeklyPrompt(weekEndDate, filter)
+	if err != nil {
+		return "", err
+	}
+
+	summary, _, err := g.generate(ctx, preview.Prompt, g.weeklyOutputSpec(preview.Prompt))
+	if err != nil {
+		return "", fmt.Errorf("generate weekly summary: %w", err)
+	}
+	return summary, nil
+}
+
+// groupByOrDefault 返回分组方式，未设置时按项目分组
+func (g *Generator) groupByOrDefault() string {
+	if g.groupBy == models.GroupByTag {
+		return models.GroupByTag
+	}
+	return models.GroupByProject
+}
+
+// collectGroupStats 统计日期范围内满足过滤条件的记录按项目或标签的分布
+func (g *Generator) collectGroupStats(startDate, endDate time.Time, filter models.EntryFilter, by string) []GroupStat {
+	if g.storage == nil {
+		return nil
+	}
+
+	index := make(map[string]int)
+	var stats []GroupStat
+	for current := startDate; !current.After(endDate); current = current.AddDate(0, 0, 1) {
+		dailyData, err := g.storage.GetDailyData(current)
+		if err != nil {
+			continue
+		}
+
+		windows := g.entryWindows(dailyData.Entries)
+		for _, group := range models.GroupEntries(filter.Apply(dailyData.Entries), by) {
+			i, ok := index[group.Name]
+			if !ok {
+				i = len(stats)
+				index[group.Name] = i
+				stats = append(stats, GroupStat{Name: group.Name})
+			}
+			stats[i].EntryCount += len(group.Entries)
+			stats[i].Days++
+			for _, entry := range group.Entries {
+				stats[i].Duration += windows[entryKey(entry)].Duration
+			}
+		}
+	}
+
+	if !hasNamedGroup(stats) {
+		return nil
+	}
+
+	// 按记录条数从多到少排列，未标注的记录放到最后
+	sort.SliceStable(stats, func(i, j int) bool {
+		if (stats[i].Name == "") != (stats[j].Name == "") {
+			return stats[j].Name == ""
+		}
+		return stats[i].EntryCount > stats[j].EntryCount
+	})
+	return stats
+}
+
+// namedGroups 对记录分组；如果所有记录都没有标注，返回 nil
+func namedGroups(entries []models.WorkEntry, by string) []models.EntryGroup {
+	groups := models.GroupEntries(entries, by)
+	for _, group := range groups {
+		if group.Name != "" {
+			return groups
+		}
+	}
+	return nil
+}
+
+// hasNamedGroup 判断统计结果中是否有已标注的分组
+func hasNamedGroup(stats []GroupStat) bool {
+	for _, stat := range stats {
+		if stat.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// toPromptEntries 将工作记录转换为模板数据，windows 为 entryWindows 基于当天全部记录计算的时间窗口
+func toPromptEntries(entries []models.WorkEntry, windows map[string]timesheet.Window) []PromptEntry {
+	result := make([]PromptEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, PromptEntry{
+			ID:         entry.ID,
+			Timestamp:  entry.Timestamp,
+			Time:       entry.Timestamp.Format("15:04"),
+			Content:    entry.Content,
+			Tags:       entry.Tags,
+			Project:    entry.Project,
+			Duration:   windows[entryKey(entry)].Duration,
+			Supplement: windows[entryKey(entry)].Supplement,
+		})
+	}
+	return result
+}
@@ -1,16 +1,18 @@
 package summary
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"strings"
-	"text/template"
 	"time"
 
 	"humg.top/daily_summary/internal/models"
 	"humg.top/daily_summary/internal/storage"
+	"humg.top/daily_summary/internal/timesheet"
+	"humg.top/daily_summary/templates"
 )
 
 // Notifier 通知接口
@@ -23,50 +25,92 @@ type Generator struct {
 	storage      storage.Storage
 	aiClient     AIClient
 	notifier     Notifier
-	templatePath string // 提示词模板路径
+	templatePath string    // 日报提示词模板路径，为空时使用 templateDir 或内置模板
+	groupBy      string    // 提示词中记录的分组方式（project 或 tag）
+	stream       io.Writer // 流式输出目标，为 nil 时等待完整结果（守护进程）
+
+	weeklyTemplatePath  string // 周报模板路径
+	monthlyTemplatePath string // 月报模板路径
+	templateDir         string // 自定义模板目录，其中与内置模板同名的文件优先于内置模板
+
+	dailySections   []string // 每日总结必需的章节，nil 时使用 DefaultDailySections
+	weeklySections  []string // 周报必需的章节，nil 时使用 DefaultWeeklySections
+	monthlySections []string // 月报必需的章节，nil 时使用 DefaultMonthlySections
+	outputRetries   int      // 输出未通过校验时带着问题重新生成的次数
+
+	reportChunkChars int // 报告内容超过该字符数时分段预先总结，0 表示不分段
+
+	timeOptions timesheet.Options // 计算每条记录耗时的规则（休息时段、补充记录、单条上限）
 }
 
 // NewGenerator 创建总结生成器
 func NewGenerator(storage storage.Storage, aiClient AIClient, notifier Notifier) *Generator {
 	return &Generator{
-		storage:      storage,
-		aiClient:     aiClient,
-		notifier:     notifier,
-		templatePath: "", // 默认使用内置模板
+		storage:       storage,
+		aiClient:      aiClient,
+		notifier:      notifier,
+		templatePath:  "", // 默认使用内置模板
+		groupBy:       models.GroupByProject,
+		outputRetries: 1,
+
+		reportChunkChars: DefaultReportChunkChars,
 	}
 }
 
-// SetTemplatePath 设置自定义模板路径
+// SetTemplatePath 设置日报模板路径
 func (g *Generator) SetTemplatePath(path string) {
 	g.templatePath = path
 }
 
+// SetTimeAccounting 设置计算记录耗时的规则，用于提示词中的时长和日报末尾的工时统计
+func (g *Generator) SetTimeAccounting(opts timesheet.Options) {
+	g.timeOptions = opts
+}
+
+// SetStreamOutput 设置流式输出目标，AI 生成的内容会实时写入 w（用于命令行）；为 nil 时关闭
+func (g *Generator) SetStreamOutput(w io.Writer) {
+	g.stream = w
+}
+
+// SetRequiredSections 设置日报、周报和月报必须包含的章节标题，nil 表示使用默认章节，空列表表示不检查
+func (g *Generator) SetRequiredSections(daily, weekly, monthly []string) {
+	g.dailySections = daily
+	g.weeklySections = weekly
+	g.monthlySections = monthly
+}
+
+// SetOutputRetries 设置 AI 输出未通过校验时重新生成的次数，0 表示直接失败
+func (g *Generator) SetOutputRetries(n int) {
+	g.outputRetries = n
+}
+
+// SetGroupBy 设置提示词中记录的分组方式（models.GroupByProject 或 models.GroupByTag）
+func (g *Generator) SetGroupBy(by string) {
+	g.groupBy = by
+}
+
 // GenerateDailySummary 生成每日总结
-func (g *Generator) GenerateDailySummary(date time.Time) error {
-	// 获取当天的所有工作记录
-	dailyData, err := g.storage.GetDailyData(date)
+// ctx 取消或超时时终止 AI 调用，不保存任何内容
+func (g *Generator) GenerateDailySummary(ctx context.Context, date time.Time) error {
+	// 基于当天的所有工作记录构建提示词
+	preview, err := g.BuildDailyPrompt(date, models.EntryFilter{})
 	if err != nil {
-		return fmt.Errorf("get daily data: %w", err)
-	}
-
-	if len(dailyData.Entries) == 0 {
-		return fmt.Errorf("no work entries for date %s", date.Format("2006-01-02"))
+		return err
 	}
 
-	// 构建提示词
-	prompt := g.buildPrompt(dailyData)
-
-	// 调用 AI 客户端生成总结
-	summary, err := g.aiClient.GenerateSummary(prompt)
+	// 调用 AI 客户端生成总结（附加模板数据，AI 不可用时离线总结可直接使用记录）
+	summary, provider, err := g.generate(withPromptData(ctx, preview.Data), preview.Prompt, g.dailyOutputSpec())
 	if err != nil {
 		return fmt.Errorf("generate summary: %w", err)
 	}
+	summary = appendTimeTable(summary, &preview.Data.TimeTable)
 
 	// 保存总结
 	metadata := models.SummaryMetadata{
 		GeneratedAt: time.Now(),
 		Date:        date.Format("2006-01-02"),
-		EntryCount:  len(dailyData.Entries),
+		EntryCount:  preview.EntryCount,
+		Provider:    provider,
 	}
 
 	if err := g.storage.SaveSummary(date, summary, metadata); err != nil {
@@ -91,25 +135,149 @@ func (g *Generator) GenerateDailySummary(date time.Time) error {
 	return nil
 }
 
+// providerReporter 可报告实际生成总结的提供商的客户端（如 Chain）
+type providerReporter interface {
+	Generate(ctx context.Context, prompt string, onChunk func(string)) (summary, provider string, err error)
+}
+
+// generate 调用 AI 客户端生成总结，清理并按 spec 校验输出，返回总结和生成它的提供商（客户端无法报告时为空）
+// 校验未通过时附上问题重新生成（最多 g.outputRetries 次），仍不合格则返回 ErrInvalidOutput，避免保存无效内容
+func (g *Generator) generate(ctx context.Context, prompt string, spec outputSpec) (string, string, error) {
+	attemptPrompt := prompt
+	for attempt := 0; ; attempt++ {
+		summary, provider, err := g.generateOnce(ctx, attemptPrompt)
+		if err != nil {
+			return "", "", err
+		}
+		// 离线总结按规则生成，格式固定，重新生成也不会变化
+		if provider == OfflineProviderName {
+			log.Printf("Summary generated offline (no AI provider available)")
+			return summary, provider, nil
+		}
+
+		summary, problems := spec.process(summary)
+		if len(problems) == 0 {
+			return summary, provider, nil
+		}
+
+		log.Printf("AI output failed validation (attempt %d/%d, %d characters): %s",
+			attempt+1, g.outputRetries+1, len(summary), strings.Join(problems, "; "))
+		if attempt >= g.outputRetries {
+			return "", "", fmt.Errorf("%w: %s", ErrInvalidOutput, strings.Join(problems, "; "))
+		}
+		if g.stream != nil {
+			fmt.Fprintf(g.stream, "[输出未通过格式校验，正在重新生成: %s]\n\n", strings.Join(problems, "; "))
+		}
+		attemptPrompt = repairPrompt(prompt, problems)
+	}
+}
+
+// generateOnce 调用一次 AI 客户端
+// 设置了流式输出时，生成过程中的内容实时写入 g.stream
+// 空结果视为失败，避免把空白内容保存为总结
+func (g *Generator) generateOnce(ctx context.Context, prompt string) (string, string, error) {
+	var onChunk func(string)
+	if g.stream != nil {
+		var streamed bool
+		onChunk = func(s string) {
+			streamed = true
+			io.WriteString(g.stream, s)
+		}
+		defer func() {
+			if streamed {
+				io.WriteString(g.stream, "\n")
+			}
+		}()
+	}
+
+	var summary, provider string
+	var err error
+	if reporter, ok := g.aiClient.(providerReporter); ok {
+		summary, provider, err = reporter.Generate(ctx, prompt, onChunk)
+	} else {
+		summary, err = StreamSummary(ctx, g.aiClient, prompt, onChunk)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(summary) == "" {
+		return "", "", errors.New("AI returned an empty summary")
+	}
+	return summary, provider, nil
+}
+
+// dailyOutputSpec 每日总结的输出格式：Markdown，包含必需章节
+func (g *Generator) dailyOutputSpec() outputSpec {
+	sections := g.dailySections
+	if sections == nil {
+		sections = DefaultDailySections
+	}
+	return outputSpec{sections: sections}
+}
+
+// weeklyOutputSpec 周报的输出格式：模板要求 HTML 文档，降级提示词要求 Markdown
+func (g *Generator) weeklyOutputSpec(prompt string) outputSpec {
+	sections := g.weeklySections
+	if sections == nil {
+		sections = DefaultWeeklySections
+	}
+	return outputSpec{html: expectsHTML(prompt), sections: sections}
+}
+
 // PromptData 模板数据结构
 type PromptData struct {
-	Date       string
-	EntryCount int
-	Entries    []PromptEntry
+	Date            string
+	Weekday         string // 星期几（如 周三）
+	EntryCount      int
+	Entries         []PromptEntry
+	TotalDuration   time.Duration   // 各条记录 Duration 之和
+	TimeTable       timesheet.Table // 程序根据记录时间计算的工时表（按 GroupBy 汇总），可用 {{.TimeTable.Markdown}} 输出
+	Filter          string          // 过滤条件（如 "@billing #oncall"），未过滤时为空
+	GroupBy         string          // 分组方式：project 或 tag
+	Groups          []PromptGroup   // 按项目或标签分组的记录，没有任何标注时为空
+	PreviousDate    string          // 之前最近一篇日报的日期（最多往前找 7 天），没有时为空
+	PreviousSummary string          // 之前最近一篇日报的正文（不含文件头），没有时为空
 }
 
 // PromptEntry 单条工作记录
 type PromptEntry struct {
-	Time    string
-	Content string
+	ID         string
+	Timestamp  time.Time
+	Time       string // 记录时间（HH:MM）
+	Content    string
+	Tags       []string      // #标签
+	Project    string        // @项目
+	Duration   time.Duration // 该记录覆盖的时间窗口（距上一条常规记录，扣除休息时段）
+	Supplement bool          // 补充记录（如 #补充），补记之前遗漏的工作，不计时
+}
+
+// PromptGroup 按项目或标签分组的工作记录
+type PromptGroup struct {
+	Name       string // 项目或标签名，未标注的记录为空
+	EntryCount int
+	Entries    []PromptEntry
+	Duration   time.Duration // 组内记录 Duration 之和
 }
 
 // WeeklyPromptData 周报模板数据结构
 type WeeklyPromptData struct {
-	WeekStartDate   string
-	WeekEndDate     string
-	EntryCount      int
-	DailySummaries  []DailySummaryEntry
+	WeekStartDate       string
+	WeekEndDate         string
+	EntryCount          int
+	DailySummaries      []DailySummaryEntry
+	TotalDuration       time.Duration // 本周各条记录 Duration 之和
+	Filter              string        // 过滤条件，未过滤时为空
+	GroupBy             string        // 分组方式：project 或 tag
+	Groups              []GroupStat   // 本周记录按项目或标签的分布，没有任何标注时为空
+	PreviousWeekSummary string        // 上周周报（已转换为纯文本），没有时为空
+}
+
+// GroupStat 项目或标签在一段时间内的记录统计
+type GroupStat struct {
+	Name       string        // 项目或标签名，未标注的记录为空
+	EntryCount int           // 记录条数
+	Days       int           // 涉及天数
+	Duration   time.Duration // 记录 Duration 之和
 }
 
 // DailySummaryEntry 单日总结条目
@@ -118,70 +286,87 @@ type DailySummaryEntry struct {
 	Weekday    string
 	HasSummary bool
 	Summary    string
+	EntryCount int           // 当天（满足过滤条件的）记录条数
+	Duration   time.Duration // 当天记录 Duration 之和
+	Entries    []PromptEntry // 当天的原始记录
 }
 
 // buildPrompt 构建发送给 Claude 的提示词
 func (g *Generator) buildPrompt(dailyData *models.DailyData) string {
-	// 准备模板数据
-	entries := make([]PromptEntry, 0, len(dailyData.Entries))
-	for _, entry := range dailyData.Entries {
-		entries = append(entries, PromptEntry{
-			Time:    entry.Timestamp.Format("15:04"),
-			Content: entry.Content,
-		})
-	}
+	return g.buildFilteredPrompt(dailyData, models.EntryFilter{})
+}
+
+// buildFilteredPrompt 构建只包含满足过滤条件的记录的提示词
+func (g *Generator) buildFilteredPrompt(dailyData *models.DailyData, filter models.EntryFilter) string {
+	prompt, _, _ := g.renderDailyPrompt(dailyData, filter)
+	return prompt
+}
 
+// renderDailyPrompt 构建日报提示词，同时返回实际使用的模板来源（FallbackPromptSource 表示降级提示词）和模板数据
+func (g *Generator) renderDailyPrompt(dailyData *models.DailyData, filter models.EntryFilter) (string, string, PromptData) {
+	// 记录时长基于当天全部记录计算，过滤不影响每条记录覆盖的时间窗口
+	windows := g.entryWindows(dailyData.Entries)
+	groupBy := g.groupByOrDefault()
+	table := g.timeTable(dailyData.Entries, filter, groupBy)
+	filtered := *dailyData
+	filtered.Entries = filter.Apply(dailyData.Entries)
+	dailyData = &filtered
+
+	// 准备模板数据
 	data := PromptData{
 		Date:       dailyData.Date,
 		EntryCount: len(dailyData.Entries),
-		Entries:    entries,
-	}
-
-	// 确定模板路径
-	templatePath := g.templatePath
-	if templatePath == "" {
-		// 默认使用模板
-		templatePath = "templates/summary_prompt.md"
+		Entries:    toPromptEntries(dailyData.Entries, windows),
+		TimeTable:  table,
+		Filter:     filter.String(),
+		GroupBy:    groupBy,
 	}
-
-	// 读取模板文件
-	templateContent, err := os.ReadFile(templatePath)
-	if err != nil {
-		log.Printf("Warning: failed to read template file %s: %v, using fallback", templatePath, err)
-		return g.buildFallbackPrompt(dailyData)
+	data.TotalDuration = totalDuration(data.Entries)
+	if date, err := time.Parse("2006-01-02", dailyData.Date); err == nil {
+		data.Weekday = getWeekdayName(date)
+		data.PreviousDate, data.PreviousSummary = g.previousDailySummary(date)
 	}
-
-	// 解析并执行模板
-	tmpl, err := template.New("prompt").Parse(string(templateContent))
-	if err != nil {
-		log.Printf("Warning: failed to parse template: %v, using fallback", err)
-		return g.buildFallbackPrompt(dailyData)
+	for _, group := range namedGroups(dailyData.Entries, data.GroupBy) {
+		entries := toPromptEntries(group.Entries, windows)
+		data.Groups = append(data.Groups, PromptGroup{
+			Name:       group.Name,
+			EntryCount: len(group.Entries),
+			Entries:    entries,
+			Duration:   totalDuration(entries),
+		})
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		log.Printf("Warning: failed to execute template: %v, using fallback", err)
-		return g.buildFallbackPrompt(dailyData)
+	// 依次尝试配置的模板、template_dir 和内置模板
+	prompt, source, ok := g.renderPrompt("daily", templates.Daily, g.templatePath, data)
+	if !ok {
+		return g.buildFallbackPrompt(dailyData, data.Filter, table), source, data
 	}
-
-	return buf.String()
+	return prompt, source, data
 }
 
 // buildFallbackPrompt 降级方案：使用原有的硬编码逻辑
-func (g *Generator) buildFallbackPrompt(dailyData *models.DailyData) string {
+func (g *Generator) buildFallbackPrompt(dailyData *models.DailyData, filter string, table timesheet.Table) string {
 	var builder strings.Builder
 
 	builder.WriteString(fmt.Sprintf("请为以下工作记录生成一份结构化的工作总结（日期：%s）\n\n", dailyData.Date))
-	builder.WriteString("工作记录（每1条记录都是对前一个时间窗口工作内容的总结）：\n\n")
+	if filter != "" {
+		builder.WriteString(fmt.Sprintf("本次总结仅包含带有 %s 标注的记录。\n\n", filter))
+	}
+	builder.WriteString("工作记录（每1条记录都是对前一个时间窗口工作内容的总结；@项目 和 #标签 是用户标注的分类，请优先按其归类）：\n\n")
 
 	for _, entry := range dailyData.Entries {
 		timeStr := entry.Timestamp.Format("15:04")
 		builder.WriteString(fmt.Sprintf("- **%s**: %s\n", timeStr, entry.Content))
 	}
 
+	if table.Total > 0 {
+		builder.WriteString("\n程序根据记录时间计算的工时（请直接使用这些数字，不要另行估算；工时表会自动附在总结末尾，无需重复）：\n\n")
+		builder.WriteString(table.Markdown())
+	}
+
 	builder.WriteString("\n请按照以下格式生成总结：\n")
 	builder.WriteString("## 主要完成的任务\n")
-	builder.WriteString("（列出完成的主要工作，按项目或模块分类，并估算工作实际耗时）\n\n")
+	builder.WriteString("（列出完成的主要工作，按项目或模块分类，并标注工作实际耗时）\n\n")
 	builder.WriteString("## 关键进展\n")
 	builder.WriteString("（突出重要的进展和成果）\n\n")
 	builder.WriteString("## 遇到的问题\n")
@@ -194,7 +379,7 @@ func (g *Generator) buildFallbackPrompt(dailyData *models.DailyData) string {
 
 // GenerateWeeklySummary 生成周度总结
 // weekEndDate: 周的最后一天（周日）
-func (g *Generator) GenerateWeeklySummary(weekEndDate time.Time) error {
+func (g *Generator) GenerateWeeklySummary(ctx context.Context, weekEndDate time.Time) error {
 	// 计算周的开始日期（周一）
 	weekStartDate := weekEndDate.AddDate(0, 0, -6)
 
@@ -202,25 +387,14 @@ func (g *Generator) GenerateWeeklySummary(weekEndDate time.Time) error {
 		weekStartDate.Format("2006-01-02"),
 		weekEndDate.Format("2006-01-02"))
 
-	// 获取该周的所有每日总结
-	dailySummaries, err := g.storage.GetDailySummariesInRange(weekStartDate, weekEndDate)
+	// 基于该周的所有每日总结构建周度总结的 prompt
+	preview, err := g.BuildWeeklyPrompt(weekEndDate, models.EntryFilter{})
 	if err != nil {
-		return fmt.Errorf("get daily summaries: %w", err)
-	}
-
-	if len(dailySummaries) == 0 {
-		return fmt.Errorf("no daily summaries found for week %s to %s",
-			weekStartDate.Format("2006-01-02"),
-			weekEndDate.Format("2006-01-02"))
+		return err
 	}
 
-	log.Printf("Found %d daily summaries for the week", len(dailySummaries))
-
-	// 构建周度总结的 prompt
-	prompt := g.buildWeeklyPrompt(weekStartDate, weekEndDate, dailySummaries)
-
 	// 调用 AI 生成周度总结
-	summary, err := g.aiClient.GenerateSummary(prompt)
+	summary, provider, err := g.generate(ctx, preview.Prompt, g.weeklyOutputSpec(preview.Prompt))
 	if err != nil {
 		return fmt.Errorf("generate weekly summary: %w", err)
 	}
@@ -229,7 +403,8 @@ func (g *Generator) GenerateWeeklySummary(weekEndDate time.Time) error {
 	metadata := models.SummaryMetadata{
 		GeneratedAt: time.Now(),
 		Date:        weekEndDate.Format("2006-01-02"),
-		EntryCount:  len(dailySummaries),
+		EntryCount:  preview.EntryCount,
+		Provider:    provider,
 	}
 
 	if err := g.storage.SaveWeeklySummary(weekEndDate, summary, metadata); err != nil {
@@ -255,10 +430,22 @@ func (g *Generator) GenerateWeeklySummary(weekEndDate time.Time) error {
 }
 
 // buildWeeklyPrompt 构建周度总结的 prompt
+// filter 非空时 dailySummaries 为按条件过滤后的每日记录，而不是每日总结
 func (g *Generator) buildWeeklyPrompt(
 	weekStartDate, weekEndDate time.Time,
 	dailySummaries map[string]string,
+	filter models.EntryFilter,
 ) string {
+	prompt, _ := g.renderWeeklyPrompt(weekStartDate, weekEndDate, dailySummaries, filter)
+	return prompt
+}
+
+// renderWeeklyPrompt 构建周报提示词，同时返回实际使用的模板来源（FallbackPromptSource 表示降级提示词）
+func (g *Generator) renderWeeklyPrompt(
+	weekStartDate, weekEndDate time.Time,
+	dailySummaries map[string]string,
+	filter models.EntryFilter,
+) (string, string) {
 	// 准备模板数据
 	summaries := make([]DailySummaryEntry, 0, 7)
 	current := weekStartDate
@@ -266,21 +453,18 @@ func (g *Generator) buildWeeklyPrompt(
 		dateStr := current.Format("2006-01-02")
 		weekdayStr := getWeekdayName(current)
 
+		entry := DailySummaryEntry{
+			Date:    dateStr,
+			Weekday: weekdayStr,
+		}
 		if summary, ok := dailySummaries[dateStr]; ok {
-			summaries = append(summaries, DailySummaryEntry{
-				Date:       dateStr,
-				Weekday:    weekdayStr,
-				HasSummary: true,
-				Summary:    summary,
-			})
-		} else {
-			summaries = append(summaries, DailySummaryEntry{
-				Date:       dateStr,
-				Weekday:    weekdayStr,
-				HasSummary: false,
-				Summary:    "",
-			})
+			entry.HasSummary = true
+			entry.Summary = summary
 		}
+		entry.Entries = g.dayPromptEntries(current, filter)
+		entry.EntryCount = len(entry.Entries)
+		entry.Duration = totalDuration(entry.Entries)
+		summaries = append(summaries, entry)
 
 		current = current.AddDate(0, 0, 1)
 	}
@@ -290,38 +474,28 @@ func (g *Generator) buildWeeklyPrompt(
 		WeekEndDate:    weekEndDate.Format("2006-01-02"),
 		EntryCount:     len(dailySummaries),
 		DailySummaries: summaries,
+		Filter:         filter.String(),
+		GroupBy:        g.groupByOrDefault(),
 	}
-
-	// 确定模板路径
-	templatePath := "templates/weekly_summary_prompt.md"
-
-	// 读取模板文件
-	templateContent, err := os.ReadFile(templatePath)
-	if err != nil {
-		log.Printf("Warning: failed to read weekly template file %s: %v, using fallback", templatePath, err)
-		return g.buildWeeklyFallbackPrompt(weekStartDate, weekEndDate, dailySummaries)
+	for _, day := range summaries {
+		data.TotalDuration += day.Duration
 	}
+	data.PreviousWeekSummary = g.previousWeeklySummary(weekEndDate)
+	data.Groups = g.collectGroupStats(weekStartDate, weekEndDate, filter, data.GroupBy)
 
-	// 解析并执行模板
-	tmpl, err := template.New("weekly_prompt").Parse(string(templateContent))
-	if err != nil {
-		log.Printf("Warning: failed to parse weekly template: %v, using fallback", err)
-		return g.buildWeeklyFallbackPrompt(weekStartDate, weekEndDate, dailySummaries)
+	// 依次尝试配置的模板、template_dir 和内置模板
+	prompt, source, ok := g.renderPrompt("weekly", templates.Weekly, g.weeklyTemplatePath, data)
+	if !ok {
+		return g.buildWeeklyFallbackPrompt(weekStartDate, weekEndDate, dailySummaries, data.Filter), source
 	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		log.Printf("Warning: failed to execute weekly template: %v, using fallback", err)
-		return g.buildWeeklyFallbackPrompt(weekStartDate, weekEndDate, dailySummaries)
-	}
-
-	return buf.String()
+	return prompt, source
 }
 
 // buildWeeklyFallbackPrompt 降级方案：使用原有的硬编码逻辑
 func (g *Generator) buildWeeklyFallbackPrompt(
 	weekStartDate, weekEndDate time.Time,
 	dailySummaries map[string]string,
+	filter string,
 ) string {
 	var builder strings.Builder
 
@@ -329,6 +503,10 @@ func (g *Generator) buildWeeklyFallbackPrompt(
 		weekStartDate.Format("2006-01-02"),
 		weekEndDate.Format("2006-01-02")))
 
+	if filter != "" {
+		builder.WriteString(fmt.Sprintf("本次周报仅关注带有 %s 标注的工作，以下为每天相关的工作记录。\n\n", filter))
+	}
+
 	builder.WriteString("## 本周每日总结\n\n")
 
 	// 按日期顺序遍历（周一到周日）
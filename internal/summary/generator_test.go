@@ -115,6 +115,34 @@ func TestBuildPromptFallback(t *testing.T) {
 	t.Logf("Fallback prompt:\n%s", prompt)
 }
 
+// TestBuildFilteredPromptWithGroups 测试提示词中的项目分组和过滤
+func TestBuildFilteredPromptWithGroups(t *testing.T) {
+	dailyData := &models.DailyData{Date: "2026-01-21"}
+	for i, content := range []string{"@billing 对账任务排查 #bug", "@search 索引优化", "团队周会"} {
+		entry := models.WorkEntry{
+			Timestamp: time.Date(2026, 1, 21, 9+i, 0, 0, 0, time.Local),
+			Content:   content,
+		}
+		entry.ParseMeta()
+		dailyData.Entries = append(dailyData.Entries, entry)
+	}
+
+	generator := &Generator{
+		templatePath: "../../templates/summary_prompt.md",
+		groupBy:      models.GroupByProject,
+	}
+
+	prompt := generator.buildPrompt(dailyData)
+	if !contains(prompt, "按项目分组") || !contains(prompt, "**billing**（1 条）：09:00") || !contains(prompt, "**未标注**（1 条）：11:00") {
+		t.Errorf("prompt should contain project groups:\n%s", prompt)
+	}
+
+	prompt = generator.buildFilteredPrompt(dailyData, models.EntryFilter{Project: "billing"})
+	if !contains(prompt, "@billing") || contains(prompt, "索引优化") || contains(prompt, "团队周会") {
+		t.Errorf("filtered prompt should only contain @billing entries:\n%s", prompt)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 || 
 		func() bool {
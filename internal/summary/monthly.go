@@ -0,0 +1,224 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/templates"
+)
+
+// MonthlyPromptData 月报模板数据结构
+type MonthlyPromptData struct {
+	Month           string               // 月份（YYYY-MM）
+	MonthStartDate  string               // 月初日期
+	MonthEndDate    string               // 月末日期
+	DailyCount      int                  // 日报篇数
+	WeeklyCount     int                  // 周报篇数
+	WeeklySummaries []WeeklySummaryEntry // 与本月有重叠的周报（已转换为纯文本）
+	DailySummaries  []DailySummaryEntry  // 本月有日报的日期
+}
+
+// WeeklySummaryEntry 单周周报条目
+type WeeklySummaryEntry struct {
+	WeekStartDate string
+	WeekEndDate   string
+	Summary       string
+}
+
+// MonthRange 返回 month 所在月份的第一天和最后一天
+func MonthRange(month time.Time) (start, end time.Time) {
+	start = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	return start, start.AddDate(0, 1, -1)
+}
+
+// GenerateMonthlySummary 生成月度总结
+// month: 该月的任意一天；基于本月的每日总结和与本月有重叠的周报生成
+func (g *Generator) GenerateMonthlySummary(ctx context.Context, month time.Time) error {
+	monthStart, monthEnd := MonthRange(month)
+	monthStr := monthStart.Format("2006-01")
+
+	log.Printf("Generating monthly summary for %s", monthStr)
+
+	dailySummaries, err := g.storage.GetDailySummariesInRange(monthStart, monthEnd)
+	if err != nil {
+		return fmt.Errorf("get daily summaries: %w", err)
+	}
+	if len(dailySummaries) == 0 {
+		return fmt.Errorf("no daily summaries found for month %s", monthStr)
+	}
+
+	// 周末日期落在 [月初, 月末+6] 内的周与本月有重叠
+	weeklySummaries, err := g.storage.GetWeeklySummariesInRange(monthStart, monthEnd.AddDate(0, 0, 6))
+	if err != nil {
+		return fmt.Errorf("get weekly summaries: %w", err)
+	}
+
+	log.Printf("Found %d daily summaries and %d weekly summaries for %s",
+		len(dailySummaries), len(weeklySummaries), monthStr)
+
+	prompt := g.buildMonthlyPrompt(monthStart, monthEnd, dailySummaries, weeklySummaries)
+
+	summary, provider, err := g.generate(ctx, prompt, g.monthlyOutputSpec())
+	if err != nil {
+		return fmt.Errorf("generate monthly summary: %w", err)
+	}
+
+	metadata := models.SummaryMetadata{
+		GeneratedAt: time.Now(),
+		Date:        monthStr,
+		EntryCount:  len(dailySummaries),
+		Provider:    provider,
+	}
+
+	if err := g.storage.SaveMonthlySummary(monthStart, summary, metadata); err != nil {
+		return fmt.Errorf("save monthly summary: %w", err)
+	}
+
+	// 发送通知
+	if g.notifier != nil {
+		title := "月报已生成"
+		message := fmt.Sprintf("%s 的月度总结已完成", monthStart.Format("2006年01月"))
+		log.Printf("Sending notification: %s - %s", title, message)
+		if err := g.notifier.ShowNotification(title, message); err != nil {
+			log.Printf("Failed to send notification: %v", err)
+		} else {
+			log.Printf("Notification sent successfully")
+		}
+	}
+
+	log.Printf("Monthly summary generated successfully")
+	return nil
+}
+
+// monthlyOutputSpec 月报的输出格式：Markdown，包含必需章节
+func (g *Generator) monthlyOutputSpec() outputSpec {
+	sections := g.monthlySections
+	if sections == nil {
+		sections = DefaultMonthlySections
+	}
+	return outputSpec{sections: sections}
+}
+
+// buildMonthlyPrompt 构建月度总结的 prompt
+func (g *Generator) buildMonthlyPrompt(
+	monthStart, monthEnd time.Time,
+	dailySummaries, weeklySummaries map[string]string,
+) string {
+	data := MonthlyPromptData{
+		Month:          monthStart.Format("2006-01"),
+		MonthStartDate: monthStart.Format("2006-01-02"),
+		MonthEndDate:   monthEnd.Format("2006-01-02"),
+		DailyCount:     len(dailySummaries),
+		WeeklyCount:    len(weeklySummaries),
+	}
+
+	for _, weekEnd := range sortedKeys(weeklySummaries) {
+		weekEndDate, err := time.Parse("2006-01-02", weekEnd)
+		if err != nil {
+			continue
+		}
+		data.WeeklySummaries = append(data.WeeklySummaries, WeeklySummaryEntry{
+			WeekStartDate: weekEndDate.AddDate(0, 0, -6).Format("2006-01-02"),
+			WeekEndDate:   weekEnd,
+			Summary:       summaryText(weeklySummaries[weekEnd]),
+		})
+	}
+
+	for _, dateStr := range sortedKeys(dailySummaries) {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			continue
+		}
+		data.DailySummaries = append(data.DailySummaries, DailySummaryEntry{
+			Date:       dateStr,
+			Weekday:    getWeekdayName(date),
+			HasSummary: true,
+			Summary:    dailySummaries[dateStr],
+		})
+	}
+
+	prompt, _, ok := g.renderPrompt("monthly", templates.Monthly, g.monthlyTemplatePath, data)
+	if !ok {
+		return buildMonthlyFallbackPrompt(data)
+	}
+	return prompt
+}
+
+// buildMonthlyFallbackPrompt 降级方案：模板不可用时使用硬编码的提示词
+func buildMonthlyFallbackPrompt(data MonthlyPromptData) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("请基于以下周报和每日工作总结生成一份月度总结（%s，%s 至 %s）\n\n",
+		data.Month, data.MonthStartDate, data.MonthEndDate))
+
+	if len(data.WeeklySummaries) > 0 {
+		builder.WriteString("## 本月周报\n\n")
+		for _, weekly := range data.WeeklySummaries {
+			builder.WriteString(fmt.Sprintf("### %s 至 %s\n\n", weekly.WeekStartDate, weekly.WeekEndDate))
+			builder.WriteString(weekly.Summary)
+			builder.WriteString("\n\n")
+		}
+	}
+
+	builder.WriteString("## 本月每日总结\n\n")
+	for _, daily := range data.DailySummaries {
+		builder.WriteString(fmt.Sprintf("### %s (%s)\n\n", daily.Date, daily.Weekday))
+		builder.WriteString(daily.Summary)
+		builder.WriteString("\n\n")
+	}
+
+	builder.WriteString("---\n\n")
+	builder.WriteString("请直接输出 Markdown 格式的月报，包括以下部分：\n\n")
+	builder.WriteString("## 本月完成情况\n")
+	builder.WriteString("（按项目或模块汇总本月完成的主要工作，统计各项目耗时）\n\n")
+	builder.WriteString("## 关键进展与成果\n")
+	builder.WriteString("（突出本月的重要进展和里程碑）\n\n")
+	builder.WriteString("## 遇到的问题与经验\n")
+	builder.WriteString("（列出本月的主要问题、解决情况和经验）\n\n")
+	builder.WriteString("## 下月计划\n")
+	builder.WriteString("（基于本月情况和记录中的计划，规划下月重点）\n")
+
+	return builder.String()
+}
+
+var (
+	// htmlTextTagPattern 匹配任意 HTML 标签
+	htmlTextTagPattern = regexp.MustCompile(`<[^>]*>`)
+	// blankLinesPattern 匹配连续空行
+	blankLinesPattern = regexp.MustCompile(`\n\s*\n\s*`)
+)
+
+// summaryText 将周报 HTML 转换为纯文本，去掉样式，减少 prompt 长度；Markdown 周报原样返回
+func summaryText(content string) string {
+	if !strings.Contains(strings.ToLower(content), "<html") {
+		return strings.TrimSpace(content)
+	}
+
+	text := htmlRawTextPattern.ReplaceAllString(content, "")
+	text = htmlTextTagPattern.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n")
+	return strings.TrimSpace(text)
+}
+
+// sortedKeys 返回按字典序排列的 key（日期字符串即按时间排序）
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
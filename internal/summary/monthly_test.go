@@ -0,0 +1,45 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBuildMonthlyPrompt 测试月报提示词包含按日期排序的日报和转换为纯文本的周报
+func TestBuildMonthlyPrompt(t *testing.T) {
+	monthStart, monthEnd := MonthRange(time.Date(2026, 9, 17, 0, 0, 0, 0, time.Local))
+	if monthStart.Format("2006-01-02") != "2026-09-01" || monthEnd.Format("2006-01-02") != "2026-09-30" {
+		t.Fatalf("Unexpected month range: %s - %s", monthStart, monthEnd)
+	}
+
+	daily := map[string]string{
+		"2026-09-14": "## 主要完成的任务\n- 支付对账",
+		"2026-09-02": "## 主要完成的任务\n- 需求评审",
+	}
+	weekly := map[string]string{
+		"2026-09-06": `<!DOCTYPE html><html><head><style>.card { color: red; }</style></head>
+<body><h2>1. 本周完成情况</h2><div class="card"><strong>需求评审</strong> &amp; 设计</div></body></html>
+<!-- provider: codex -->`,
+	}
+
+	g := &Generator{}
+	prompt := g.buildMonthlyPrompt(monthStart, monthEnd, daily, weekly)
+
+	for _, want := range []string{"2026-09", "2026-08-31 至 2026-09-06", "1. 本周完成情况", "需求评审\n& 设计", "2026-09-02 (周三)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Prompt should contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"<div", "color: red", "provider: codex"} {
+		if strings.Contains(prompt, unwanted) {
+			t.Errorf("Prompt should not contain %q", unwanted)
+		}
+	}
+	if strings.Index(prompt, "- 需求评审") > strings.Index(prompt, "- 支付对账") {
+		t.Error("Daily summaries should be sorted by date")
+	}
+	if expectsHTML(prompt) {
+		t.Error("Monthly prompt should not ask for HTML output")
+	}
+}
@@ -0,0 +1,177 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// OfflineProviderName 离线规则总结的提供商名称，可放在 ai_providers 末尾作为兜底（如 [codex, offline]）
+const OfflineProviderName = "offline"
+
+// ErrOfflineUnsupported 离线总结只支持日报（周报、月报和报告需要 AI 归纳多天的内容）
+var ErrOfflineUnsupported = errors.New("offline summarizer only supports daily summaries")
+
+func init() {
+	RegisterProvider(OfflineProviderName, func(cfg *models.Config, name string) (AIClient, error) {
+		return NewOfflineClient(), nil
+	})
+}
+
+// offlineNotice 离线总结开头的说明，便于和 AI 生成的总结区分
+const offlineNotice = "> ⚠️ 本总结由离线规则生成（未使用 AI）：按记录整理了时间线、分组、耗时和识别到的待办，没有归纳和改写。"
+
+var (
+	// offlinePlanPattern 识别计划/待办的关键词
+	offlinePlanPattern = regexp.MustCompile(`(?i)明日|明天|下一步|待办|todo|计划`)
+	// offlineProblemPattern 识别问题/阻碍的关键词
+	offlineProblemPattern = regexp.MustCompile(`(?i)问题|阻塞|卡住|失败|报错|异常|故障|风险|bug|blocked|error`)
+	// offlineProgressPattern 识别进展/成果的关键词
+	offlineProgressPattern = regexp.MustCompile(`(?i)完成|上线|发布|合入|修复|解决|done|fixed|released|merged`)
+	// offlineSentenceSeparators 将记录拆分为句子的分隔符
+	offlineSentenceSeparators = regexp.MustCompile(`[\n。；;！!]+`)
+)
+
+// promptDataKey 在 ctx 中附加日报模板数据的 key
+type promptDataKey struct{}
+
+// withPromptData 在 ctx 中附加日报模板数据，供离线总结使用（提示词本身无法还原结构化的记录）
+func withPromptData(ctx context.Context, data *PromptData) context.Context {
+	return context.WithValue(ctx, promptDataKey{}, data)
+}
+
+// promptDataFrom 读取 withPromptData 附加的日报模板数据
+func promptDataFrom(ctx context.Context) (*PromptData, bool) {
+	data, ok := ctx.Value(promptDataKey{}).(*PromptData)
+	return data, ok && data != nil
+}
+
+// OfflineClient 不调用 AI、按规则从工作记录生成日报的客户端（实现 AIClient）
+// 记录从 ctx 中读取（Generator 生成日报时附加），不解析提示词；其他类型的总结返回 ErrOfflineUnsupported
+type OfflineClient struct{}
+
+// NewOfflineClient 创建离线总结客户端
+func NewOfflineClient() *OfflineClient {
+	return &OfflineClient{}
+}
+
+// GenerateSummary 根据 ctx 中的日报数据生成总结
+func (c *OfflineClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	data, ok := promptDataFrom(ctx)
+	if !ok {
+		return "", ErrOfflineUnsupported
+	}
+	return OfflineSummary(data), nil
+}
+
+// OfflineSummary 按规则生成日报：章节与内置模板一致，便于通过输出校验和后续周报汇总
+func OfflineSummary(data *PromptData) string {
+	var builder strings.Builder
+	builder.WriteString(offlineNotice + "\n\n")
+	if data.Filter != "" {
+		builder.WriteString(fmt.Sprintf("> 仅包含带有 %s 标注的记录。\n\n", data.Filter))
+	}
+
+	builder.WriteString("## 主要完成的任务\n\n")
+	groups := data.Groups
+	if len(groups) == 0 {
+		groups = []PromptGroup{{EntryCount: len(data.Entries), Entries: data.Entries, Duration: data.TotalDuration}}
+	}
+	for _, group := range groups {
+		if len(data.Groups) > 0 {
+			name := group.Name
+			if name == "" {
+				name = "未标注"
+			}
+			builder.WriteString(fmt.Sprintf("### %s（%d 条，%s 小时）\n\n", name, group.EntryCount, formatHours(group.Duration)))
+		}
+		for _, entry := range group.Entries {
+			builder.WriteString(fmt.Sprintf("- %s %s\n", offlineEntryTime(entry), oneLine(entry.Content)))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("## 工作耗时分析\n\n")
+	builder.WriteString(fmt.Sprintf("- 共 %d 条记录，记录耗时合计 %s 小时\n", data.EntryCount, formatHours(data.TotalDuration)))
+	if items := data.TimeTable.Items; len(items) > 0 && items[0].Name != "" {
+		builder.WriteString(fmt.Sprintf("- 耗时最多：%s（%.1f 小时，%.1f%%）\n", items[0].Name, items[0].Hours, items[0].Percent))
+	}
+	if data.GroupBy != models.GroupByTag {
+		var tags []string
+		for _, group := range groupPromptEntries(data.Entries, models.GroupByTag) {
+			if group.Name != "" {
+				tags = append(tags, fmt.Sprintf("#%s %s 小时", group.Name, formatHours(group.Duration)))
+			}
+		}
+		if len(tags) > 0 {
+			builder.WriteString("- 按标签：" + strings.Join(tags, "，") + "\n")
+		}
+	}
+	if data.TimeTable.Total > 0 {
+		builder.WriteString("- 工时表见文末\"工时统计\"\n")
+	}
+	builder.WriteString("\n")
+
+	builder.WriteString("## 时间线\n\n")
+	for _, entry := range data.Entries {
+		builder.WriteString(fmt.Sprintf("- %s %s\n", offlineEntryTime(entry), oneLine(entry.Content)))
+	}
+	builder.WriteString("\n")
+
+	writeOfflineSection(&builder, "关键进展", matchingSentences(data.Entries, offlineProgressPattern), "记录中没有识别到明确的完成事项")
+	writeOfflineSection(&builder, "遇到的问题", matchingSentences(data.Entries, offlineProblemPattern), "记录中没有提到问题")
+	writeOfflineSection(&builder, "明日计划", matchingSentences(data.Entries, offlinePlanPattern), "记录中没有提到明日计划或待办")
+
+	return strings.TrimRight(builder.String(), "\n") + "\n"
+}
+
+// writeOfflineSection 输出一个章节，没有内容时输出 empty
+func writeOfflineSection(builder *strings.Builder, title string, items []string, empty string) {
+	builder.WriteString("## " + title + "\n\n")
+	if len(items) == 0 {
+		builder.WriteString("- " + empty + "\n\n")
+		return
+	}
+	for _, item := range items {
+		builder.WriteString("- " + item + "\n")
+	}
+	builder.WriteString("\n")
+}
+
+// matchingSentences 返回记录中包含关键词的句子（带记录时间），按记录顺序排列
+func matchingSentences(entries []PromptEntry, pattern *regexp.Regexp) []string {
+	var result []string
+	for _, entry := range entries {
+		for _, sentence := range offlineSentenceSeparators.Split(entry.Content, -1) {
+			sentence = strings.TrimSpace(sentence)
+			if sentence != "" && pattern.MatchString(sentence) {
+				result = append(result, fmt.Sprintf("%s：%s", entry.Time, sentence))
+			}
+		}
+	}
+	return result
+}
+
+// offlineEntryTime 返回记录的时间前缀，包含耗时或补充记录标记
+func offlineEntryTime(entry PromptEntry) string {
+	switch {
+	case entry.Supplement:
+		return fmt.Sprintf("**%s**（补充）", entry.Time)
+	case entry.Duration > 0:
+		return fmt.Sprintf("**%s**（%s）", entry.Time, formatDuration(entry.Duration))
+	default:
+		return fmt.Sprintf("**%s**", entry.Time)
+	}
+}
+
+// oneLine 将多行内容合并为一行，避免破坏 Markdown 列表
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
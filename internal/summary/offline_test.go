@@ -0,0 +1,68 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+	"humg.top/daily_summary/internal/timesheet"
+)
+
+// TestOfflineFallback 测试 AI 提供商都失败时由离线规则生成日报，并标注为离线生成
+func TestOfflineFallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewJSONStorage(filepath.Join(tmpDir, "data"), filepath.Join(tmpDir, "summaries"))
+
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 21, hour, minute, 0, 0, time.Local) }
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: at(10, 0), Content: "@billing 对账脚本开发 #dev"},
+		{Timestamp: at(11, 0), Content: "@billing 修复重复扣款，已合入。压测时接口报错，待排查"},
+		{Timestamp: at(15, 0), Content: "@search 索引优化 #dev\n明日：灰度发布"},
+	} {
+		entry.Tags, entry.Project = models.ParseEntryMeta(entry.Content)
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("SaveEntry failed: %v", err)
+		}
+	}
+
+	broken := &scriptedClient{results: []error{errors.New("codex: command not found")}}
+	chain, _ := newTestChain(t, []Provider{{Name: "codex", Client: broken}, {Name: OfflineProviderName, Client: NewOfflineClient()}}, RetryPolicy{})
+	g := NewGenerator(store, chain, nil)
+	g.SetTimeAccounting(timesheet.Options{DefaultWindow: time.Hour, SupplementTags: timesheet.DefaultSupplementTags})
+
+	if err := g.GenerateDailySummary(context.Background(), at(0, 0)); err != nil {
+		t.Fatalf("GenerateDailySummary failed: %v", err)
+	}
+	saved, err := store.GetSummary(at(0, 0))
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+
+	for _, want := range []string{
+		offlineNotice,
+		"### billing（2 条，2.0 小时）",
+		"- **15:00**（4 小时） @search 索引优化 #dev 明日：灰度发布",
+		"- 按标签：#dev 5.0 小时",
+		"- 11:00：@billing 修复重复扣款，已合入",
+		"- 11:00：压测时接口报错，待排查",
+		"## 明日计划\n\n- 15:00：明日：灰度发布",
+		"## 工时统计",
+	} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("Offline summary should contain %q:\n%s", want, saved)
+		}
+	}
+	if problems := (outputSpec{sections: DefaultDailySections}).validateMarkdown(saved); len(problems) > 0 {
+		t.Errorf("Offline summary should contain the default sections: %v", problems)
+	}
+
+	// 周报需要 AI 归纳，离线提供商不支持
+	if _, err := NewOfflineClient().GenerateSummary(context.Background(), "weekly prompt"); !errors.Is(err, ErrOfflineUnsupported) {
+		t.Errorf("Expected ErrOfflineUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package summary
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// 默认配置
+const (
+	DefaultOllamaHost       = "http://localhost:11434"
+	ollamaCheckTimeout      = 5 * time.Second
+	ollamaMaxResponseLength = 1024 * 1024 // 单行流式响应的最大长度
+)
+
+func init() {
+	RegisterProvider("ollama", func(cfg *models.Config, name string) (AIClient, error) {
+		host := cfg.OllamaHost
+		if host == "" {
+			host = os.Getenv("OLLAMA_HOST")
+		}
+		client, err := NewOllamaClient(OllamaConfig{
+			Host:        host,
+			Model:       cfg.OllamaModel,
+			Temperature: cfg.OllamaTemperature,
+			NumCtx:      cfg.OllamaNumCtx,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 启动时检查模型是否已下载，不可用时只记录警告：服务可能稍后启动，
+		// 生成时模型仍不可用会返回 ErrUnavailable，由 ai_providers 切换到下一个提供商
+		ctx, cancel := context.WithTimeout(context.Background(), ollamaCheckTimeout)
+		defer cancel()
+		if err := client.CheckModel(ctx); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return client, nil
+	})
+}
+
+// OllamaConfig Ollama 配置
+type OllamaConfig struct {
+	Host        string   // 服务地址（默认 http://localhost:11434）
+	Model       string   // 模型名称（如 qwen2.5:14b）
+	Temperature *float64 // 采样温度，为 nil 时使用模型默认值
+	NumCtx      int      // 上下文长度（token），0 表示使用模型默认值；周报 prompt 较长时需要调大
+}
+
+// OllamaClient 本地 Ollama 客户端（/api/chat），数据不离开本机
+type OllamaClient struct {
+	config     OllamaConfig
+	httpClient *http.Client
+}
+
+// NewOllamaClient 创建 Ollama 客户端
+func NewOllamaClient(config OllamaConfig) (*OllamaClient, error) {
+	if config.Model == "" {
+		return nil, errors.New("ollama model is required (set ollama_model in config)")
+	}
+	if config.Host == "" {
+		config.Host = DefaultOllamaHost
+	}
+	if !strings.Contains(config.Host, "://") {
+		config.Host = "http://" + config.Host
+	}
+	config.Host = strings.TrimRight(config.Host, "/")
+
+	return &OllamaClient{
+		config:     config,
+		httpClient: &http.Client{}, // 超时由调用方的 ctx 控制
+	}, nil
+}
+
+// ollamaTagsResponse /api/tags 响应体
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
+
+// CheckModel 检查服务是否可用以及模型是否已下载
+// 服务无法连接或模型未下载时返回 ErrUnavailable
+func (c *OllamaClient) CheckModel(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.Host+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: ollama is not reachable at %s: %v", ErrUnavailable, c.config.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama /api/tags returned %s", resp.Status)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("decode ollama models: %w", err)
+	}
+
+	for _, m := range tags.Models {
+		if ollamaModelMatches(m.Name, c.config.Model) || ollamaModelMatches(m.Model, c.config.Model) {
+			return nil
+		}
+	}
+	return c.modelNotAvailable()
+}
+
+// modelNotAvailable 返回模型未下载的错误（不会重试，直接切换到下一个提供商）
+func (c *OllamaClient) modelNotAvailable() error {
+	return fmt.Errorf("%w: ollama model %s is not available (run: ollama pull %s)", ErrUnavailable, c.config.Model, c.config.Model)
+}
+
+// ollamaModelMatches 比较模型名称，未指定标签时等同于 :latest
+func ollamaModelMatches(name, want string) bool {
+	if !strings.Contains(want, ":") {
+		want += ":latest"
+	}
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+	return name == want
+}
+
+// ollamaChatRequest /api/chat 请求体
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []chatMessage          `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaChatChunk /api/chat 流式响应中的一行
+type ollamaChatChunk struct {
+	Message    chatMessage `json:"message"`
+	Done       bool        `json:"done"`
+	DoneReason string      `json:"done_reason"`
+	Error      string      `json:"error"`
+}
+
+// GenerateSummary 调用 /api/chat 生成总结（流式读取，拼接完整结果）
+func (c *OllamaClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, nil)
+}
+
+// StreamSummary 流式生成总结，每收到一段内容时调用 onChunk（实现 StreamingClient）
+func (c *OllamaClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return c.generate(ctx, prompt, onChunk)
+}
+
+// generate 调用 /api/chat，每收到一段内容时调用 onChunk（可为 nil）
+func (c *OllamaClient) generate(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	request := ollamaChatRequest{
+		Model:    c.config.Model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+		Stream:   true,
+	}
+	options := make(map[string]interface{})
+	if c.config.Temperature != nil {
+		options["temperature"] = *c.config.Temperature
+	}
+	if c.config.NumCtx > 0 {
+		options["num_ctx"] = c.config.NumCtx
+	}
+	if len(options) > 0 {
+		request.Options = options
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := c.config.Host + "/api/chat"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("调用 Ollama: %s (model: %s)", url, c.config.Model)
+	log.Printf("Prompt 长度: %d 字符", len(prompt))
+	fmt.Printf("调用 %s 生成总结...\n", c.config.Model)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("request ollama: %w", ctx.Err())
+		}
+		return "", &TransientError{Err: fmt.Errorf("request ollama: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var chunk ollamaChatChunk
+		message := truncate(string(data), 200)
+		if json.Unmarshal(data, &chunk) == nil && chunk.Error != "" {
+			message = chunk.Error
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("%w: %s", c.modelNotAvailable(), message)
+		}
+		return "", statusError(resp.StatusCode, fmt.Errorf("ollama returned %s: %s", resp.Status, message))
+	}
+
+	var builder strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), ollamaMaxResponseLength)
+	done := false
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("unmarshal ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama error: %s", chunk.Error)
+		}
+
+		builder.WriteString(chunk.Message.Content)
+		if onChunk != nil && chunk.Message.Content != "" {
+			onChunk(chunk.Message.Content)
+		}
+		if chunk.Done {
+			done = true
+			if chunk.DoneReason == "length" {
+				log.Printf("Warning: ollama response was truncated (num_ctx: %d)", c.config.NumCtx)
+			}
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("read ollama response: %w", ctx.Err())
+		}
+		return "", &TransientError{Err: fmt.Errorf("read ollama response: %w", err)}
+	}
+	if !done {
+		return "", &TransientError{Err: errors.New("ollama response ended before completion")}
+	}
+
+	response := builder.String()
+	if strings.TrimSpace(response) == "" {
+		return "", errors.New("ollama returned an empty response")
+	}
+	log.Printf("✓ %s 响应成功，长度: %d 字符", c.config.Model, len(response))
+	fmt.Printf("✓ %s 响应成功 (长度: %d 字符)\n", c.config.Model, len(response))
+
+	return response, nil
+}
@@ -0,0 +1,145 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// newOllamaStub 创建模拟 Ollama 服务：/api/tags 返回已下载的模型，/api/chat 按行流式返回 chunks
+func newOllamaStub(t *testing.T, chunks []string, gotRequest *ollamaChatRequest) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models": [{"name": "qwen2.5:14b", "model": "qwen2.5:14b"}, {"name": "llama3:latest", "model": "llama3:latest"}]}`))
+		case "/api/chat":
+			var request ollamaChatRequest
+			json.NewDecoder(r.Body).Decode(&request)
+			if gotRequest != nil {
+				*gotRequest = request
+			}
+			if !ollamaModelMatches("qwen2.5:14b", request.Model) && !ollamaModelMatches("llama3", request.Model) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error": "model \"` + request.Model + `\" not found, try pulling it first"}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			for _, chunk := range chunks {
+				w.Write([]byte(chunk + "\n"))
+				w.(http.Flusher).Flush()
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestOllamaClientGenerateSummary 测试流式响应的拼接和请求参数
+func TestOllamaClientGenerateSummary(t *testing.T) {
+	var got ollamaChatRequest
+	server := newOllamaStub(t, []string{
+		`{"message": {"role": "assistant", "content": "## 今日"}, "done": false}`,
+		`{"message": {"role": "assistant", "content": "总结"}, "done": false}`,
+		`{"message": {"role": "assistant", "content": ""}, "done": true, "done_reason": "stop"}`,
+	}, &got)
+
+	temperature := 0.2
+	client, err := NewOllamaClient(OllamaConfig{Host: server.URL, Model: "qwen2.5:14b", Temperature: &temperature, NumCtx: 16384})
+	if err != nil {
+		t.Fatalf("NewOllamaClient failed: %v", err)
+	}
+
+	var streamed []string
+	result, err := client.generate(context.Background(), "总结今天的工作", func(s string) { streamed = append(streamed, s) })
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if result != "## 今日总结" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+	if len(streamed) != 2 {
+		t.Errorf("Expected 2 streamed chunks, got %v", streamed)
+	}
+
+	if got.Model != "qwen2.5:14b" || !got.Stream || len(got.Messages) != 1 || got.Messages[0].Content != "总结今天的工作" {
+		t.Errorf("Unexpected request: %+v", got)
+	}
+	if got.Options["temperature"] != 0.2 || got.Options["num_ctx"] != float64(16384) {
+		t.Errorf("Unexpected options: %v", got.Options)
+	}
+}
+
+// TestOllamaClientErrors 测试流中的错误和提前结束
+func TestOllamaClientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunks    []string
+		wantErr   string
+		transient bool
+	}{
+		{"error chunk", []string{`{"error": "model requires more system memory"}`}, "more system memory", false},
+		{"truncated stream", []string{`{"message": {"content": "部分"}, "done": false}`}, "ended before completion", true},
+		{"empty response", []string{`{"message": {"content": " "}, "done": true}`}, "empty response", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOllamaStub(t, tt.chunks, nil)
+			client, _ := NewOllamaClient(OllamaConfig{Host: server.URL, Model: "llama3"})
+
+			_, err := client.GenerateSummary(context.Background(), "prompt")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient() = %v, want %v", IsTransient(err), tt.transient)
+			}
+		})
+	}
+}
+
+// TestOllamaCheckModel 测试启动时的模型可用性检查
+func TestOllamaCheckModel(t *testing.T) {
+	server := newOllamaStub(t, nil, nil)
+
+	for model, wantOK := range map[string]bool{"qwen2.5:14b": true, "llama3": true, "mistral": false} {
+		client, _ := NewOllamaClient(OllamaConfig{Host: server.URL, Model: model})
+		err := client.CheckModel(context.Background())
+		if (err == nil) != wantOK {
+			t.Errorf("CheckModel(%s) = %v, want ok=%v", model, err, wantOK)
+		}
+		if err != nil && !strings.Contains(err.Error(), "ollama pull "+model) {
+			t.Errorf("Error should suggest ollama pull, got %v", err)
+		}
+	}
+
+	// 缺少模型时仍可创建提供商（只记录警告），生成时返回 ErrUnavailable，不重试，由提供商链切换到下一个
+	cfg := &models.Config{OllamaHost: server.URL, OllamaModel: "mistral"}
+	provider, err := NewProvider(cfg, "ollama")
+	if err != nil {
+		t.Fatalf("NewProvider should succeed when the model is not pulled: %v", err)
+	}
+	_, err = provider.GenerateSummary(context.Background(), "prompt")
+	if !errors.Is(err, ErrUnavailable) || IsTransient(err) || !strings.Contains(err.Error(), "ollama pull mistral") {
+		t.Errorf("Expected non-transient ErrUnavailable for missing model, got %v", err)
+	}
+
+	// 服务未启动时返回 ErrUnavailable，提供商仍可创建（服务可能稍后启动）
+	server.Close()
+	client, _ := NewOllamaClient(OllamaConfig{Host: server.URL, Model: "llama3"})
+	if err := client.CheckModel(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+	cfg.OllamaModel = "llama3"
+	if _, err := NewProvider(cfg, "ollama"); err != nil {
+		t.Errorf("NewProvider should succeed when ollama is not running: %v", err)
+	}
+}
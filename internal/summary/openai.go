@@ -0,0 +1,270 @@
+package summary
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// 默认配置
+const (
+	DefaultOpenAIBaseURL   = "https://api.openai.com/v1"
+	DefaultOpenAIAPIKeyEnv = "OPENAI_API_KEY"
+)
+
+func init() {
+	RegisterProvider("openai", func(cfg *models.Config, name string) (AIClient, error) {
+		keyEnv := cfg.OpenAIAPIKeyEnv
+		if keyEnv == "" {
+			keyEnv = DefaultOpenAIAPIKeyEnv
+		}
+		return NewOpenAIClient(OpenAIConfig{
+			BaseURL:     cfg.OpenAIBaseURL,
+			Model:       cfg.OpenAIModel,
+			APIKey:      os.Getenv(keyEnv),
+			Temperature: cfg.OpenAITemperature,
+			MaxTokens:   cfg.OpenAIMaxTokens,
+		})
+	})
+}
+
+// OpenAIConfig OpenAI 兼容接口配置
+type OpenAIConfig struct {
+	BaseURL     string   // 接口地址（包含 /v1，如 http://localhost:11434/v1）
+	Model       string   // 模型名称
+	APIKey      string   // API Key（本地模型服务可为空）
+	Temperature *float64 // 采样温度，为 nil 时使用服务端默认值
+	MaxTokens   int      // 最大生成 token 数，0 表示使用服务端默认值
+}
+
+// OpenAIClient OpenAI 兼容的 HTTP 客户端（/v1/chat/completions）
+// 可用于 OpenAI、本地模型服务或公司内部网关，不依赖任何 CLI
+type OpenAIClient struct {
+	config     OpenAIConfig
+	httpClient *http.Client
+}
+
+// NewOpenAIClient 创建 OpenAI 兼容客户端
+func NewOpenAIClient(config OpenAIConfig) (*OpenAIClient, error) {
+	if config.Model == "" {
+		return nil, errors.New("openai model is required (set openai_model in config)")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultOpenAIBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &OpenAIClient{
+		config:     config,
+		httpClient: &http.Client{}, // 超时由调用方的 ctx 控制
+	}, nil
+}
+
+// chatMessage 对话消息
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest /chat/completions 请求体
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+
+// chatResponse /chat/completions 响应体（只解析需要的字段）
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Error *apiError `json:"error"`
+}
+
+// chatStreamChunk 流式响应中的一个事件（data: {...}）
+type chatStreamChunk struct {
+	Choices []struct {
+		Delta        chatMessage `json:"delta"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Error *apiError `json:"error"`
+}
+
+// apiError 接口返回的错误信息
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// GenerateSummary 调用 chat completions 接口生成总结
+func (c *OpenAIClient) GenerateSummary(ctx context.Context, prompt string) (string, error) {
+	return c.generate(ctx, prompt, nil)
+}
+
+// StreamSummary 以流式方式（stream: true）调用 chat completions 接口（实现 StreamingClient）
+func (c *OpenAIClient) StreamSummary(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	return c.generate(ctx, prompt, onChunk)
+}
+
+// generate 调用 chat completions 接口，onChunk 不为 nil 时使用流式响应
+func (c *OpenAIClient) generate(ctx context.Context, prompt string, onChunk func(string)) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:       c.config.Model,
+		Messages:    []chatMessage{{Role: "user", Content: prompt}},
+		Temperature: c.config.Temperature,
+		MaxTokens:   c.config.MaxTokens,
+		Stream:      onChunk != nil,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := c.config.BaseURL + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	log.Printf("调用 OpenAI 兼容接口: %s (model: %s)", url, c.config.Model)
+	log.Printf("Prompt 长度: %d 字符", len(prompt))
+	fmt.Printf("调用 %s 生成总结...\n", c.config.Model)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("request chat completions: %w", ctx.Err())
+		}
+		// 连接失败等网络错误可以重试
+		return "", &TransientError{Err: fmt.Errorf("request chat completions: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if onChunk != nil && resp.StatusCode == http.StatusOK {
+		return c.readStream(ctx, resp.Body, onChunk)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	var result chatResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", statusError(resp.StatusCode, fmt.Errorf("chat completions returned %s: %s", resp.Status, truncate(string(data), 200)))
+		}
+		return "", fmt.Errorf("unmarshal response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil && result.Error.Message != "" {
+			return "", statusError(resp.StatusCode, fmt.Errorf("chat completions returned %s: %s", resp.Status, result.Error.Message))
+		}
+		return "", statusError(resp.StatusCode, fmt.Errorf("chat completions returned %s", resp.Status))
+	}
+
+	if len(result.Choices) == 0 || strings.TrimSpace(result.Choices[0].Message.Content) == "" {
+		return "", errors.New("chat completions returned an empty response")
+	}
+	if result.Choices[0].FinishReason == "length" {
+		log.Printf("Warning: response was truncated by max_tokens (%d)", c.config.MaxTokens)
+	}
+
+	response := result.Choices[0].Message.Content
+	log.Printf("✓ %s 响应成功，长度: %d 字符", c.config.Model, len(response))
+	fmt.Printf("✓ %s 响应成功 (长度: %d 字符)\n", c.config.Model, len(response))
+
+	return response, nil
+}
+
+// readStream 读取 server-sent events 格式的流式响应，拼接完整结果
+func (c *OpenAIClient) readStream(ctx context.Context, body io.Reader, onChunk func(string)) (string, error) {
+	var builder strings.Builder
+	var finishReason string
+	done := false
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue // 空行、注释和 event: 行
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk chatStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("unmarshal stream event: %w", err)
+		}
+		if chunk.Error != nil && chunk.Error.Message != "" {
+			return "", fmt.Errorf("chat completions stream error: %s", chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if content := chunk.Choices[0].Delta.Content; content != "" {
+			builder.WriteString(content)
+			onChunk(content)
+		}
+		if chunk.Choices[0].FinishReason != "" {
+			finishReason = chunk.Choices[0].FinishReason
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("read stream: %w", ctx.Err())
+		}
+		return "", &TransientError{Err: fmt.Errorf("read stream: %w", err)}
+	}
+	if !done && finishReason == "" {
+		return "", &TransientError{Err: errors.New("chat completions stream ended before completion")}
+	}
+
+	response := builder.String()
+	if strings.TrimSpace(response) == "" {
+		return "", errors.New("chat completions returned an empty response")
+	}
+	if finishReason == "length" {
+		log.Printf("Warning: response was truncated by max_tokens (%d)", c.config.MaxTokens)
+	}
+	log.Printf("✓ %s 响应成功，长度: %d 字符", c.config.Model, len(response))
+
+	return response, nil
+}
+
+// statusError 限流（429）和服务端错误（5xx）标记为可重试
+func statusError(code int, err error) error {
+	if code == http.StatusTooManyRequests || code >= http.StatusInternalServerError {
+		return &TransientError{Err: err}
+	}
+	return err
+}
+
+// truncate 截断过长的文本（用于错误信息）
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "..."
+}
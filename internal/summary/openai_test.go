@@ -0,0 +1,157 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOpenAIClientGenerateSummary 测试请求格式和响应解析
+func TestOpenAIClientGenerateSummary(t *testing.T) {
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("Unexpected Authorization header: %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "## 主要完成的任务\n- 对账"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	temperature := 0.2
+	client, err := NewOpenAIClient(OpenAIConfig{
+		BaseURL:     server.URL + "/v1/",
+		Model:       "test-model",
+		APIKey:      "test-key",
+		Temperature: &temperature,
+		MaxTokens:   512,
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient failed: %v", err)
+	}
+
+	result, err := client.GenerateSummary(context.Background(), "总结今天的工作")
+	if err != nil {
+		t.Fatalf("GenerateSummary failed: %v", err)
+	}
+	if result != "## 主要完成的任务\n- 对账" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+
+	if got.Model != "test-model" || got.MaxTokens != 512 || got.Temperature == nil || *got.Temperature != 0.2 {
+		t.Errorf("Unexpected request body: %+v", got)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content != "总结今天的工作" {
+		t.Errorf("Unexpected messages: %+v", got.Messages)
+	}
+}
+
+// TestOpenAIClientOmitsOptionalFields 测试未配置的可选参数和 API Key 不会发送
+func TestOpenAIClientOmitsOptionalFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %q", auth)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["temperature"]; ok {
+			t.Error("temperature should be omitted")
+		}
+		if _, ok := body["max_tokens"]; ok {
+			t.Error("max_tokens should be omitted")
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "ok"}}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewOpenAIClient(OpenAIConfig{BaseURL: server.URL, Model: "local"})
+	if _, err := client.GenerateSummary(context.Background(), "prompt"); err != nil {
+		t.Fatalf("GenerateSummary failed: %v", err)
+	}
+}
+
+// TestOpenAIClientErrors 测试错误响应
+func TestOpenAIClientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   string
+		transient bool
+	}{
+		{"api error", http.StatusUnauthorized, `{"error": {"message": "invalid api key", "type": "auth"}}`, "invalid api key", false},
+		{"rate limited", http.StatusTooManyRequests, `{"error": {"message": "rate limit reached", "type": "requests"}}`, "rate limit reached", true},
+		{"non-json error", http.StatusBadGateway, `upstream unavailable`, "upstream unavailable", true},
+		{"empty choices", http.StatusOK, `{"choices": []}`, "empty response", false},
+		{"invalid json", http.StatusOK, `not json`, "unmarshal response", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewOpenAIClient(OpenAIConfig{BaseURL: server.URL, Model: "test-model"})
+			_, err := client.GenerateSummary(context.Background(), "prompt")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient() = %v, want %v", IsTransient(err), tt.transient)
+			}
+		})
+	}
+
+	if _, err := NewOpenAIClient(OpenAIConfig{}); err == nil {
+		t.Error("Expected error when model is not configured")
+	}
+}
+
+// TestOpenAIClientStream 测试 server-sent events 流式响应
+func TestOpenAIClientStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("Expected stream: true in request")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"choices": [{"delta": {"role": "assistant"}}]}`,
+			`{"choices": [{"delta": {"content": "## 今日"}}]}`,
+			`{"choices": [{"delta": {"content": "总结"}, "finish_reason": "stop"}]}`,
+			`[DONE]`,
+		} {
+			w.Write([]byte("data: " + event + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewOpenAIClient(OpenAIConfig{BaseURL: server.URL, Model: "test-model"})
+
+	var chunks []string
+	result, err := client.StreamSummary(context.Background(), "prompt", func(s string) { chunks = append(chunks, s) })
+	if err != nil {
+		t.Fatalf("StreamSummary failed: %v", err)
+	}
+	if result != "## 今日总结" {
+		t.Errorf("Unexpected result: %q", result)
+	}
+	if strings.Join(chunks, "|") != "## 今日|总结" {
+		t.Errorf("Unexpected chunks: %v", chunks)
+	}
+}
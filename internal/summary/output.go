@@ -0,0 +1,230 @@
+package summary
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidOutput AI 输出未通过格式校验（重新生成后仍不合格），不会保存
+var ErrInvalidOutput = errors.New("invalid AI output")
+
+// DefaultDailySections 每日总结必须包含的章节标题（与 summary_prompt.md 及降级提示词一致）
+var DefaultDailySections = []string{"主要完成的任务", "关键进展", "遇到的问题", "明日计划"}
+
+// DefaultWeeklySections 周报必须包含的章节标题（与 weekly_summary_prompt.md 及降级提示词一致）
+var DefaultWeeklySections = []string{"本周完成情况", "关键进展", "遇到的问题", "下周计划"}
+
+// DefaultMonthlySections 月报必须包含的章节标题（与 monthly_summary_prompt.md 及降级提示词一致）
+var DefaultMonthlySections = []string{"本月完成情况", "关键进展", "遇到的问题", "下月计划"}
+
+// outputSpec AI 输出的期望格式
+type outputSpec struct {
+	html     bool     // 期望完整的 HTML 文档（周报模板），否则为 Markdown
+	sections []string // 必须出现在标题中的章节名
+}
+
+// expectsHTML 判断提示词是否要求输出 HTML 文档（周报模板要求，降级提示词要求 Markdown）
+func expectsHTML(prompt string) bool {
+	return strings.Contains(strings.ToLower(prompt), "<!doctype html>")
+}
+
+// process 清理并校验 AI 输出，返回清理后的内容和未通过的校验项
+func (s outputSpec) process(output string) (string, []string) {
+	if s.html {
+		output = normalizeHTML(output)
+		return output, s.validateHTML(output)
+	}
+	output = normalizeMarkdown(output)
+	return output, s.validateMarkdown(output)
+}
+
+// normalizeMarkdown 去掉包裹整个输出的 ```markdown 代码块和第一个标题之前的开场白
+func normalizeMarkdown(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	// 包裹整个总结的代码块：第一个代码块出现在任何标题之前，且语言为空或 markdown
+	// 总结内部的 ```mermaid 等代码块不受影响
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if isMarkdownHeading(line) {
+			break
+		}
+		if !strings.HasPrefix(line, "```") {
+			continue
+		}
+		if lang := strings.ToLower(strings.TrimPrefix(line, "```")); lang != "" && lang != "markdown" && lang != "md" {
+			break
+		}
+		for j := len(lines) - 1; j > i; j-- {
+			if strings.TrimSpace(lines[j]) == "```" {
+				lines = lines[i+1 : j]
+				break
+			}
+		}
+		break
+	}
+
+	for i, line := range lines {
+		if isMarkdownHeading(strings.TrimSpace(line)) {
+			lines = lines[i:]
+			break
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// normalizeHTML 截取 <!DOCTYPE html>（或 <html>）到 </html> 之间的内容，去掉代码块标记和前后的说明文字
+func normalizeHTML(output string) string {
+	lower := strings.ToLower(output)
+	start := strings.Index(lower, "<!doctype html")
+	if start < 0 {
+		start = strings.Index(lower, "<html")
+	}
+	if start < 0 {
+		return strings.TrimSpace(output)
+	}
+
+	end := len(output)
+	if i := strings.LastIndex(lower, "</html>"); i > start {
+		end = i + len("</html>")
+	}
+	return strings.TrimSpace(output[start:end])
+}
+
+// validateMarkdown 检查必需章节是否都以标题形式出现
+func (s outputSpec) validateMarkdown(output string) []string {
+	var headings []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); isMarkdownHeading(line) {
+			headings = append(headings, line)
+		}
+	}
+	if len(headings) == 0 {
+		return []string{"output is not Markdown (no headings found)"}
+	}
+	return missingSections(s.sections, headings)
+}
+
+// htmlHeadingPattern 匹配 <h1>~<h6> 标题
+var htmlHeadingPattern = regexp.MustCompile(`(?is)<h[1-6][^>]*>(.*?)</h[1-6]>`)
+
+// validateHTML 检查输出是否为完整、标签闭合的 HTML 文档，且包含必需章节
+func (s outputSpec) validateHTML(output string) []string {
+	lower := strings.ToLower(output)
+	if !strings.HasPrefix(lower, "<!doctype html") && !strings.HasPrefix(lower, "<html") {
+		return []string{"output is not an HTML document (expected it to start with <!DOCTYPE html>)"}
+	}
+
+	var problems []string
+	if !strings.HasSuffix(lower, "</html>") {
+		problems = append(problems, "HTML document is truncated (missing </html>)")
+	}
+	problems = append(problems, checkHTMLTags(output)...)
+
+	var headings []string
+	for _, match := range htmlHeadingPattern.FindAllStringSubmatch(output, -1) {
+		headings = append(headings, match[1])
+	}
+	return append(problems, missingSections(s.sections, headings)...)
+}
+
+// missingSections 返回没有出现在任何标题中的必需章节
+func missingSections(sections, headings []string) []string {
+	var problems []string
+	for _, section := range sections {
+		found := false
+		for _, heading := range headings {
+			if strings.Contains(heading, section) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("missing required section %q", section))
+		}
+	}
+	return problems
+}
+
+// isMarkdownHeading 判断一行是否为 Markdown 标题（# 开头且后跟空格）
+func isMarkdownHeading(line string) bool {
+	trimmed := strings.TrimLeft(line, "#")
+	return len(trimmed) < len(line) && len(line)-len(trimmed) <= 6 && strings.HasPrefix(trimmed, " ")
+}
+
+var (
+	// htmlRawTextPattern 匹配注释和 <style>/<script> 块，其中的内容不参与标签检查
+	htmlRawTextPattern = regexp.MustCompile(`(?is)<!--.*?-->|<style\b[^>]*>.*?</style>|<script\b[^>]*>.*?</script>`)
+	// htmlTagPattern 匹配开始和结束标签
+	htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)\b[^>]*?(/?)>`)
+)
+
+// htmlVoidElements 没有结束标签的元素
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlOptionalEndElements 允许省略结束标签的元素
+var htmlOptionalEndElements = map[string]bool{
+	"p": true, "li": true, "dt": true, "dd": true, "option": true,
+	"thead": true, "tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+}
+
+// maxTagProblems 最多报告的标签问题数，避免一个未闭合标签引发的连锁错误淹没提示
+const maxTagProblems = 5
+
+// checkHTMLTags 检查标签是否正确嵌套和闭合
+func checkHTMLTags(doc string) []string {
+	doc = htmlRawTextPattern.ReplaceAllString(doc, "")
+
+	var problems []string
+	var stack []string
+	for _, match := range htmlTagPattern.FindAllStringSubmatch(doc, -1) {
+		closing, name, selfClosing := match[1] == "/", strings.ToLower(match[2]), match[3] == "/"
+		if htmlVoidElements[name] || selfClosing {
+			continue
+		}
+		if !closing {
+			stack = append(stack, name)
+			continue
+		}
+
+		// 结束标签：弹出之间允许省略结束标签的元素，遇到其他元素说明嵌套错误
+		i := len(stack) - 1
+		for i >= 0 && stack[i] != name && htmlOptionalEndElements[stack[i]] {
+			i--
+		}
+		if i < 0 || stack[i] != name {
+			problems = append(problems, fmt.Sprintf("unexpected </%s> tag", name))
+			continue
+		}
+		stack = stack[:i]
+	}
+
+	for _, name := range stack {
+		if !htmlOptionalEndElements[name] {
+			problems = append(problems, fmt.Sprintf("unclosed <%s> tag", name))
+		}
+	}
+
+	if len(problems) > maxTagProblems {
+		problems = append(problems[:maxTagProblems], fmt.Sprintf("and %d more tag problems", len(problems)-maxTagProblems))
+	}
+	return problems
+}
+
+// repairPrompt 在原提示词后附上校验问题，要求 AI 重新生成
+func repairPrompt(prompt string, problems []string) string {
+	var builder strings.Builder
+	builder.WriteString(prompt)
+	builder.WriteString("\n\n---\n\n## 上一次输出未通过格式校验\n\n")
+	for _, problem := range problems {
+		builder.WriteString("- " + problem + "\n")
+	}
+	builder.WriteString("\n请修正以上问题，重新输出完整内容。仍然严格遵守上面的输出要求，不要添加任何解释性文字或代码块标记。\n")
+	return builder.String()
+}
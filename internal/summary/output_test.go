@@ -0,0 +1,157 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const validDaily = `## 主要完成的任务
+- 对账（1.5 小时）
+
+` + "```mermaid\npie title 工作耗时分布\n    \"对账\" : 1.5\n```" + `
+
+## 关键进展
+- 完成对账
+
+## 遇到的问题
+- 无
+
+## 明日计划
+- 上线`
+
+// TestNormalizeMarkdown 测试去掉包裹的代码块和开场白，保留总结内部的代码块
+func TestNormalizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"clean", validDaily},
+		{"preamble", "好的，以下是今天的工作总结：\n\n" + validDaily},
+		{"fenced", "```markdown\n" + validDaily + "\n```"},
+		{"preamble and bare fence", "以下是总结：\n```\n" + validDaily + "\n```\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeMarkdown(tt.output); got != validDaily {
+				t.Errorf("normalizeMarkdown() =\n%s\nwant:\n%s", got, validDaily)
+			}
+		})
+	}
+}
+
+// TestValidateMarkdown 测试必需章节检查
+func TestValidateMarkdown(t *testing.T) {
+	spec := outputSpec{sections: DefaultDailySections}
+
+	if _, problems := spec.process(validDaily); len(problems) != 0 {
+		t.Errorf("Expected valid summary, got problems: %v", problems)
+	}
+
+	_, problems := spec.process(strings.Replace(validDaily, "## 明日计划", "明日计划：", 1))
+	if len(problems) != 1 || !strings.Contains(problems[0], "明日计划") {
+		t.Errorf("Expected missing 明日计划, got %v", problems)
+	}
+
+	if _, problems := spec.process("抱歉，我无法完成这个任务。"); len(problems) != 1 || !strings.Contains(problems[0], "no headings") {
+		t.Errorf("Expected no headings problem, got %v", problems)
+	}
+}
+
+const validWeekly = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        .pie-chart { background: conic-gradient(#667eea 0deg 360deg); }
+    </style>
+</head>
+<body>
+    <!-- 头部 -->
+    <div class="container">
+        <div class="section"><h2>1. 本周完成情况</h2><ul><li>对账<li>上线</ul></div>
+        <div class="section"><h2>3. 关键进展与成果</h2><p>完成对账<br></div>
+        <div class="section"><h2>4. 遇到的问题与解决方案</h2></div>
+        <div class="section"><h2>5. 下周计划</h2><ol><li><strong>P0</strong></li></ol></div>
+    </div>
+</body>
+</html>`
+
+// TestProcessHTML 测试周报 HTML 的清理和结构检查
+func TestProcessHTML(t *testing.T) {
+	spec := outputSpec{html: true, sections: DefaultWeeklySections}
+
+	output, problems := spec.process("好的，以下是周报：\n```html\n" + validWeekly + "\n```\n希望对你有帮助！")
+	if output != validWeekly {
+		t.Errorf("Fences and surrounding text not stripped:\n%s", output)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected valid HTML, got problems: %v", problems)
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{"markdown", "## 本周完成情况\n- 对账", "not an HTML document"},
+		{"truncated", validWeekly[:strings.Index(validWeekly, "<div class=\"section\"><h2>5.")], "missing </html>"},
+		{"unclosed div", strings.Replace(validWeekly, "</h2></div>", "</h2>", 1), "unclosed <div>"},
+		{"stray close", strings.Replace(validWeekly, "<strong>P0</strong>", "<strong>P0</em></strong>", 1), "unexpected </em>"},
+		{"missing section", strings.Replace(validWeekly, "5. 下周计划", "5. 其他", 1), "下周计划"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := spec.process(tt.output)
+			if !strings.Contains(strings.Join(problems, "; "), tt.wantErr) {
+				t.Errorf("Expected problem containing %q, got %v", tt.wantErr, problems)
+			}
+		})
+	}
+}
+
+// TestGenerateRepairsInvalidOutput 测试校验失败后附上问题重新生成一次，仍失败时返回 ErrInvalidOutput
+func TestGenerateRepairsInvalidOutput(t *testing.T) {
+	var prompts []string
+	outputs := []string{"好的！\n## 主要完成的任务\n- 对账", "```markdown\n" + validDaily + "\n```"}
+	client := aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return outputs[len(prompts)-1], nil
+	})
+
+	var stream bytes.Buffer
+	g := &Generator{aiClient: client, outputRetries: 1, stream: &stream}
+	summary, _, err := g.generate(context.Background(), "总结今天的工作", g.dailyOutputSpec())
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if summary != validDaily {
+		t.Errorf("Unexpected summary:\n%s", summary)
+	}
+	if len(prompts) != 2 || !strings.HasPrefix(prompts[1], "总结今天的工作") || !strings.Contains(prompts[1], `missing required section "明日计划"`) {
+		t.Errorf("Unexpected repair prompt: %q", prompts)
+	}
+	if !strings.Contains(stream.String(), "输出未通过格式校验") {
+		t.Errorf("Expected repair notice in streamed output, got %q", stream.String())
+	}
+
+	prompts = nil
+	outputs = []string{"## 随便写写", "## 还是随便写写"}
+	g = &Generator{aiClient: client, outputRetries: 1}
+	if _, _, err := g.generate(context.Background(), "prompt", g.dailyOutputSpec()); !errors.Is(err, ErrInvalidOutput) {
+		t.Errorf("Expected ErrInvalidOutput, got %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(prompts))
+	}
+
+	prompts = nil
+	g.SetRequiredSections([]string{}, nil, nil)
+	if _, _, err := g.generate(context.Background(), "prompt", g.dailyOutputSpec()); err != nil {
+		t.Errorf("Expected section check to be disabled, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package summary
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// PromptPreview 将要发送给 AI 的提示词
+type PromptPreview struct {
+	Prompt     string // 完整提示词
+	Template   string // 实际使用的模板：文件路径、embedded:文件名，或 FallbackPromptSource（硬编码降级提示词）
+	StartDate  time.Time
+	EndDate    time.Time
+	EntryCount int         // 日报为参与总结的记录条数，周报为有内容的天数
+	Data       *PromptData // 日报的模板数据，包含工时表（周报为 nil）
+}
+
+// BuildDailyPrompt 构建生成日报时会发送给 AI 的提示词（filter 非空时为按条件过滤的局部总结），不调用 AI 也不保存
+func (g *Generator) BuildDailyPrompt(date time.Time, filter models.EntryFilter) (*PromptPreview, error) {
+	dailyData, err := g.storage.GetDailyData(date)
+	if err != nil {
+		return nil, fmt.Errorf("get daily data: %w", err)
+	}
+
+	entryCount := len(filter.Apply(dailyData.Entries))
+	if entryCount == 0 {
+		if filter.IsEmpty() {
+			return nil, fmt.Errorf("no work entries for date %s", date.Format("2006-01-02"))
+		}
+		return nil, fmt.Errorf("no work entries matching %s for date %s", filter.String(), date.Format("2006-01-02"))
+	}
+
+	prompt, source, data := g.renderDailyPrompt(dailyData, filter)
+	return &PromptPreview{
+		Prompt:     prompt,
+		Template:   source,
+		StartDate:  date,
+		EndDate:    date,
+		EntryCount: entryCount,
+		Data:       &data,
+	}, nil
+}
+
+// BuildWeeklyPrompt 构建生成周报时会发送给 AI 的提示词，不调用 AI 也不保存
+// filter 为空时基于每日总结，否则基于每天过滤后的原始记录（与 GenerateFilteredWeeklySummary 相同）
+func (g *Generator) BuildWeeklyPrompt(weekEndDate time.Time, filter models.EntryFilter) (*PromptPreview, error) {
+	weekStartDate := weekEndDate.AddDate(0, 0, -6)
+
+	var dailyContents map[string]string
+	if filter.IsEmpty() {
+		dailySummaries, err := g.storage.GetDailySummariesInRange(weekStartDate, weekEndDate)
+		if err != nil {
+			return nil, fmt.Errorf("get daily summaries: %w", err)
+		}
+		if len(dailySummaries) == 0 {
+			return nil, fmt.Errorf("no daily summaries found for week %s to %s",
+				weekStartDate.Format("2006-01-02"),
+				weekEndDate.Format("2006-01-02"))
+		}
+		log.Printf("Found %d daily summaries for the week", len(dailySummaries))
+		dailyContents = dailySummaries
+	} else {
+		dailyContents = g.filteredDailyEntries(weekStartDate, weekEndDate, filter)
+		if len(dailyContents) == 0 {
+			return nil, fmt.Errorf("no work entries matching %s for week %s to %s",
+				filter.String(),
+				weekStartDate.Format("2006-01-02"),
+				weekEndDate.Format("2006-01-02"))
+		}
+	}
+
+	prompt, source := g.renderWeeklyPrompt(weekStartDate, weekEndDate, dailyContents, filter)
+	return &PromptPreview{
+		Prompt:     prompt,
+		Template:   source,
+		StartDate:  weekStartDate,
+		EndDate:    weekEndDate,
+		EntryCount: len(dailyContents),
+	}, nil
+}
+
+// filteredDailyEntries 返回日期范围内每天满足过滤条件的原始记录（Markdown 列表），没有匹配记录的日期不包含在内
+func (g *Generator) filteredDailyEntries(startDate, endDate time.Time, filter models.EntryFilter) map[string]string {
+	dailyEntries := make(map[string]string)
+	for current := startDate; !current.After(endDate); current = current.AddDate(0, 0, 1) {
+		dailyData, err := g.storage.GetDailyData(current)
+		if err != nil {
+			log.Printf("Warning: failed to get daily data for %s: %v", current.Format("2006-01-02"), err)
+			continue
+		}
+
+		entries := filter.Apply(dailyData.Entries)
+		if len(entries) == 0 {
+			continue
+		}
+
+		var builder strings.Builder
+		for _, entry := range entries {
+			builder.WriteString(fmt.Sprintf("- **%s**: %s\n", entry.Timestamp.Format("15:04"), entry.Content))
+		}
+		dailyEntries[dailyData.Date] = builder.String()
+	}
+	return dailyEntries
+}
@@ -0,0 +1,109 @@
+package summary
+
+import (
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/timesheet"
+)
+
+// previousSummaryLookback 查找之前最近一篇日报时最多往前找的天数
+const previousSummaryLookback = 7
+
+// entryWindows 按 g.timeOptions 计算当天每条记录覆盖的时间窗口，key 为 entryKey
+// 应传入当天全部记录，过滤不影响每条记录的时间窗口
+func (g *Generator) entryWindows(entries []models.WorkEntry) map[string]timesheet.Window {
+	windows := make(map[string]timesheet.Window, len(entries))
+	for _, window := range timesheet.Windows(entries, g.timeOptions) {
+		windows[entryKey(window.Entry)] = window
+	}
+	return windows
+}
+
+// timeTable 按 g.timeOptions 计算当天满足过滤条件的记录按项目或标签汇总的工时表
+func (g *Generator) timeTable(entries []models.WorkEntry, filter models.EntryFilter, by string) timesheet.Table {
+	var matched []timesheet.Window
+	for _, window := range timesheet.Windows(entries, g.timeOptions) {
+		if filter.Match(window.Entry) {
+			matched = append(matched, window)
+		}
+	}
+	return timesheet.Aggregate(matched, by, g.timeOptions.SupplementTags)
+}
+
+// appendTimeTable 在总结末尾附上程序计算的工时表，保存的耗时数据不依赖 AI 的估算
+func appendTimeTable(summary string, table *timesheet.Table) string {
+	if table == nil || table.Total == 0 {
+		return summary
+	}
+	return strings.TrimRight(summary, "\n") +
+		"\n\n## 工时统计\n\n> 根据相邻记录的时间自动计算：扣除休息时段，补充记录不计时。\n\n" +
+		table.Markdown()
+}
+
+// entryKey 返回记录的唯一标识（没有 ID 的记录使用基于时间和内容的确定性 ID）
+func entryKey(entry models.WorkEntry) string {
+	if entry.ID != "" {
+		return entry.ID
+	}
+	return models.LegacyEntryID(entry)
+}
+
+// totalDuration 返回记录时长之和
+func totalDuration(entries []PromptEntry) time.Duration {
+	var total time.Duration
+	for _, entry := range entries {
+		total += entry.Duration
+	}
+	return total
+}
+
+// dayPromptEntries 返回指定日期满足过滤条件的记录（含时长），读取失败时返回 nil
+func (g *Generator) dayPromptEntries(date time.Time, filter models.EntryFilter) []PromptEntry {
+	if g.storage == nil {
+		return nil
+	}
+	dailyData, err := g.storage.GetDailyData(date)
+	if err != nil {
+		return nil
+	}
+	return toPromptEntries(filter.Apply(dailyData.Entries), g.entryWindows(dailyData.Entries))
+}
+
+// previousDailySummary 返回 date 之前最近一篇日报的日期和正文（最多往前找 previousSummaryLookback 天）
+func (g *Generator) previousDailySummary(date time.Time) (string, string) {
+	if g.storage == nil {
+		return "", ""
+	}
+	for i := 1; i <= previousSummaryLookback; i++ {
+		previous := date.AddDate(0, 0, -i)
+		if content, err := g.storage.GetSummary(previous); err == nil && strings.TrimSpace(content) != "" {
+			return previous.Format("2006-01-02"), stripSummaryHeader(content)
+		}
+	}
+	return "", ""
+}
+
+// previousWeeklySummary 返回上一周的周报（已转换为纯文本），没有时为空
+func (g *Generator) previousWeeklySummary(weekEndDate time.Time) string {
+	if g.storage == nil {
+		return ""
+	}
+	previous := weekEndDate.AddDate(0, 0, -7)
+	summaries, err := g.storage.GetWeeklySummariesInRange(previous, previous)
+	if err != nil {
+		return ""
+	}
+	return summaryText(summaries[previous.Format("2006-01-02")])
+}
+
+// stripSummaryHeader 去掉日报文件开头的标题和元数据（第一个 --- 分隔线之前的内容）
+func stripSummaryHeader(content string) string {
+	if strings.HasPrefix(content, "# ") {
+		if i := strings.Index(content, "\n---\n"); i >= 0 {
+			content = content[i+len("\n---\n"):]
+		}
+	}
+	return strings.TrimSpace(content)
+}
@@ -0,0 +1,91 @@
+package summary
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PromptMode CLI 客户端传递提示词的方式
+type PromptMode string
+
+const (
+	// PromptModeArg 作为命令行参数传递（提示词过长时可能超过 ARG_MAX，且可被其他用户通过 ps 看到）
+	PromptModeArg PromptMode = "arg"
+	// PromptModeStdin 通过标准输入传递
+	PromptModeStdin PromptMode = "stdin"
+	// PromptModeFile 写入仅当前用户可读的临时文件，命令行只传递读取该文件的简短指令
+	// 依赖 CLI 中的 agent 主动读取文件，临时文件放在 CLI 的工作目录下，避免访问工作目录外的文件需要授权
+	PromptModeFile PromptMode = "file"
+)
+
+// ParsePromptMode 解析提示词传递方式，空字符串返回 def
+func ParsePromptMode(value string, def PromptMode) (PromptMode, error) {
+	switch mode := PromptMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return def, nil
+	case PromptModeArg, PromptModeStdin, PromptModeFile:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown prompt mode: %s (supported: arg, stdin, file)", value)
+	}
+}
+
+// promptDelivery 一次调用中提示词的传递方式
+type promptDelivery struct {
+	mode PromptMode
+	path string // file 模式下的临时文件
+}
+
+// newPromptDelivery 准备提示词的传递；file 模式会创建临时文件，调用方需在命令结束后调用 cleanup
+// dir 为临时文件所在目录，为空时使用系统临时目录
+func newPromptDelivery(mode PromptMode, prompt, dir string) (*promptDelivery, error) {
+	d := &promptDelivery{mode: mode}
+	if mode != PromptModeFile {
+		return d, nil
+	}
+
+	// os.CreateTemp 创建的文件权限为 0600
+	file, err := os.CreateTemp(dir, "daily_summary_prompt-*.md")
+	if err != nil {
+		return nil, fmt.Errorf("create prompt file: %w", err)
+	}
+	d.path = file.Name()
+	if _, err := file.WriteString(prompt); err != nil {
+		file.Close()
+		d.cleanup()
+		return nil, fmt.Errorf("write prompt file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		d.cleanup()
+		return nil, fmt.Errorf("write prompt file: %w", err)
+	}
+	return d, nil
+}
+
+// arg 返回需要放在命令行上的提示词参数：arg 模式为完整提示词，file 模式为读取文件的指令，stdin 模式为空
+func (d *promptDelivery) arg(prompt string) string {
+	switch d.mode {
+	case PromptModeArg:
+		return prompt
+	case PromptModeFile:
+		return fmt.Sprintf("请读取文件 %s 中的完整指令，严格按照其中的要求完成任务，只输出最终结果。", d.path)
+	default:
+		return ""
+	}
+}
+
+// attach stdin 模式下将提示词接到命令的标准输入
+func (d *promptDelivery) attach(cmd *exec.Cmd, prompt string) {
+	if d.mode == PromptModeStdin {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
+}
+
+// cleanup 删除 file 模式的临时文件
+func (d *promptDelivery) cleanup() {
+	if d.path != "" {
+		os.Remove(d.path)
+	}
+}
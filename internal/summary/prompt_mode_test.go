@@ -0,0 +1,112 @@
+package summary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeScript 写入模拟 CLI 的 shell 脚本
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	path := filepath.Join(t.TempDir(), "cli")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return path
+}
+
+// TestCodexStdinAndLastMessage 测试 Codex 通过标准输入接收提示词，并只返回最终回答
+func TestCodexStdinAndLastMessage(t *testing.T) {
+	captured := filepath.Join(t.TempDir(), "stdin.txt")
+	// 参数：exec --output-last-message <file> -
+	script := writeScript(t, `[ "$4" = "-" ] || { echo "unexpected args: $*" >&2; exit 1; }
+cat > `+captured+`
+echo "thinking... running tools"
+echo "## 最终总结" > "$3"
+`)
+
+	client, _ := NewCodexClient(script, t.TempDir())
+	prompt := strings.Repeat("很长的提示词", 1000)
+	result, err := client.GenerateSummary(context.Background(), prompt)
+	if err != nil {
+		t.Fatalf("GenerateSummary failed: %v", err)
+	}
+
+	if strings.TrimSpace(result) != "## 最终总结" {
+		t.Errorf("Expected only the last message, got %q", result)
+	}
+	if data, _ := os.ReadFile(captured); string(data) != prompt {
+		t.Errorf("Prompt was not delivered via stdin (got %d bytes)", len(data))
+	}
+
+	// 流式输出时实时转交标准输出，返回值仍为最终回答
+	var streamed strings.Builder
+	result, err = client.StreamSummary(context.Background(), prompt, func(s string) { streamed.WriteString(s) })
+	if err != nil {
+		t.Fatalf("StreamSummary failed: %v", err)
+	}
+	if strings.TrimSpace(result) != "## 最终总结" || !strings.Contains(streamed.String(), "thinking... running tools") {
+		t.Errorf("Expected streamed progress and last message result, got %q (streamed %q)", result, streamed.String())
+	}
+}
+
+// TestCocoPromptModes 测试 Coco 的三种提示词传递方式
+func TestCocoPromptModes(t *testing.T) {
+	// 模拟 CLI：原样输出收到的提示词（file 模式下从指令中提取文件路径并读取）
+	script := writeScript(t, `if [ $# -lt 2 ]; then cat; exit 0; fi
+path=$(echo "$2" | grep -o '/[^ ]*daily_summary_prompt-[^ ]*\.md')
+if [ -n "$path" ]; then echo "$path" > prompt_path.txt; cat "$path"; else printf '%s' "$2"; fi
+`)
+
+	for _, mode := range []PromptMode{PromptModeArg, PromptModeStdin, PromptModeFile} {
+		t.Run(string(mode), func(t *testing.T) {
+			workDir := t.TempDir()
+			client, _ := NewCocoClient(script, workDir)
+			client.SetPromptMode(mode)
+
+			result, err := client.GenerateSummary(context.Background(), "总结今天的工作")
+			if err != nil {
+				t.Fatalf("GenerateSummary failed: %v", err)
+			}
+			if result != "总结今天的工作" {
+				t.Errorf("Expected prompt to round-trip, got %q", result)
+			}
+
+			// file 模式的临时文件写在工作目录下，调用结束后删除
+			if mode == PromptModeFile {
+				if path, _ := os.ReadFile(filepath.Join(workDir, "prompt_path.txt")); !strings.HasPrefix(string(path), workDir) {
+					t.Errorf("Prompt file should be in the work dir %s, got %q", workDir, path)
+				}
+			}
+			matches, _ := filepath.Glob(filepath.Join(workDir, "daily_summary_prompt-*.md"))
+			if len(matches) > 0 {
+				t.Errorf("Prompt files were not removed: %v", matches)
+			}
+		})
+	}
+
+	// 默认通过标准输入传递，不依赖 agent 读取文件
+	if client, _ := NewCocoClient(script, t.TempDir()); client.promptMode != PromptModeStdin {
+		t.Errorf("Expected coco to default to stdin, got %s", client.promptMode)
+	}
+}
+
+// TestParsePromptMode 测试提示词传递方式的解析
+func TestParsePromptMode(t *testing.T) {
+	if mode, err := ParsePromptMode("", PromptModeStdin); err != nil || mode != PromptModeStdin {
+		t.Errorf("Empty value should return default, got %q, %v", mode, err)
+	}
+	if mode, err := ParsePromptMode(" File ", PromptModeStdin); err != nil || mode != PromptModeFile {
+		t.Errorf("Expected file mode, got %q, %v", mode, err)
+	}
+	if _, err := ParsePromptMode("pipe", PromptModeStdin); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
@@ -0,0 +1,62 @@
+package summary
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// ProviderFactory 根据配置创建 AI 客户端，name 为配置中使用的提供商名称
+type ProviderFactory func(cfg *models.Config, name string) (AIClient, error)
+
+// providerRegistry 已注册的内置提供商（名称 -> 工厂函数）
+var providerRegistry = make(map[string]ProviderFactory)
+
+// RegisterProvider 注册 AI 提供商，同名提供商会被覆盖
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerRegistry[name] = factory
+}
+
+// NewProvider 根据名称创建 AI 客户端
+// command_providers 中定义的提供商优先于同名的内置提供商
+func NewProvider(cfg *models.Config, name string) (AIClient, error) {
+	if command, ok := cfg.CommandProviders[name]; ok {
+		return newCommandProvider(cfg, name, command)
+	}
+
+	factory, ok := providerRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider: %s (supported: %s)", name, strings.Join(ProviderNames(cfg), ", "))
+	}
+	return factory(cfg, name)
+}
+
+// ProviderNames 返回可用的提供商名称（内置提供商和 command_providers，按字母排序）
+func ProviderNames(cfg *models.Config) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range providerRegistry {
+		seen[name] = true
+		names = append(names, name)
+	}
+	if cfg != nil {
+		for name := range cfg.CommandProviders {
+			if !seen[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// promptModeFor 返回 prompt_modes 中为提供商配置的提示词传递方式，未配置时返回 def
+func promptModeFor(cfg *models.Config, name string, def PromptMode) (PromptMode, error) {
+	mode, err := ParsePromptMode(cfg.PromptModes[name], def)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt_modes.%s: %w", name, err)
+	}
+	return mode, nil
+}
@@ -0,0 +1,342 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/templates"
+)
+
+// 报告的内容来源
+const (
+	ReportSourceAuto      = "auto"      // 过滤时使用原始记录，否则优先使用每日总结，没有总结的日期使用原始记录
+	ReportSourceEntries   = "entries"   // 只使用原始记录
+	ReportSourceSummaries = "summaries" // 只使用每日总结（不支持过滤）
+)
+
+// DefaultReportChunkChars 报告内容超过该字符数时分段预先总结
+const DefaultReportChunkChars = 40000
+
+// ReportOptions 自定义报告的生成参数
+type ReportOptions struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	Filter       models.EntryFilter // 只包含满足条件的记录，为空时不过滤
+	Source       string             // 内容来源：ReportSourceAuto（默认）、ReportSourceEntries 或 ReportSourceSummaries
+	TemplatePath string             // 提示词模板路径，为空时使用 template_dir 下的 report_prompt.md 或内置模板
+}
+
+// ReportPromptData 报告模板数据结构
+type ReportPromptData struct {
+	StartDate  string
+	EndDate    string
+	DayCount   int           // 有内容的天数
+	EntryCount int           // 满足过滤条件的记录条数
+	Filter     string        // 过滤条件，未过滤时为空
+	Days       []ReportDay   // 每天的内容（分段预先总结时为空）
+	Chunks     []ReportChunk // 内容过长时各段的预先总结，未分段时为空
+	GroupBy    string        // 分组方式：project 或 tag
+	Groups     []GroupStat   // 记录按项目或标签的分布，没有任何标注时为空
+}
+
+// ReportDay 报告中单日的内容
+type ReportDay struct {
+	Date    string
+	Weekday string
+	Source  string // 内容来源：summary（每日总结）或 entries（原始记录）
+	Content string
+}
+
+// ReportChunk 分段预先总结的结果
+type ReportChunk struct {
+	StartDate string
+	EndDate   string
+	Summary   string
+}
+
+// SetReportChunkChars 设置报告分段的字符数阈值，0 表示不分段
+func (g *Generator) SetReportChunkChars(n int) {
+	g.reportChunkChars = n
+}
+
+// GenerateReport 基于任意日期范围内的原始记录或每日总结生成报告，保存到 summaries/reports 目录
+// 返回报告文件名（模板要求 HTML 时为 .html，否则为 .md）
+func (g *Generator) GenerateReport(ctx context.Context, opts ReportOptions) (string, error) {
+	if opts.EndDate.Before(opts.StartDate) {
+		return "", fmt.Errorf("report end date %s is before start date %s",
+			opts.EndDate.Format("2006-01-02"), opts.StartDate.Format("2006-01-02"))
+	}
+
+	days, entryCount, err := g.collectReportDays(opts)
+	if err != nil {
+		return "", err
+	}
+	rangeStr := fmt.Sprintf("%s 至 %s", opts.StartDate.Format("2006-01-02"), opts.EndDate.Format("2006-01-02"))
+	if !opts.Filter.IsEmpty() {
+		rangeStr += "（" + opts.Filter.String() + "）"
+	}
+	if len(days) == 0 {
+		return "", fmt.Errorf("no work entries or summaries found for %s", rangeStr)
+	}
+
+	log.Printf("Generating report for %s: %d days, %d entries", rangeStr, len(days), entryCount)
+
+	data := ReportPromptData{
+		StartDate:  opts.StartDate.Format("2006-01-02"),
+		EndDate:    opts.EndDate.Format("2006-01-02"),
+		DayCount:   len(days),
+		EntryCount: entryCount,
+		Filter:     opts.Filter.String(),
+		Days:       days,
+		GroupBy:    g.groupByOrDefault(),
+	}
+	data.Groups = g.collectGroupStats(opts.StartDate, opts.EndDate, opts.Filter, data.GroupBy)
+
+	if chunks := chunkReportDays(days, g.reportChunkChars); len(chunks) > 1 {
+		data.Chunks, err = g.summarizeReportChunks(ctx, chunks, data.Filter)
+		if err != nil {
+			return "", err
+		}
+		data.Days = nil
+	}
+
+	prompt, err := g.buildReportPrompt(data, opts.TemplatePath)
+	if err != nil {
+		return "", err
+	}
+
+	spec := outputSpec{html: expectsHTML(prompt)}
+	summary, provider, err := g.generate(ctx, prompt, spec)
+	if err != nil {
+		return "", fmt.Errorf("generate report: %w", err)
+	}
+
+	filename := ReportFilename(opts.StartDate, opts.EndDate, opts.Filter, spec.html)
+	metadata := models.SummaryMetadata{
+		GeneratedAt: time.Now(),
+		Date:        rangeStr,
+		EntryCount:  entryCount,
+		Provider:    provider,
+	}
+	if err := g.storage.SaveReport(filename, summary, metadata); err != nil {
+		return "", fmt.Errorf("save report: %w", err)
+	}
+
+	log.Printf("Report generated successfully: %s", filename)
+	return filename, nil
+}
+
+// collectReportDays 按 opts.Source 收集日期范围内每天的内容，返回有内容的日期和满足过滤条件的记录条数
+func (g *Generator) collectReportDays(opts ReportOptions) ([]ReportDay, int, error) {
+	source := opts.Source
+	if source == "" {
+		source = ReportSourceAuto
+	}
+	switch source {
+	case ReportSourceAuto, ReportSourceEntries:
+	case ReportSourceSummaries:
+		// 每日总结覆盖了当天全部工作，无法按标签拆分
+		if !opts.Filter.IsEmpty() {
+			return nil, 0, fmt.Errorf("report source %q cannot be combined with a filter", source)
+		}
+	default:
+		return nil, 0, fmt.Errorf("unknown report source: %s (supported: auto, entries, summaries)", source)
+	}
+
+	var dailySummaries map[string]string
+	if source != ReportSourceEntries && opts.Filter.IsEmpty() {
+		var err error
+		dailySummaries, err = g.storage.GetDailySummariesInRange(opts.StartDate, opts.EndDate)
+		if err != nil {
+			return nil, 0, fmt.Errorf("get daily summaries: %w", err)
+		}
+	}
+
+	var days []ReportDay
+	var entryCount int
+	for current := opts.StartDate; !current.After(opts.EndDate); current = current.AddDate(0, 0, 1) {
+		dateStr := current.Format("2006-01-02")
+
+		var entries []models.WorkEntry
+		if dailyData, err := g.storage.GetDailyData(current); err != nil {
+			log.Printf("Warning: failed to get daily data for %s: %v", dateStr, err)
+		} else {
+			entries = opts.Filter.Apply(dailyData.Entries)
+		}
+		entryCount += len(entries)
+
+		day := ReportDay{Date: dateStr, Weekday: getWeekdayName(current)}
+		if summary, ok := dailySummaries[dateStr]; ok {
+			day.Source, day.Content = "summary", strings.TrimSpace(summary)
+		} else if source != ReportSourceSummaries && len(entries) > 0 {
+			var builder strings.Builder
+			for _, entry := range entries {
+				builder.WriteString(fmt.Sprintf("- **%s**: %s\n", entry.Timestamp.Format("15:04"), entry.Content))
+			}
+			day.Source, day.Content = "entries", strings.TrimSpace(builder.String())
+		} else {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days, entryCount, nil
+}
+
+// chunkReportDays 按时间顺序把日期分段，每段内容不超过 limit 个字符（单日超过时独占一段）
+// limit <= 0 时不分段
+func chunkReportDays(days []ReportDay, limit int) [][]ReportDay {
+	if limit <= 0 {
+		return [][]ReportDay{days}
+	}
+
+	var chunks [][]ReportDay
+	var current []ReportDay
+	var size int
+	for _, day := range days {
+		if len(current) > 0 && size+len(day.Content) > limit {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, day)
+		size += len(day.Content)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// summarizeReportChunks 逐段预先总结，结果作为最终报告提示词的输入
+func (g *Generator) summarizeReportChunks(ctx context.Context, chunks [][]ReportDay, filter string) ([]ReportChunk, error) {
+	log.Printf("Report content is too long, summarizing in %d chunks", len(chunks))
+
+	result := make([]ReportChunk, 0, len(chunks))
+	for i, days := range chunks {
+		chunk := ReportChunk{StartDate: days[0].Date, EndDate: days[len(days)-1].Date}
+		if g.stream != nil {
+			fmt.Fprintf(g.stream, "[内容较多，正在分段总结 %d/%d：%s 至 %s]\n\n", i+1, len(chunks), chunk.StartDate, chunk.EndDate)
+		}
+
+		summary, _, err := g.generate(ctx, buildReportChunkPrompt(days, filter), outputSpec{})
+		if err != nil {
+			return nil, fmt.Errorf("summarize report chunk %s to %s: %w", chunk.StartDate, chunk.EndDate, err)
+		}
+		chunk.Summary = summary
+		result = append(result, chunk)
+	}
+	return result, nil
+}
+
+// buildReportChunkPrompt 构建分段预先总结的提示词
+func buildReportChunkPrompt(days []ReportDay, filter string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("以下是 %s 至 %s 的工作内容，它是一份较长时间段报告的其中一段。\n",
+		days[0].Date, days[len(days)-1].Date))
+	if filter != "" {
+		builder.WriteString(fmt.Sprintf("内容仅包含带有 %s 标注的工作记录。\n", filter))
+	}
+	builder.WriteString("请提炼这一段的工作要点，供之后汇总成完整报告使用。\n\n")
+
+	writeReportDays(&builder, days)
+
+	builder.WriteString("---\n\n")
+	builder.WriteString("请直接输出 Markdown，按项目或模块用 `###` 标题分类，列出完成的工作、估算耗时、关键进展和遇到的问题。\n")
+	builder.WriteString("保留具体的数据、结论和未解决的问题，不要添加记录中没有的内容。\n")
+
+	return builder.String()
+}
+
+// buildReportPrompt 使用模板构建报告提示词
+// 命令行指定的模板无法使用时返回错误；否则依次尝试 template_dir 和内置模板，都不可用时使用降级提示词
+func (g *Generator) buildReportPrompt(data ReportPromptData, templatePath string) (string, error) {
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("read report template: %w", err)
+		}
+		prompt, err := executeTemplate("report", string(content), data)
+		if err != nil {
+			return "", fmt.Errorf("report template %s: %w", templatePath, err)
+		}
+		log.Printf("Using report template: %s", templatePath)
+		return prompt, nil
+	}
+
+	prompt, _, ok := g.renderPrompt("report", templates.Report, "", data)
+	if !ok {
+		return buildReportFallbackPrompt(data), nil
+	}
+	return prompt, nil
+}
+
+// buildReportFallbackPrompt 降级方案：模板不可用时使用硬编码的提示词
+func buildReportFallbackPrompt(data ReportPromptData) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("请基于以下工作内容生成一份工作报告（%s 至 %s）\n\n", data.StartDate, data.EndDate))
+	if data.Filter != "" {
+		builder.WriteString(fmt.Sprintf("本次报告仅关注带有 %s 标注的工作。\n\n", data.Filter))
+	}
+
+	if len(data.Chunks) > 0 {
+		builder.WriteString("## 各时间段工作要点\n\n")
+		for _, chunk := range data.Chunks {
+			builder.WriteString(fmt.Sprintf("### %s 至 %s\n\n", chunk.StartDate, chunk.EndDate))
+			builder.WriteString(chunk.Summary)
+			builder.WriteString("\n\n")
+		}
+	} else {
+		builder.WriteString("## 每日工作内容\n\n")
+		writeReportDays(&builder, data.Days)
+	}
+
+	builder.WriteString("---\n\n")
+	builder.WriteString("请直接输出 Markdown 格式的报告，包括以下部分：\n\n")
+	builder.WriteString("## 完成情况\n")
+	builder.WriteString("（按项目或模块汇总这段时间完成的主要工作，估算各项目耗时）\n\n")
+	builder.WriteString("## 关键进展与成果\n")
+	builder.WriteString("（突出重要的进展、里程碑和交付成果）\n\n")
+	builder.WriteString("## 遇到的问题与经验\n")
+	builder.WriteString("（列出主要问题、解决情况和值得沉淀的经验）\n\n")
+	builder.WriteString("## 后续计划\n")
+	builder.WriteString("（基于记录中的计划和未解决的问题，列出后续重点）\n")
+
+	return builder.String()
+}
+
+// writeReportDays 按日期写出每天的内容
+func writeReportDays(builder *strings.Builder, days []ReportDay) {
+	for _, day := range days {
+		label := "工作记录"
+		if day.Source == "summary" {
+			label = "每日总结"
+		}
+		builder.WriteString(fmt.Sprintf("### %s (%s) - %s\n\n", day.Date, day.Weekday, label))
+		builder.WriteString(day.Content)
+		builder.WriteString("\n\n")
+	}
+}
+
+// reportNamePattern 文件名中不允许的字符
+var reportNamePattern = regexp.MustCompile(`[^\p{L}\p{N}_\-]+`)
+
+// ReportFilename 返回报告文件名，如 report-2026-09-01_2026-09-14-billing-oncall.md
+func ReportFilename(startDate, endDate time.Time, filter models.EntryFilter, html bool) string {
+	name := fmt.Sprintf("report-%s_%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if !filter.IsEmpty() {
+		if slug := strings.Trim(reportNamePattern.ReplaceAllString(filter.String(), "-"), "-"); slug != "" {
+			name += "-" + slug
+		}
+	}
+	if html {
+		return name + ".html"
+	}
+	return name + ".md"
+}
@@ -0,0 +1,99 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+)
+
+// TestGenerateReport 测试按日期范围和过滤条件生成报告，内容过长时先分段总结再汇总
+func TestGenerateReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	summaryDir := filepath.Join(tmpDir, "summaries")
+	store := storage.NewJSONStorage(filepath.Join(tmpDir, "data"), summaryDir)
+
+	day := func(d, hour int) time.Time { return time.Date(2026, 9, d, hour, 0, 0, 0, time.Local) }
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: day(1, 10), Content: "@billing 对账脚本 #oncall"},
+		{Timestamp: day(1, 11), Content: "@search 索引优化"},
+		{Timestamp: day(3, 10), Content: "@billing 修复重复扣款"},
+		{Timestamp: day(20, 10), Content: "@billing 范围之外的记录"},
+	} {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("SaveEntry failed: %v", err)
+		}
+	}
+	if err := store.SaveSummary(day(1, 0), "## 主要完成的任务\n- 9 月 1 日的日报", models.SummaryMetadata{}); err != nil {
+		t.Fatalf("SaveSummary failed: %v", err)
+	}
+
+	var prompts []string
+	client := aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return fmt.Sprintf("好的：\n## 完成情况\n- 第 %d 次输出", len(prompts)), nil
+	})
+	g := NewGenerator(store, client, nil)
+
+	opts := ReportOptions{
+		StartDate:    day(1, 0),
+		EndDate:      day(14, 0),
+		Filter:       models.EntryFilter{Project: "billing"},
+		TemplatePath: filepath.Join(tmpDir, "missing.md"),
+	}
+	if _, err := g.GenerateReport(context.Background(), opts); err == nil {
+		t.Error("Expected error for missing custom template")
+	}
+
+	// 过滤时使用原始记录；内容超过阈值时逐段总结，最终提示词只包含各段的总结
+	prompts = nil
+	opts.TemplatePath = ""
+	g.SetReportChunkChars(10)
+	filename, err := g.GenerateReport(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if filename != "report-2026-09-01_2026-09-14-billing.md" {
+		t.Errorf("Unexpected filename: %s", filename)
+	}
+	if len(prompts) != 3 {
+		t.Fatalf("Expected 2 chunk prompts and 1 final prompt, got %d", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "对账脚本") || strings.Contains(prompts[0], "索引优化") || strings.Contains(prompts[0], "日报") {
+		t.Errorf("Chunk prompt should only contain filtered entries:\n%s", prompts[0])
+	}
+	if !strings.Contains(prompts[2], "第 1 次输出") || !strings.Contains(prompts[2], "第 2 次输出") || strings.Contains(prompts[2], "修复重复扣款") {
+		t.Errorf("Final prompt should contain chunk summaries instead of entries:\n%s", prompts[2])
+	}
+
+	content, err := os.ReadFile(filepath.Join(summaryDir, "reports", filename))
+	if err != nil {
+		t.Fatalf("Report file not saved: %v", err)
+	}
+	if !strings.Contains(string(content), "2026-09-01 至 2026-09-14（@billing）") || !strings.Contains(string(content), "## 完成情况\n- 第 3 次输出") {
+		t.Errorf("Unexpected report file:\n%s", content)
+	}
+
+	// 未过滤时有日报的日期使用日报，其余日期使用原始记录
+	prompts = nil
+	opts.Filter = models.EntryFilter{}
+	g.SetReportChunkChars(0)
+	if _, err := g.GenerateReport(context.Background(), opts); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "9 月 1 日的日报") || strings.Contains(prompts[0], "对账脚本") || !strings.Contains(prompts[0], "修复重复扣款") {
+		t.Errorf("Unexpected unfiltered prompt:\n%s", prompts)
+	}
+
+	opts.Source = ReportSourceSummaries
+	opts.Filter = models.EntryFilter{Tags: []string{"oncall"}}
+	if _, err := g.GenerateReport(context.Background(), opts); err == nil {
+		t.Error("Expected error when combining summaries source with a filter")
+	}
+}
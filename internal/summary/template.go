@@ -0,0 +1,232 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/templates"
+)
+
+// SetWeeklyTemplatePath 设置周报模板路径
+func (g *Generator) SetWeeklyTemplatePath(path string) {
+	g.weeklyTemplatePath = path
+}
+
+// SetMonthlyTemplatePath 设置月报模板路径
+func (g *Generator) SetMonthlyTemplatePath(path string) {
+	g.monthlyTemplatePath = path
+}
+
+// SetTemplateDir 设置自定义模板目录，目录中与内置模板同名的文件（如 summary_prompt.md）会覆盖内置模板
+func (g *Generator) SetTemplateDir(dir string) {
+	g.templateDir = dir
+}
+
+// templateSource 模板的一个候选来源
+type templateSource struct {
+	name string                 // 用于日志的来源描述（文件路径或 embedded:文件名）
+	read func() ([]byte, error) // 读取模板内容
+}
+
+// templateSources 按优先级返回模板的候选来源：单独配置的路径、template_dir 下的同名文件、内置模板
+func (g *Generator) templateSources(name, path string) []templateSource {
+	var sources []templateSource
+	if path != "" {
+		sources = append(sources, fileTemplateSource(path))
+	}
+	if g.templateDir != "" {
+		dirPath := filepath.Join(g.templateDir, name)
+		// template_dir 只需要包含想要覆盖的模板，不存在的文件直接使用内置模板
+		if _, err := os.Stat(dirPath); err == nil {
+			sources = append(sources, fileTemplateSource(dirPath))
+		}
+	}
+	return append(sources, templateSource{
+		name: "embedded:" + name,
+		read: func() ([]byte, error) { return templates.FS.ReadFile(name) },
+	})
+}
+
+// fileTemplateSource 从文件读取模板
+func fileTemplateSource(path string) templateSource {
+	return templateSource{name: path, read: func() ([]byte, error) { return os.ReadFile(path) }}
+}
+
+// FallbackPromptSource 所有模板都不可用、使用硬编码降级提示词时的模板来源
+const FallbackPromptSource = "fallback"
+
+// renderPrompt 按优先级依次尝试模板来源并渲染，返回提示词和实际使用的来源
+// 某个来源读取、解析或执行失败时记录警告并尝试下一个；全部失败时返回 false，由调用方使用降级提示词
+func (g *Generator) renderPrompt(kind, name, path string, data interface{}) (string, string, bool) {
+	for _, source := range g.templateSources(name, path) {
+		content, err := source.read()
+		if err != nil {
+			log.Printf("Warning: failed to read %s template %s: %v", kind, source.name, err)
+			continue
+		}
+
+		prompt, err := executeTemplate(kind, string(content), data)
+		if err != nil {
+			log.Printf("Warning: %s template %s: %v", kind, source.name, err)
+			continue
+		}
+
+		log.Printf("Using %s template: %s", kind, source.name)
+		return prompt, source.name, true
+	}
+
+	log.Printf("Warning: no usable %s template, using fallback prompt", kind)
+	return "", FallbackPromptSource, false
+}
+
+// executeTemplate 解析并执行模板（可使用 templateFuncs 中的函数）
+func executeTemplate(kind, content string, data interface{}) (string, error) {
+	tmpl, err := template.New(kind + "_prompt").Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// templateFuncs 提示词模板可用的函数
+var templateFuncs = template.FuncMap{
+	// 时长：{{duration $a.Timestamp $b.Timestamp}}、{{.Duration | hours}}、{{.TotalDuration | formatDuration}}
+	"duration":       func(from, to time.Time) time.Duration { return to.Sub(from) },
+	"hours":          formatHours,
+	"formatDuration": formatDuration,
+
+	// 分组：{{range groupByTag .Entries}}{{.Name}} {{.Duration | hours}}{{end}}
+	"groupByTag":     func(entries []PromptEntry) []PromptGroup { return groupPromptEntries(entries, models.GroupByTag) },
+	"groupByProject": func(entries []PromptEntry) []PromptGroup { return groupPromptEntries(entries, models.GroupByProject) },
+
+	// 日期：{{addDays .Date -1}}、{{weekday .Date}}、{{daysBetween .WeekStartDate .WeekEndDate}}、{{formatTime .Timestamp "01-02 15:04"}}
+	"addDays": func(date interface{}, days int) (string, error) {
+		t, err := templateDate(date)
+		if err != nil {
+			return "", err
+		}
+		return t.AddDate(0, 0, days).Format("2006-01-02"), nil
+	},
+	"weekday": func(date interface{}) (string, error) {
+		t, err := templateDate(date)
+		if err != nil {
+			return "", err
+		}
+		return getWeekdayName(t), nil
+	},
+	"daysBetween": func(from, to interface{}) (int, error) {
+		start, err := templateDate(from)
+		if err != nil {
+			return 0, err
+		}
+		end, err := templateDate(to)
+		if err != nil {
+			return 0, err
+		}
+		return int(end.Sub(start).Hours() / 24), nil
+	},
+	"formatTime": func(t time.Time, layout string) string { return t.Format(layout) },
+
+	// 文本：{{.Summary | truncate 200}}、{{join ", " .Tags}}、{{json .Entries}}
+	"truncate": truncateRunes,
+	"join":     func(sep string, items []string) string { return strings.Join(items, sep) },
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// templateDate 将模板参数（YYYY-MM-DD 字符串或 time.Time）转换为日期
+func templateDate(v interface{}) (time.Time, error) {
+	switch date := v.(type) {
+	case time.Time:
+		return date, nil
+	case string:
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported date type %T", v)
+	}
+}
+
+// formatHours 将时长格式化为小时（保留 1 位小数）
+func formatHours(d time.Duration) string {
+	return fmt.Sprintf("%.1f", d.Hours())
+}
+
+// formatDuration 将时长格式化为中文（如 1 小时 30 分钟、45 分钟）
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%d 分钟", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d 小时", minutes/60)
+	default:
+		return fmt.Sprintf("%d 小时 %d 分钟", minutes/60, minutes%60)
+	}
+}
+
+// truncateRunes 截断到最多 n 个字符（按 rune 计算），超出时以 … 结尾
+func truncateRunes(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+// groupPromptEntries 按项目或标签对模板记录分组，规则与 models.GroupEntries 相同
+// 按标签分组时，带多个标签的记录会出现在多个分组中；未标注的记录归入名称为空的分组，排在最后
+func groupPromptEntries(entries []PromptEntry, by string) []PromptGroup {
+	index := make(map[string]int)
+	var groups []PromptGroup
+
+	add := func(name string, entry PromptEntry) {
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, PromptGroup{Name: name})
+		}
+		groups[i].Entries = append(groups[i].Entries, entry)
+		groups[i].EntryCount++
+		groups[i].Duration += entry.Duration
+	}
+
+	for _, entry := range entries {
+		if by == models.GroupByTag {
+			if len(entry.Tags) == 0 {
+				add("", entry)
+			}
+			for _, tag := range entry.Tags {
+				add(tag, entry)
+			}
+		} else {
+			add(entry.Project, entry)
+		}
+	}
+
+	// 按首次出现的顺序排列，未标注分组放到最后
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name != "" && groups[j].Name == ""
+	})
+	return groups
+}
@@ -0,0 +1,254 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+	"humg.top/daily_summary/internal/storage"
+	"humg.top/daily_summary/internal/timesheet"
+	"humg.top/daily_summary/templates"
+)
+
+// TestRenderPromptSources 测试模板来源优先级：单独配置的路径 > template_dir 中的同名文件 > 内置模板
+func TestRenderPromptSources(t *testing.T) {
+	dir := t.TempDir()
+	data := PromptData{Date: "2026-01-21"}
+
+	g := &Generator{}
+	prompt, source, ok := g.renderPrompt("daily", templates.Daily, "", data)
+	if !ok || source != "embedded:summary_prompt.md" || !strings.Contains(prompt, "2026-01-21") || !strings.Contains(prompt, "注意事项") {
+		t.Errorf("Expected embedded template to be used, got:\n%s", prompt)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, templates.Daily), []byte("dir template {{.Date}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g.SetTemplateDir(dir)
+	if prompt, source, _ := g.renderPrompt("daily", templates.Daily, "", data); prompt != "dir template 2026-01-21" || source != filepath.Join(dir, templates.Daily) {
+		t.Errorf("Expected template_dir template, got %q", prompt)
+	}
+	// template_dir 中没有的模板仍使用内置模板
+	if prompt, _, _ := g.renderPrompt("weekly", templates.Weekly, "", WeeklyPromptData{}); !strings.Contains(prompt, "<!DOCTYPE html>") {
+		t.Errorf("Expected embedded weekly template, got:\n%s", prompt)
+	}
+
+	custom := filepath.Join(dir, "custom.md")
+	if err := os.WriteFile(custom, []byte("custom template {{.Date}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if prompt, _, _ := g.renderPrompt("daily", templates.Daily, custom, data); prompt != "custom template 2026-01-21" {
+		t.Errorf("Expected configured template, got %q", prompt)
+	}
+
+	// 配置的模板无法解析时退回到下一个来源
+	if err := os.WriteFile(custom, []byte("broken {{.Date"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if prompt, _, _ := g.renderPrompt("daily", templates.Daily, custom, data); prompt != "dir template 2026-01-21" {
+		t.Errorf("Expected fallback to template_dir template, got %q", prompt)
+	}
+}
+
+// TestTemplateFuncs 测试模板函数
+func TestTemplateFuncs(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 21, hour, minute, 0, 0, time.Local) }
+	data := PromptData{
+		Date: "2026-01-21",
+		Entries: []PromptEntry{
+			{Timestamp: at(9, 0), Content: "周会", Tags: []string{"meeting"}},
+			{Timestamp: at(10, 30), Content: "对账脚本开发，处理重复扣款", Project: "billing", Tags: []string{"bug", "oncall"}, Duration: 90 * time.Minute},
+			{Timestamp: at(11, 15), Content: "继续对账", Project: "billing", Duration: 45 * time.Minute},
+		},
+	}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{`{{$e := .Entries}}{{duration (index $e 0).Timestamp (index $e 2).Timestamp | formatDuration}}`, "2 小时 15 分钟"},
+		{`{{range .Entries}}{{.Duration | hours}} {{end}}`, "0.0 1.5 0.8 "},
+		{`{{range groupByProject .Entries}}[{{.Name}} {{.EntryCount}} {{formatDuration .Duration}}]{{end}}`, "[billing 2 2 小时 15 分钟][ 1 0 分钟]"},
+		{`{{range groupByTag .Entries}}{{.Name}},{{end}}`, "meeting,bug,oncall,,"},
+		{`{{addDays .Date -1}} {{weekday .Date}} {{daysBetween .Date "2026-02-01"}} {{formatTime (index .Entries 1).Timestamp "15:04"}}`, "2026-01-20 周三 11 10:30"},
+		{`{{(index .Entries 1).Content | truncate 5}}|{{join "/" (index .Entries 1).Tags}}`, "对账脚本开…|bug/oncall"},
+		{`{{json (index .Entries 1).Tags}}`, `["bug","oncall"]`},
+	}
+
+	for _, tt := range tests {
+		got, err := executeTemplate("test", tt.tmpl, data)
+		if err != nil {
+			t.Errorf("executeTemplate(%s) failed: %v", tt.tmpl, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("executeTemplate(%s) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+
+	if _, err := executeTemplate("test", `{{addDays "01/21" 1}}`, data); err == nil {
+		t.Error("Expected error for invalid date")
+	}
+}
+
+// TestBuildPromptData 测试日报模板数据中的记录时长、星期和上一篇日报
+func TestBuildPromptData(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewJSONStorage(filepath.Join(tmpDir, "data"), filepath.Join(tmpDir, "summaries"))
+
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 1, day, hour, minute, 0, 0, time.Local) }
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: at(21, 10, 0), Content: "周会"},
+		{Timestamp: at(21, 11, 30), Content: "@billing 对账"},
+		{Timestamp: at(21, 12, 0), Content: "@billing 上线"},
+	} {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("SaveEntry failed: %v", err)
+		}
+	}
+	// 上一篇日报在两天前（中间一天没有日报）
+	if err := store.SaveSummary(at(19, 0, 0), "## 明日计划\n- 对账上线", models.SummaryMetadata{GeneratedAt: at(19, 23, 0)}); err != nil {
+		t.Fatalf("SaveSummary failed: %v", err)
+	}
+
+	dailyData, err := store.GetDailyData(at(21, 0, 0))
+	if err != nil {
+		t.Fatalf("GetDailyData failed: %v", err)
+	}
+
+	var got PromptData
+	g := &Generator{storage: store}
+	g.SetTemplateDir(tmpDir)
+	if err := os.WriteFile(filepath.Join(tmpDir, templates.Daily), []byte(`{{json .}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(g.buildFilteredPrompt(dailyData, models.EntryFilter{Project: "billing"})), &got); err != nil {
+		t.Fatalf("Failed to decode prompt data: %v", err)
+	}
+
+	if got.Weekday != "周三" || got.PreviousDate != "2026-01-19" || got.PreviousSummary != "## 明日计划\n- 对账上线" {
+		t.Errorf("Unexpected weekday or previous summary: %q %q %q", got.Weekday, got.PreviousDate, got.PreviousSummary)
+	}
+	// 过滤后的第一条记录仍按当天全部记录计算时长
+	if len(got.Entries) != 2 || got.Entries[0].Duration != 90*time.Minute || got.Entries[1].Duration != 30*time.Minute || got.Entries[0].ID == "" {
+		t.Errorf("Unexpected entries: %+v", got.Entries)
+	}
+	if got.TotalDuration != 2*time.Hour || len(got.Groups) != 1 || got.Groups[0].Duration != 2*time.Hour {
+		t.Errorf("Unexpected durations: total %s, groups %+v", got.TotalDuration, got.Groups)
+	}
+}
+
+// TestBuildPromptPreview 测试预览的提示词与生成时使用的一致，并报告实际使用的模板
+func TestBuildPromptPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewJSONStorage(filepath.Join(tmpDir, "data"), filepath.Join(tmpDir, "summaries"))
+
+	at := func(day, hour int) time.Time { return time.Date(2026, 1, day, hour, 0, 0, 0, time.Local) }
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: at(21, 10), Content: "@billing 对账"},
+		{Timestamp: at(21, 11), Content: "@search 索引优化"},
+	} {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("SaveEntry failed: %v", err)
+		}
+	}
+
+	var prompts []string
+	client := aiClientFunc(func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "## 主要完成的任务\n- 对账\n## 工作耗时分析\n- 1 小时\n## 关键进展\n- 无\n## 遇到的问题\n- 无\n## 明日计划\n- 上线", nil
+	})
+	g := NewGenerator(store, client, nil)
+
+	preview, err := g.BuildDailyPrompt(at(21, 0), models.EntryFilter{})
+	if err != nil {
+		t.Fatalf("BuildDailyPrompt failed: %v", err)
+	}
+	if preview.Template != "embedded:"+templates.Daily || preview.EntryCount != 2 {
+		t.Errorf("Unexpected preview: template=%s entries=%d", preview.Template, preview.EntryCount)
+	}
+	if err := g.GenerateDailySummary(context.Background(), at(21, 0)); err != nil {
+		t.Fatalf("GenerateDailySummary failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != preview.Prompt {
+		t.Errorf("Preview differs from the prompt sent to AI")
+	}
+
+	filtered, err := g.BuildDailyPrompt(at(21, 0), models.EntryFilter{Project: "billing"})
+	if err != nil {
+		t.Fatalf("BuildDailyPrompt with filter failed: %v", err)
+	}
+	if filtered.EntryCount != 1 || strings.Contains(filtered.Prompt, "索引优化") {
+		t.Errorf("Filtered preview should only contain matching entries:\n%s", filtered.Prompt)
+	}
+	if _, err := g.BuildDailyPrompt(at(22, 0), models.EntryFilter{}); err == nil {
+		t.Error("Expected error for date without entries")
+	}
+
+	// 模板不可用时报告使用了降级提示词
+	broken := filepath.Join(tmpDir, "broken.md")
+	if err := os.WriteFile(broken, []byte("{{.Missing"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	g.SetWeeklyTemplatePath(broken)
+	weekly, err := g.BuildWeeklyPrompt(at(25, 0), models.EntryFilter{})
+	if err != nil {
+		t.Fatalf("BuildWeeklyPrompt failed: %v", err)
+	}
+	if weekly.Template != "embedded:"+templates.Weekly || weekly.EntryCount != 1 || !weekly.StartDate.Equal(at(19, 0)) {
+		t.Errorf("Unexpected weekly preview: template=%s days=%d start=%s", weekly.Template, weekly.EntryCount, weekly.StartDate)
+	}
+}
+
+// TestDailySummaryTimeTable 测试工时表由程序计算：提示词中包含工时表，保存的日报末尾附上同一张表
+func TestDailySummaryTimeTable(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := storage.NewJSONStorage(filepath.Join(tmpDir, "data"), filepath.Join(tmpDir, "summaries"))
+
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 21, hour, minute, 0, 0, time.Local) }
+	for _, entry := range []models.WorkEntry{
+		{Timestamp: at(11, 0), Content: "@billing 对账"},
+		{Timestamp: at(14, 30), Content: "@billing 上线"},
+		{Timestamp: at(15, 0), Content: "#补充 @search 上午的索引评审"},
+		{Timestamp: at(16, 0), Content: "@search 索引优化"},
+	} {
+		if err := store.SaveEntry(entry); err != nil {
+			t.Fatalf("SaveEntry failed: %v", err)
+		}
+	}
+
+	var prompt string
+	client := aiClientFunc(func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "## 主要完成的任务\n- 对账\n## 工作耗时分析\n- 对账最耗时\n## 关键进展\n- 无\n## 遇到的问题\n- 无\n## 明日计划\n- 上线", nil
+	})
+	g := NewGenerator(store, client, nil)
+	g.SetTimeAccounting(timesheet.Options{
+		DefaultWindow:  time.Hour,
+		Breaks:         []timesheet.Break{{Start: 12 * time.Hour, End: 14 * time.Hour}},
+		SupplementTags: timesheet.DefaultSupplementTags,
+	})
+
+	if err := g.GenerateDailySummary(context.Background(), at(0, 0)); err != nil {
+		t.Fatalf("GenerateDailySummary failed: %v", err)
+	}
+
+	// billing：11:00 默认 1 小时 + 14:30 扣除午休后 1.5 小时；search：补充记录不计时，16:00 从 14:30 开始计算 1.5 小时
+	table := "| billing | 2.5 | 62.5% | 2 |\n| search | 1.5 | 37.5% | 2 |\n| **合计** | **4.0** | **100.0%** | **4** |"
+	if !strings.Contains(prompt, table) || !strings.Contains(prompt, "（补充记录，不计时）") || !strings.Contains(prompt, "（耗时 1 小时 30 分钟）") {
+		t.Errorf("Prompt should contain the computed time table:\n%s", prompt)
+	}
+
+	saved, err := store.GetSummary(at(0, 0))
+	if err != nil {
+		t.Fatalf("GetSummary failed: %v", err)
+	}
+	if !strings.Contains(saved, "## 工时统计") || !strings.Contains(saved, table) || !strings.Contains(saved, "\"billing\" : 2.5") {
+		t.Errorf("Saved summary should end with the computed time table:\n%s", saved)
+	}
+}
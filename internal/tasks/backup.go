@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"humg.top/daily_summary/internal/backup"
+	"humg.top/daily_summary/internal/scheduler"
+)
+
+// BackupTask 每日自动备份任务
+type BackupTask struct {
+	sources    backup.Sources
+	backupDir  string // 备份目录
+	retention  int    // 保留的备份个数（0 表示不清理）
+	backupTime string // 执行时间 HH:MM
+}
+
+// NewBackupTask 创建自动备份任务
+func NewBackupTask(sources backup.Sources, backupDir string, retention int, backupTime string) *BackupTask {
+	return &BackupTask{
+		sources:    sources,
+		backupDir:  backupDir,
+		retention:  retention,
+		backupTime: backupTime,
+	}
+}
+
+// ID 返回任务 ID
+func (t *BackupTask) ID() string {
+	return "backup"
+}
+
+// Name 返回任务名称
+func (t *BackupTask) Name() string {
+	return "数据自动备份"
+}
+
+// ShouldRun 判断是否应该执行
+func (t *BackupTask) ShouldRun(now time.Time, config *scheduler.TaskConfig) (bool, func(*scheduler.TaskConfig)) {
+	if !config.Enabled {
+		return false, nil
+	}
+
+	// 检查下次执行时间
+	if config.NextRun.IsZero() {
+		return false, nil
+	}
+
+	return !now.Before(config.NextRun), nil
+}
+
+// Execute 执行任务
+func (t *BackupTask) Execute(ctx context.Context) error {
+	archivePath, manifest, err := backup.Create(t.sources, t.backupDir, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	log.Printf("Backup created: %s (%d files)", archivePath, len(manifest.Files))
+
+	removed, err := backup.Prune(t.backupDir, t.retention)
+	for _, path := range removed {
+		log.Printf("Old backup removed: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune old backups: %w", err)
+	}
+
+	return nil
+}
+
+// OnExecuted 任务执行后的回调
+func (t *BackupTask) OnExecuted(now time.Time, config *scheduler.TaskConfig, err error) {
+	config.LastRun = now
+
+	if err != nil {
+		config.LastError = err.Error()
+		log.Printf("Task %s failed: %v", t.Name(), err)
+	} else {
+		config.LastSuccess = now
+		config.LastError = ""
+	}
+
+	// 计算下次执行时间（明天的备份时间）
+	config.NextRun = scheduler.CalculateNextSummaryTime(now, t.backupTime)
+}
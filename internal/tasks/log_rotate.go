@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -48,7 +49,7 @@ func (t *LogRotateTask) ShouldRun(now time.Time, config *scheduler.TaskConfig) (
 }
 
 // Execute 执行任务
-func (t *LogRotateTask) Execute() error {
+func (t *LogRotateTask) Execute(ctx context.Context) error {
 	if t.maxLogSizeMB <= 0 {
 		// 未设置大小限制，跳过
 		return nil
@@ -0,0 +1,191 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"humg.top/daily_summary/internal/scheduler"
+	"humg.top/daily_summary/internal/storage"
+	"humg.top/daily_summary/internal/summary"
+)
+
+// monthlyCatchUpLimit 补生成时最多回溯的月数
+const monthlyCatchUpLimit = 12
+
+// MonthlySummaryTask 月度总结生成任务
+// 每月指定日期生成上个月的月报；服务停机错过时，下次运行会补生成上次成功之后所有缺失的月报
+type MonthlySummaryTask struct {
+	storage        storage.Storage
+	generator      *summary.Generator
+	day            int         // 每月几号执行（1-28）
+	summaryTime    string      // 执行时间（HH:MM）
+	pendingMonths  []time.Time // 待生成月报的月份（临时字段，由 ShouldRun 设置，Execute 使用）
+	generatedMonth string      // 本次连续生成成功的最新月份（临时字段，由 Execute 设置，OnExecuted 使用）
+}
+
+// NewMonthlySummaryTask 创建月度总结任务
+// day: 每月几号执行（1-28）
+func NewMonthlySummaryTask(
+	storage storage.Storage,
+	generator *summary.Generator,
+	day int,
+	summaryTime string,
+) *MonthlySummaryTask {
+	return &MonthlySummaryTask{
+		storage:     storage,
+		generator:   generator,
+		day:         day,
+		summaryTime: summaryTime,
+	}
+}
+
+// ID 返回任务 ID
+func (t *MonthlySummaryTask) ID() string {
+	return "monthly-summary"
+}
+
+// Name 返回任务名称
+func (t *MonthlySummaryTask) Name() string {
+	return "月度总结生成"
+}
+
+// ShouldRun 判断是否应该执行
+func (t *MonthlySummaryTask) ShouldRun(now time.Time, config *scheduler.TaskConfig) (bool, func(*scheduler.TaskConfig)) {
+	if !config.Enabled {
+		return false, nil
+	}
+
+	pending := t.findPendingMonths(now, config)
+	if len(pending) == 0 {
+		nextRun := scheduler.CalculateNextMonthlySummaryTime(now, t.day, t.summaryTime)
+		log.Printf("MonthlySummaryTask: no pending monthly summaries, delaying to %s", nextRun.Format("2006-01-02 15:04"))
+		return false, func(cfg *scheduler.TaskConfig) {
+			cfg.NextRun = nextRun
+		}
+	}
+
+	t.pendingMonths = pending
+
+	log.Printf("MonthlySummaryTask: found %d pending monthly summaries, will generate", len(pending))
+	return true, nil
+}
+
+// findPendingMonths 返回已到生成时间、有日报但还没有月报的月份，按时间从旧到新排序
+// 只回溯到上次成功生成的月份；从未生成过时只检查最近一个月，不会为全部历史补生成月报
+func (t *MonthlySummaryTask) findPendingMonths(now time.Time, config *scheduler.TaskConfig) []time.Time {
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	// 本月的总结时间已过，上个月的月报到期；否则最近到期的是上上个月
+	latest := thisMonth.AddDate(0, -1, 0)
+	if now.Before(scheduler.MonthlySummaryTime(now.Year(), now.Month(), t.day, t.summaryTime, now.Location())) {
+		latest = latest.AddDate(0, -1, 0)
+	}
+
+	lastGenerated, _ := config.Data["last_generated_month"].(string)
+
+	var pending []time.Time
+	for i, month := 0, latest; i < monthlyCatchUpLimit; i, month = i+1, month.AddDate(0, -1, 0) {
+		if lastGenerated != "" && month.Format("2006-01") <= lastGenerated {
+			break
+		}
+		if t.needsSummary(month) {
+			pending = append([]time.Time{month}, pending...)
+		}
+		if lastGenerated == "" {
+			break
+		}
+	}
+	return pending
+}
+
+// needsSummary 判断月份是否有日报且还没有月报
+func (t *MonthlySummaryTask) needsSummary(month time.Time) bool {
+	if _, err := t.storage.GetMonthlySummary(month); err == nil {
+		return false
+	}
+
+	start, end := summary.MonthRange(month)
+	dailySummaries, err := t.storage.GetDailySummariesInRange(start, end)
+	if err != nil {
+		log.Printf("MonthlySummaryTask: failed to get daily summaries for %s: %v", month.Format("2006-01"), err)
+		return false
+	}
+	return len(dailySummaries) > 0
+}
+
+// Execute 执行任务
+func (t *MonthlySummaryTask) Execute(ctx context.Context) error {
+	pending := t.pendingMonths
+	t.pendingMonths = nil
+	t.generatedMonth = ""
+
+	var generatedCount int
+	var lastError error
+
+	for _, month := range pending {
+		// 调度器停止时放弃剩余月份，下次启动重新生成
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		monthStr := month.Format("2006-01")
+		log.Printf("Generating monthly summary for %s", monthStr)
+
+		if err := t.generator.GenerateMonthlySummary(ctx, month); err != nil {
+			log.Printf("Failed to generate monthly summary for %s: %v", monthStr, err)
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to generate monthly summary for %s: %w", monthStr, ctx.Err())
+			}
+			lastError = err
+			continue // 继续生成其他月份
+		}
+
+		generatedCount++
+		// 只有之前的月份都成功时才推进进度，失败的月份在重试时仍会被找到
+		if lastError == nil {
+			t.generatedMonth = monthStr
+		}
+	}
+
+	if lastError != nil {
+		return fmt.Errorf("failed to generate %d of %d monthly summaries: %w",
+			len(pending)-generatedCount, len(pending), lastError)
+	}
+
+	log.Printf("MonthlySummaryTask: generated %d monthly summaries", generatedCount)
+	return nil
+}
+
+// OnExecuted 任务执行后的回调
+func (t *MonthlySummaryTask) OnExecuted(now time.Time, config *scheduler.TaskConfig, err error) {
+	config.LastRun = now
+
+	if err != nil {
+		config.LastError = err.Error()
+		log.Printf("Task %s failed: %v", t.Name(), err)
+	} else {
+		config.LastSuccess = now
+		config.LastError = ""
+	}
+
+	if t.generatedMonth != "" {
+		if config.Data == nil {
+			config.Data = make(map[string]interface{})
+		}
+		config.Data["last_generated_month"] = t.generatedMonth
+		t.generatedMonth = ""
+	}
+
+	// 计算下次执行时间（下个月的总结时间），失败时 summaryRetryDelay 后重试
+	nextRun := scheduler.CalculateNextMonthlySummaryTime(now, t.day, t.summaryTime)
+	if err != nil {
+		if retryAt := now.Add(summaryRetryDelay); retryAt.Before(nextRun) {
+			nextRun = retryAt
+		}
+	}
+	config.NextRun = nextRun
+
+	log.Printf("MonthlySummaryTask: next run scheduled at %s", nextRun.Format("2006-01-02 15:04:05"))
+}
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -73,7 +74,7 @@ func (t *ReminderTask) ShouldRun(now time.Time, config *scheduler.TaskConfig) (b
 }
 
 // Execute 执行任务
-func (t *ReminderTask) Execute() error {
+func (t *ReminderTask) Execute(ctx context.Context) error {
 	startTime := time.Now()
 	title := "工作记录"
 
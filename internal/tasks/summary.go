@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +11,9 @@ import (
 	"humg.top/daily_summary/internal/summary"
 )
 
+// summaryRetryDelay 总结生成失败后的重试间隔
+const summaryRetryDelay = time.Hour
+
 // SummaryTask 每日总结生成任务
 type SummaryTask struct {
 	storage           storage.Storage
@@ -86,7 +90,7 @@ func (t *SummaryTask) ShouldRun(now time.Time, config *scheduler.TaskConfig) (bo
 }
 
 // Execute 执行任务
-func (t *SummaryTask) Execute() error {
+func (t *SummaryTask) Execute(ctx context.Context) error {
 	// 从临时字段读取未生成的日期列表
 	if len(t.ungeneratedDates) == 0 {
 		log.Printf("SummaryTask.Execute: no dates to generate (this should not happen)")
@@ -101,11 +105,21 @@ func (t *SummaryTask) Execute() error {
 	var lastError error
 
 	for _, date := range t.ungeneratedDates {
+		// 调度器停止时放弃剩余日期，下次启动重新生成
+		if err := ctx.Err(); err != nil {
+			t.ungeneratedDates = nil
+			return err
+		}
+
 		dateStr := date.Format("2006-01-02")
 		log.Printf("Generating summary for %s", dateStr)
 
-		if err := t.generator.GenerateDailySummary(date); err != nil {
+		if err := t.generator.GenerateDailySummary(ctx, date); err != nil {
 			log.Printf("Failed to generate summary for %s: %v", dateStr, err)
+			if ctx.Err() != nil {
+				t.ungeneratedDates = nil
+				return fmt.Errorf("failed to generate summary for %s: %w", dateStr, ctx.Err())
+			}
 			lastError = err
 			continue // 继续生成其他日期的日报
 		}
@@ -151,11 +165,17 @@ func (t *SummaryTask) OnExecuted(now time.Time, config *scheduler.TaskConfig, er
 	}
 
 	// 计算下次执行时间（明天的总结时间）
-	tomorrowSummaryTime := time.Date(now.Year(), now.Month(), now.Day()+1,
+	// 全部失败时（如所有 AI 提供商都被限流）不等到明天，summaryRetryDelay 后重试
+	nextRun := time.Date(now.Year(), now.Month(), now.Day()+1,
 		t.hour, t.minute, 0, 0, now.Location())
-	config.NextRun = tomorrowSummaryTime
+	if err != nil {
+		if retryAt := now.Add(summaryRetryDelay); retryAt.Before(nextRun) {
+			nextRun = retryAt
+		}
+	}
+	config.NextRun = nextRun
 
-	log.Printf("SummaryTask: next run scheduled at %s", tomorrowSummaryTime.Format("2006-01-02 15:04:05"))
+	log.Printf("SummaryTask: next run scheduled at %s", nextRun.Format("2006-01-02 15:04:05"))
 }
 
 // parseSummaryTime 解析总结时间
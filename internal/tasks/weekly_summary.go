@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -94,7 +95,7 @@ func (t *WeeklySummaryTask) ShouldRun(now time.Time, config *scheduler.TaskConfi
 }
 
 // Execute 执行任务
-func (t *WeeklySummaryTask) Execute() error {
+func (t *WeeklySummaryTask) Execute(ctx context.Context) error {
 	now := time.Now()
 
 	// 计算上周的周日日期（周末）
@@ -110,7 +111,7 @@ func (t *WeeklySummaryTask) Execute() error {
 
 	log.Printf("Generating weekly summary for week ending %s", lastSunday.Format("2006-01-02"))
 
-	if err := t.generator.GenerateWeeklySummary(lastSunday); err != nil {
+	if err := t.generator.GenerateWeeklySummary(ctx, lastSunday); err != nil {
 		return fmt.Errorf("failed to generate weekly summary: %w", err)
 	}
 
@@ -139,7 +140,11 @@ func (t *WeeklySummaryTask) OnExecuted(now time.Time, config *scheduler.TaskConf
 	}
 
 	// 计算下次执行时间（下周的同一天同一时间）
+	// 失败时当天 summaryRetryDelay 后重试（ShouldRun 要求当天是周报日，跨天则等到下周）
 	config.NextRun = calculateNextWeeklyTime(now, t.weekday, t.hour, t.minute)
+	if retryAt := now.Add(summaryRetryDelay); err != nil && retryAt.Day() == now.Day() {
+		config.NextRun = retryAt
+	}
 }
 
 // calculateNextWeeklyTime 计算下次周度总结时间
@@ -0,0 +1,334 @@
+package timesheet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// DefaultBreaks 默认的休息时段（午休、晚饭），与内置提示词中的日常工作时间一致
+var DefaultBreaks = []string{"12:00-14:00", "18:00-19:30"}
+
+// DefaultSupplementTags 默认的补充记录标签
+// 带这些标签的记录补记之前遗漏的工作，不是对前一个时间窗口的总结
+var DefaultSupplementTags = []string{"补充", "supplement", "遗漏", "missing", "回顾", "review"}
+
+// Break 每天固定的休息时段，落在其中的时间不计入记录耗时
+type Break struct {
+	Start time.Duration // 距 0 点的时间
+	End   time.Duration
+}
+
+// ParseBreak 解析休息时段（如 12:00-14:00）
+func ParseBreak(value string) (Break, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 2 {
+		return Break{}, fmt.Errorf("invalid break %q (expected HH:MM-HH:MM)", value)
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return Break{}, fmt.Errorf("invalid break %q: %w", value, err)
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return Break{}, fmt.Errorf("invalid break %q: %w", value, err)
+	}
+	if end <= start {
+		return Break{}, fmt.Errorf("invalid break %q: end must be after start", value)
+	}
+	return Break{Start: start, End: end}, nil
+}
+
+// ParseBreaks 解析休息时段列表
+func ParseBreaks(values []string) ([]Break, error) {
+	breaks := make([]Break, 0, len(values))
+	for _, value := range values {
+		b, err := ParseBreak(value)
+		if err != nil {
+			return nil, err
+		}
+		breaks = append(breaks, b)
+	}
+	return breaks, nil
+}
+
+// parseClock 解析 HH:MM 为距 0 点的时间
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", strings.TrimSpace(value))
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Options 时间核算选项
+type Options struct {
+	DefaultWindow  time.Duration // 当天第一条记录的时间窗口（没有上一条记录可参考）
+	MaxWindow      time.Duration // 单条记录耗时上限，超过时截断（如下班后的第一条记录），0 表示不限制
+	Breaks         []Break       // 不计入耗时的休息时段
+	SupplementTags []string      // 补充记录的标签
+}
+
+// Window 一条记录覆盖的时间窗口
+// 每条记录是对前一个时间窗口工作内容的总结，因此窗口为 [上一条常规记录时间, 本条记录时间]
+type Window struct {
+	Entry      models.WorkEntry
+	Start      time.Time     // 时间窗口开始
+	End        time.Time     // 时间窗口结束（即记录时间）
+	Duration   time.Duration // 扣除休息时段并按上限截断后的耗时
+	Supplement bool          // 补充记录：耗时为 0，也不作为下一条记录窗口的起点
+}
+
+// Windows 计算一天内每条记录的时间窗口，结果按记录时间排序
+// 补充记录补记的是更早的工作，无法确定时间窗口，因此不占用时间，其后的常规记录仍从上一条常规记录开始计算
+func Windows(entries []models.WorkEntry, opts Options) []Window {
+	sorted := make([]models.WorkEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	windows := make([]Window, 0, len(sorted))
+	var prev time.Time
+	for _, entry := range sorted {
+		if isSupplement(entry, opts.SupplementTags) {
+			windows = append(windows, Window{Entry: entry, Start: entry.Timestamp, End: entry.Timestamp, Supplement: true})
+			continue
+		}
+
+		start := entry.Timestamp.Add(-opts.DefaultWindow)
+		if !prev.IsZero() {
+			start = prev
+		}
+		if start.After(entry.Timestamp) {
+			start = entry.Timestamp
+		}
+
+		duration := entry.Timestamp.Sub(start) - breakOverlap(start, entry.Timestamp, opts.Breaks)
+		if opts.MaxWindow > 0 && duration > opts.MaxWindow {
+			duration = opts.MaxWindow
+			start = entry.Timestamp.Add(-duration)
+		}
+
+		windows = append(windows, Window{Entry: entry, Start: start, End: entry.Timestamp, Duration: duration})
+		prev = entry.Timestamp
+	}
+	return windows
+}
+
+// isSupplement 判断记录是否带有补充记录标签
+func isSupplement(entry models.WorkEntry, supplementTags []string) bool {
+	for _, tag := range entry.Tags {
+		if containsTag(supplementTags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTag 判断标签列表中是否包含指定标签（忽略大小写和 # 前缀）
+func containsTag(tags []string, tag string) bool {
+	tag = models.NormalizeTag(tag)
+	for _, t := range tags {
+		if models.NormalizeTag(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// breakOverlap 计算 [start, end] 与 end 当天各休息时段重叠的时间
+func breakOverlap(start, end time.Time, breaks []Break) time.Duration {
+	day := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	var overlap time.Duration
+	for _, b := range breaks {
+		from, to := day.Add(b.Start), day.Add(b.End)
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		if to.After(from) {
+			overlap += to.Sub(from)
+		}
+	}
+	return overlap
+}
+
+// Item 工时表中的一行
+type Item struct {
+	Name       string        // 项目或标签名，未标注时为空
+	Duration   time.Duration // 耗时
+	EntryCount int           // 记录条数
+	Hours      float64       // 耗时（小时，保留 1 位小数，各行之和等于 Table.Hours）
+	Percent    float64       // 占比（保留 1 位小数，各行之和为 100）
+}
+
+// Table 按项目或标签汇总的工时表
+type Table struct {
+	GroupBy    string // 汇总方式：project 或 tag
+	Items      []Item // 按耗时从多到少排列，未标注的放到最后
+	Total      time.Duration
+	Hours      float64 // 总耗时（小时，保留 1 位小数）
+	EntryCount int
+}
+
+// Aggregate 按项目或标签汇总时间窗口
+// 按标签汇总时，带多个标签的记录平均分摊到各个标签，补充记录的标签不参与汇总，保证各行之和等于总耗时
+func Aggregate(windows []Window, by string, supplementTags []string) Table {
+	table := Table{GroupBy: by}
+	index := make(map[string]int)
+	add := func(name string, duration time.Duration, count int) {
+		i, ok := index[name]
+		if !ok {
+			i = len(table.Items)
+			index[name] = i
+			table.Items = append(table.Items, Item{Name: name})
+		}
+		table.Items[i].Duration += duration
+		table.Items[i].EntryCount += count
+	}
+
+	for _, window := range windows {
+		table.Total += window.Duration
+		table.EntryCount++
+
+		if by != models.GroupByTag {
+			add(window.Entry.Project, window.Duration, 1)
+			continue
+		}
+
+		var tags []string
+		for _, tag := range window.Entry.Tags {
+			if !containsTag(supplementTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			add("", window.Duration, 1)
+			continue
+		}
+		share := window.Duration / time.Duration(len(tags))
+		for i, tag := range tags {
+			// 除不尽的部分计入第一个标签，避免总和出现误差
+			if i == 0 {
+				add(tag, window.Duration-share*time.Duration(len(tags)-1), 1)
+			} else {
+				add(tag, share, 1)
+			}
+		}
+	}
+
+	sort.SliceStable(table.Items, func(i, j int) bool {
+		a, b := table.Items[i], table.Items[j]
+		if (a.Name == "") != (b.Name == "") {
+			return b.Name == ""
+		}
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.Name < b.Name
+	})
+
+	weights := make([]float64, len(table.Items))
+	for i, item := range table.Items {
+		weights[i] = float64(item.Duration)
+	}
+	totalTenths := int(math.Round(table.Total.Hours() * 10))
+	table.Hours = float64(totalTenths) / 10
+	hours := apportion(weights, totalTenths)
+	var percents []int
+	if table.Total > 0 {
+		percents = apportion(weights, 1000)
+	}
+	for i := range table.Items {
+		table.Items[i].Hours = float64(hours[i]) / 10
+		if percents != nil {
+			table.Items[i].Percent = float64(percents[i]) / 10
+		}
+	}
+	return table
+}
+
+// apportion 按权重将 total 个单位分配给各项（最大余数法），保证各项之和恰好等于 total
+func apportion(weights []float64, total int) []int {
+	result := make([]int, len(weights))
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= 0 || total <= 0 {
+		return result
+	}
+
+	remainders := make([]float64, len(weights))
+	assigned := 0
+	for i, w := range weights {
+		exact := w / sum * float64(total)
+		result[i] = int(math.Floor(exact))
+		remainders[i] = exact - float64(result[i])
+		assigned += result[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; assigned < total; i++ {
+		result[order[i%len(order)]]++
+		assigned++
+	}
+	return result
+}
+
+// Markdown 输出工时表和 Mermaid 饼图
+func (t Table) Markdown() string {
+	label := "项目"
+	if t.GroupBy == models.GroupByTag {
+		label = "标签"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("| %s | 耗时（小时） | 占比 | 记录数 |\n", label))
+	builder.WriteString("|------|-------------|------|--------|\n")
+	for _, item := range t.Items {
+		builder.WriteString(fmt.Sprintf("| %s | %s | %s%% | %d |\n",
+			itemName(item.Name), formatFloat(item.Hours), formatFloat(item.Percent), item.EntryCount))
+	}
+	builder.WriteString(fmt.Sprintf("| **合计** | **%s** | **100.0%%** | **%d** |\n", formatFloat(t.Hours), t.EntryCount))
+
+	builder.WriteString("\n```mermaid\n")
+	builder.WriteString("%%{init: {'theme':'base', 'themeVariables': { 'fontSize':'16px'}}}%%\n")
+	builder.WriteString(fmt.Sprintf("pie title 工作耗时分布 (总计: %s 小时)\n", formatFloat(t.Hours)))
+	for _, item := range t.Items {
+		if item.Hours > 0 {
+			// Mermaid 的标签不支持转义，将双引号替换为单引号
+			name := strings.ReplaceAll(itemName(item.Name), `"`, "'")
+			builder.WriteString(fmt.Sprintf("    \"%s\" : %s\n", name, formatFloat(item.Hours)))
+		}
+	}
+	builder.WriteString("```\n")
+	return builder.String()
+}
+
+// itemName 返回工时表中显示的名称
+func itemName(name string) string {
+	if name == "" {
+		return "未标注"
+	}
+	return name
+}
+
+// formatFloat 保留 1 位小数
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,113 @@
+package timesheet
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"humg.top/daily_summary/internal/models"
+)
+
+// entriesAt 创建 2026-01-21 当天指定时间的记录（解析 @项目 和 #标签）
+func entriesAt(items ...string) []models.WorkEntry {
+	var entries []models.WorkEntry
+	for i := 0; i < len(items); i += 2 {
+		ts, _ := time.ParseInLocation("2006-01-02 15:04", "2026-01-21 "+items[i], time.Local)
+		entry := models.WorkEntry{Timestamp: ts, Content: items[i+1]}
+		entry.Tags, entry.Project = models.ParseEntryMeta(entry.Content)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// TestWindows 测试时间窗口：补充记录不占用时间、扣除休息时段、按上限截断
+func TestWindows(t *testing.T) {
+	breaks, err := ParseBreaks(DefaultBreaks)
+	if err != nil {
+		t.Fatalf("ParseBreaks failed: %v", err)
+	}
+	opts := Options{
+		DefaultWindow:  time.Hour,
+		MaxWindow:      3 * time.Hour,
+		Breaks:         breaks,
+		SupplementTags: DefaultSupplementTags,
+	}
+
+	windows := Windows(entriesAt(
+		"14:30", "@billing 上线",
+		"11:30", "@billing 对账",
+		"15:00", "#补充 上午的需求评审",
+		"16:00", "@search 索引优化",
+		"21:00", "@search 压测",
+	), opts)
+
+	want := []struct {
+		duration   time.Duration
+		supplement bool
+	}{
+		{time.Hour, false},        // 11:30 第一条记录使用默认窗口
+		{time.Hour, false},        // 14:30 跨越午休，扣除 12:00-14:00
+		{0, true},                 // 15:00 补充记录
+		{90 * time.Minute, false}, // 16:00 从上一条常规记录 14:30 开始计算
+		{3 * time.Hour, false},    // 21:00 扣除晚饭后仍超过上限，截断
+	}
+	if len(windows) != len(want) {
+		t.Fatalf("Expected %d windows, got %d", len(want), len(windows))
+	}
+	for i, w := range want {
+		if windows[i].Duration != w.duration || windows[i].Supplement != w.supplement {
+			t.Errorf("Window %d (%s): got %s supplement=%v, want %s supplement=%v",
+				i, windows[i].End.Format("15:04"), windows[i].Duration, windows[i].Supplement, w.duration, w.supplement)
+		}
+	}
+
+	if _, err := ParseBreak("14:00-12:00"); err == nil {
+		t.Error("Expected error for break ending before it starts")
+	}
+	if _, err := ParseBreak("lunch"); err == nil {
+		t.Error("Expected error for invalid break")
+	}
+}
+
+// TestAggregate 测试按项目/标签汇总：小时数和百分比四舍五入后总和仍然精确
+func TestAggregate(t *testing.T) {
+	opts := Options{DefaultWindow: 20 * time.Minute, SupplementTags: DefaultSupplementTags}
+	windows := Windows(entriesAt(
+		"10:20", "@a 任务一 #dev",
+		"10:40", "@b 任务二 #dev #review-meeting",
+		"11:00", "@c 任务三",
+		"11:05", "#补充 @a 昨天的遗留",
+	), opts)
+
+	table := Aggregate(windows, models.GroupByProject, DefaultSupplementTags)
+	if table.Total != time.Hour || table.Hours != 1.0 || table.EntryCount != 4 {
+		t.Fatalf("Unexpected totals: %s %.1f %d", table.Total, table.Hours, table.EntryCount)
+	}
+	var hours, percent float64
+	for _, item := range table.Items {
+		hours += item.Hours
+		percent += item.Percent
+	}
+	if len(table.Items) != 3 || table.Items[0].Name != "a" || table.Items[0].EntryCount != 2 {
+		t.Errorf("Unexpected items: %+v", table.Items)
+	}
+	// 三项各 1/3：0.3+0.3+0.3 需要补足到 1.0，33.3% 需要补足到 100%
+	if int(hours*10+0.5) != 10 || int(percent*10+0.5) != 1000 {
+		t.Errorf("Rounded values should add up: hours=%.1f percent=%.1f", hours, percent)
+	}
+
+	table = Aggregate(windows, models.GroupByTag, DefaultSupplementTags)
+	names := make([]string, 0, len(table.Items))
+	for _, item := range table.Items {
+		names = append(names, item.Name)
+	}
+	// 多标签记录平均分摊；补充标签不参与汇总；未标注排在最后
+	if strings.Join(names, ",") != "dev,review-meeting," || table.Items[0].Duration != 30*time.Minute {
+		t.Errorf("Unexpected tag items: %+v", table.Items)
+	}
+
+	md := table.Markdown()
+	if !strings.Contains(md, "| 标签 |") || !strings.Contains(md, "| 未标注 | 0.3 |") || !strings.Contains(md, "| **合计** | **1.0** | **100.0%** | **4** |") || !strings.Contains(md, "pie title 工作耗时分布 (总计: 1.0 小时)") {
+		t.Errorf("Unexpected markdown:\n%s", md)
+	}
+}
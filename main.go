@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -10,14 +12,31 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"humg.top/daily_summary/config"
+	"humg.top/daily_summary/internal/backup"
 	"humg.top/daily_summary/internal/cli"
 	"humg.top/daily_summary/internal/dialog"
+	"humg.top/daily_summary/internal/export"
+	"humg.top/daily_summary/internal/fsck"
+	"humg.top/daily_summary/internal/importer"
+	"humg.top/daily_summary/internal/migrate"
+	"humg.top/daily_summary/internal/models"
 	"humg.top/daily_summary/internal/scheduler"
+	"humg.top/daily_summary/internal/search"
 	"humg.top/daily_summary/internal/storage"
 	"humg.top/daily_summary/internal/summary"
 	"humg.top/daily_summary/internal/tasks"
+	"humg.top/daily_summary/internal/timesheet"
+)
+
+const (
+	// shutdownTimeout serve 退出时等待正在执行的任务响应取消的最长时间
+	shutdownTimeout = 30 * time.Second
+
+	// aiMaxRetryBackoff AI 提供商重试的最长等待时间
+	aiMaxRetryBackoff = 5 * time.Minute
 )
 
 func main() {
@@ -68,11 +87,39 @@ func main() {
 	case "popup":
 		runPopupWithConfig(*configPath)
 	case "list":
-		runListWithConfig(*configPath)
+		runListWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "edit":
+		runEditWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "delete":
+		runDeleteWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "amend":
+		runAmendWithConfig(*configPath, os.Args[subcommandIndex+1:])
 	case "summary":
 		runSummaryWithConfig(*configPath, os.Args[subcommandIndex+1:])
 	case "weekly":
 		runWeeklySummaryWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "monthly":
+		runMonthlySummaryWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "report":
+		runReportWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "prompt":
+		runPromptWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "search":
+		runSearchWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "export":
+		runExportWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "import":
+		runImportWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "backup":
+		runBackupWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "restore":
+		runRestoreWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "fsck":
+		runFsckWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "migrate":
+		runMigrateWithConfig(*configPath, os.Args[subcommandIndex+1:])
+	case "sqlite-import":
+		runSQLiteImportWithConfig(*configPath)
 	case "help", "-h", "--help":
 		printHelp()
 	default:
@@ -120,50 +167,31 @@ func runServeWithConfig(configPath string) {
 	log.Println("Daily Summary Tool starting...")
 	log.Printf("Data directory: %s", cfg.DataDir)
 	log.Printf("Summary directory: %s", cfg.SummaryDir)
+	if cfg.StorageBackend == storage.BackendSQLite {
+		log.Printf("Storage backend: sqlite (%s)", storage.SQLitePath(cfg))
+	}
+
+	// 升级旧格式数据（每一步执行前都会备份）
+	steps, err := newMigrator(cfg).Run()
+	for _, step := range steps {
+		log.Printf("Schema migrated to v%d: %s (backup: %s)", step.Version, step.Description, step.Backup)
+	}
+	if err != nil {
+		// 不退出：launchd 的 KeepAlive 会反复重启 serve，迁移失败时继续运行，按当前格式读写数据
+		log.Printf("Warning: failed to migrate data, continuing without migration (run migrate to retry): %v", err)
+	}
 
 	// 初始化组件
 	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
 	dlg := dialog.NewOSAScriptDialog(dialogTimeout)
 
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
+	store := openStorage(cfg)
+	defer closeStorage(store)
 
 	// 根据配置创建 AI 客户端
-	var aiClient summary.AIClient
+	aiClient := newAIClient(cfg)
 
-	// 默认使用 codex
-	if cfg.AIProvider == "" || cfg.AIProvider == "codex" {
-		codexPath := cfg.CodexPath
-		if codexPath == "" {
-			codexPath = "codex"
-		}
-		aiClient, err = summary.NewCodexClient(codexPath, cfg.WorkDir)
-		if err != nil {
-			log.Fatalf("Failed to create Codex client: %v", err)
-		}
-		log.Println("Using Codex for summary generation")
-	} else if cfg.AIProvider == "claude" {
-		var claudeClient *summary.ClaudeClient
-		claudeClient, err = summary.NewClaudeClient(cfg.ClaudeCodePath)
-		if err != nil {
-			log.Fatalf("Failed to create Claude client: %v", err)
-		}
-		aiClient = claudeClient
-		log.Println("Using Claude for summary generation")
-	} else if cfg.AIProvider == "coco" {
-		cocoPath := cfg.CocoPath
-		if cocoPath == "" {
-			cocoPath = "coco"
-		}
-		aiClient, err = summary.NewCocoClient(cocoPath, cfg.WorkDir)
-		if err != nil {
-			log.Fatalf("Failed to create Coco client: %v", err)
-		}
-		log.Println("Using Coco for summary generation")
-	} else {
-		log.Fatalf("Unknown AI provider: %s (supported: codex, claude, coco)", cfg.AIProvider)
-	}
-
-	gen := summary.NewGenerator(store, aiClient, dlg)
+	gen := newGenerator(cfg, store, aiClient, dlg)
 
 	// 创建调度器（使用 run 目录作为工作目录）
 	runDir := filepath.Dir(cfg.DataDir)
@@ -188,6 +216,25 @@ func runServeWithConfig(configPath string) {
 		log.Println("Registered weekly summary task")
 	}
 
+	// 创建月度总结任务（如果启用）
+	if cfg.EnableMonthlySummary {
+		monthlyTask := tasks.NewMonthlySummaryTask(
+			store,
+			gen,
+			cfg.MonthlySummaryDay,
+			cfg.MonthlySummaryTime,
+		)
+		sched.RegisterTask(monthlyTask)
+		log.Println("Registered monthly summary task")
+	}
+
+	// 注册自动备份任务（如果启用）
+	if cfg.EnableBackup {
+		backupTask := tasks.NewBackupTask(backupSources(cfg), backupDir(cfg), cfg.BackupRetention, cfg.BackupTime)
+		sched.RegisterTask(backupTask)
+		log.Println("Registered backup task")
+	}
+
 	// 注册日志轮转任务（每3小时检查一次）
 	if cfg.MaxLogSizeMB > 0 {
 		logFile := cfg.LogFile
@@ -215,14 +262,23 @@ func runServeWithConfig(configPath string) {
 		cfg.EnableWeeklySummary,
 		cfg.WeeklySummaryTime,
 		cfg.WeeklySummaryDay,
+		cfg.EnableMonthlySummary,
+		cfg.MonthlySummaryTime,
+		cfg.MonthlySummaryDay,
+		cfg.EnableBackup,
+		cfg.BackupTime,
 	); err != nil {
-		log.Fatalf("Failed to initialize tasks: %v", err)
+		log.Printf("Failed to initialize tasks: %v", err)
+		exitWithStorage(store, 1)
 	}
 
 	// 启动调度器
+	schedDone := make(chan struct{})
 	go func() {
+		defer close(schedDone)
 		if err := sched.Start(); err != nil {
-			log.Fatalf("Scheduler error: %v", err)
+			log.Printf("Scheduler error: %v", err)
+			exitWithStorage(store, 1)
 		}
 	}()
 
@@ -234,8 +290,13 @@ func runServeWithConfig(configPath string) {
 
 	<-sigCh
 	log.Println("Shutting down...")
+	// 取消正在执行的任务（终止 AI 子进程），等待调度循环退出
 	sched.Stop()
-	time.Sleep(1 * time.Second) // 给予一点时间完成清理
+	select {
+	case <-schedDone:
+	case <-time.After(shutdownTimeout):
+		log.Printf("Warning: running task did not stop within %s, exiting anyway", shutdownTimeout)
+	}
 	log.Println("Goodbye!")
 }
 
@@ -273,11 +334,13 @@ func runAddWithConfig(configPath string, args []string) {
 	}
 
 	// 初始化存储
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
+	store := openStorage(cfg)
+	defer closeStorage(store)
 
 	// 执行添加
 	if err := cli.RunAdd(store, content, cfg.DataDir); err != nil {
-		log.Fatalf("Failed to add entry: %v", err)
+		log.Printf("Failed to add entry: %v", err)
+		exitWithStorage(store, 1)
 	}
 }
 
@@ -305,7 +368,8 @@ func runPopupWithConfig(configPath string) {
 	}
 
 	// 初始化存储
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
+	store := openStorage(cfg)
+	defer closeStorage(store)
 
 	// 初始化对话框
 	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
@@ -313,12 +377,20 @@ func runPopupWithConfig(configPath string) {
 
 	// 执行弹窗录入
 	if err := cli.RunPopup(store, dlg, cfg.DataDir); err != nil {
-		log.Fatalf("Failed to popup entry: %v", err)
+		log.Printf("Failed to popup entry: %v", err)
+		exitWithStorage(store, 1)
 	}
 }
 
 // runListWithConfig 查看今日记录
-func runListWithConfig(configPath string) {
+func runListWithConfig(configPath string, args []string) {
+	// 解析参数
+	listCmd := flag.NewFlagSet("list", flag.ExitOnError)
+	filterFlags := addEntryFilterFlags(listCmd)
+	groupByStr := listCmd.String("group-by", "", "按 project 或 tag 分组显示")
+	listCmd.Parse(args)
+	groupBy := parseGroupBy(*groupByStr, "")
+
 	// 加载配置
 	cfg, err := config.Load(configPath)
 	if err != nil {
@@ -336,20 +408,109 @@ func runListWithConfig(configPath string) {
 	}
 
 	// 初始化存储
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
+	store := openStorage(cfg)
+	defer closeStorage(store)
 
 	// 执行列表
-	if err := cli.RunList(store); err != nil {
-		log.Fatalf("Failed to list entries: %v", err)
+	if err := cli.RunList(store, filterFlags.filter(), groupBy); err != nil {
+		log.Printf("Failed to list entries: %v", err)
+		exitWithStorage(store, 1)
 	}
 }
 
+// runEditWithConfig 修改指定 ID 的工作记录
+func runEditWithConfig(configPath string, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: 请提供记录 ID")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary edit <id> [\"新内容\"]")
+		fmt.Fprintln(os.Stderr, "提示: 使用 daily_summary list 查看记录 ID；省略新内容时弹窗修改")
+		os.Exit(1)
+	}
+
+	id := args[0]
+	content := ""
+	if len(args) > 1 {
+		content = args[1]
+	}
+
+	cfg, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
+	dlg := dialog.NewOSAScriptDialog(dialogTimeout)
+
+	if err := cli.RunEdit(store, dlg, id, content); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 修改记录失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// runDeleteWithConfig 删除指定 ID 的工作记录
+func runDeleteWithConfig(configPath string, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: 请提供记录 ID")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary delete <id>")
+		os.Exit(1)
+	}
+
+	_, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	if err := cli.RunDelete(store, args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 删除记录失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// runAmendWithConfig 修改最后一条工作记录
+func runAmendWithConfig(configPath string, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: 请提供新的工作内容")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary amend \"新内容\"")
+		os.Exit(1)
+	}
+
+	_, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	if err := cli.RunAmend(store, args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 修改记录失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// loadEntryCommand 记录修改类命令的公共初始化：加载配置、设置日志、打开存储
+func loadEntryCommand(configPath string) (*models.Config, storage.Storage) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.EnableLogging {
+		logFile := cfg.LogFile
+		if logFile == "" {
+			logFile = filepath.Join("run", "logs", "app.log")
+		}
+		os.MkdirAll(filepath.Dir(logFile), 0755)
+		setupLogging(logFile, cfg.MaxLogSizeMB)
+	}
+
+	return cfg, openStorage(cfg)
+}
+
 // runSummaryWithConfig 生成工作总结
 func runSummaryWithConfig(configPath string, args []string) {
 	// 解析参数
 	summaryCmd := flag.NewFlagSet("summary", flag.ExitOnError)
 	dateStr := summaryCmd.String("date", "", "指定日期 (格式: 2006-01-02，默认今天)")
+	filterFlags := addEntryFilterFlags(summaryCmd)
+	groupByStr := summaryCmd.String("group-by", "", "提示词中记录的分组方式: project（默认）或 tag")
+	output := summaryCmd.String("output", "", "按标签/项目过滤或 --dry-run 时，将结果写入指定文件（默认输出到终端）")
+	noStream := summaryCmd.Bool("no-stream", false, "不实时显示 AI 输出，等待生成完成")
+	dryRun := summaryCmd.Bool("dry-run", false, "只输出将要发送给 AI 的提示词，不调用 AI，不保存也不标记总结状态")
 	summaryCmd.Parse(args)
+	groupBy := parseGroupBy(*groupByStr, models.GroupByProject)
+	filter := filterFlags.filter()
 
 	// 加载配置
 	var err error
@@ -381,51 +542,64 @@ func runSummaryWithConfig(configPath string, args []string) {
 	}
 
 	// 初始化存储
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
-
-	// 创建 AI 客户端
-	var aiClient summary.AIClient
-	if cfg.AIProvider == "" || cfg.AIProvider == "codex" {
-		codexPath := cfg.CodexPath
-		if codexPath == "" {
-			codexPath = "codex"
-		}
-		aiClient, err = summary.NewCodexClient(codexPath, cfg.WorkDir)
-		if err != nil {
-			log.Fatalf("Failed to create Codex client: %v", err)
-		}
-	} else if cfg.AIProvider == "claude" {
-		var claudeClient *summary.ClaudeClient
-		claudeClient, err = summary.NewClaudeClient(cfg.ClaudeCodePath)
+	store := openStorage(cfg)
+	defer closeStorage(store)
+
+	// 只预览提示词：不创建 AI 客户端，不保存也不标记总结状态
+	if *dryRun {
+		gen := newGenerator(cfg, store, nil, nil)
+		gen.SetGroupBy(groupBy)
+		preview, err := gen.BuildDailyPrompt(targetDate, filter)
 		if err != nil {
-			log.Fatalf("Failed to create Claude client: %v", err)
-		}
-		aiClient = claudeClient
-	} else if cfg.AIProvider == "coco" {
-		cocoPath := cfg.CocoPath
-		if cocoPath == "" {
-			cocoPath = "coco"
+			fmt.Fprintf(os.Stderr, "Error: 构建提示词失败: %v\n", err)
+			exitWithStorage(store, 1)
 		}
-		aiClient, err = summary.NewCocoClient(cocoPath, cfg.WorkDir)
-		if err != nil {
-			log.Fatalf("Failed to create Coco client: %v", err)
+		if err := writePromptPreview(preview, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 写入文件失败: %v\n", err)
+			exitWithStorage(store, 1)
 		}
-	} else {
-		log.Fatalf("Unknown AI provider: %s (supported: codex, claude, coco)", cfg.AIProvider)
+		fmt.Fprintln(os.Stderr, "（--dry-run：未调用 AI，未保存总结，也未标记总结状态）")
+		return
 	}
 
+	// 创建 AI 客户端
+	aiClient := newAIClient(cfg)
+
 	// 创建对话框用于发送通知
 	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
 	dlg := dialog.NewOSAScriptDialog(dialogTimeout)
 
 	// 创建生成器
-	gen := summary.NewGenerator(store, aiClient, dlg)
+	gen := newGenerator(cfg, store, aiClient, dlg)
+	gen.SetGroupBy(groupBy)
 
-	// 生成总结
+	// Ctrl+C / SIGTERM 时取消生成并终止 AI 子进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 按标签/项目过滤时只生成局部总结，不覆盖当日的完整总结
+	if !filter.IsEmpty() {
+		fmt.Fprintf(os.Stderr, "正在生成 %s 的工作总结（%s）...\n", targetDate.Format("2006-01-02"), filter.String())
+		content, err := gen.GenerateFilteredDailySummary(ctx, targetDate, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 生成总结失败: %v\n", err)
+			exitWithStorage(store, 1)
+		}
+		if err := writeFilteredSummary(content, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 写入文件失败: %v\n", err)
+			exitWithStorage(store, 1)
+		}
+		return
+	}
+
+	// 生成总结（实时显示 AI 输出）
 	fmt.Printf("正在生成 %s 的工作总结...\n", targetDate.Format("2006-01-02"))
-	if err := gen.GenerateDailySummary(targetDate); err != nil {
+	if !*noStream {
+		gen.SetStreamOutput(os.Stdout)
+	}
+	if err := gen.GenerateDailySummary(ctx, targetDate); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: 生成总结失败: %v\n", err)
-		os.Exit(1)
+		exitWithStorage(store, 1)
 	}
 
 	// 标记总结已生成
@@ -444,7 +618,14 @@ func runWeeklySummaryWithConfig(configPath string, args []string) {
 	// 解析子命令参数
 	summaryFlags := flag.NewFlagSet("weekly", flag.ExitOnError)
 	dateStr := summaryFlags.String("date", "", "周末日期（周日，格式：YYYY-MM-DD，默认为上周日）")
+	filterFlags := addEntryFilterFlags(summaryFlags)
+	groupByStr := summaryFlags.String("group-by", "", "记录分布的统计方式: project（默认）或 tag")
+	output := summaryFlags.String("output", "", "按标签/项目过滤或 --dry-run 时，将结果写入指定文件（默认输出到终端）")
+	noStream := summaryFlags.Bool("no-stream", false, "不实时显示 AI 输出，等待生成完成")
+	dryRun := summaryFlags.Bool("dry-run", false, "只输出将要发送给 AI 的提示词，不调用 AI，不保存")
 	summaryFlags.Parse(args)
+	groupBy := parseGroupBy(*groupByStr, models.GroupByProject)
+	filter := filterFlags.filter()
 
 	// 加载配置
 	cfg, err := config.Load(configPath)
@@ -466,13 +647,7 @@ func runWeeklySummaryWithConfig(configPath string, args []string) {
 	var weekEndDate time.Time
 	if *dateStr == "" {
 		// 默认：上周日
-		now := time.Now()
-		daysFromLastSunday := int(now.Weekday())
-		if daysFromLastSunday == 0 {
-			// 今天是周日，上周日 = 今天 - 7 天
-			daysFromLastSunday = 7
-		}
-		weekEndDate = now.AddDate(0, 0, -daysFromLastSunday)
+		weekEndDate = lastSunday(time.Now())
 	} else {
 		weekEndDate, err = time.Parse("2006-01-02", *dateStr)
 		if err != nil {
@@ -482,57 +657,73 @@ func runWeeklySummaryWithConfig(configPath string, args []string) {
 	}
 
 	// 初始化存储
-	store := storage.NewJSONStorage(cfg.DataDir, cfg.SummaryDir)
-
-	// 创建 AI 客户端
-	var aiClient summary.AIClient
-	if cfg.AIProvider == "" || cfg.AIProvider == "codex" {
-		codexPath := cfg.CodexPath
-		if codexPath == "" {
-			codexPath = "codex"
-		}
-		aiClient, err = summary.NewCodexClient(codexPath, cfg.WorkDir)
+	store := openStorage(cfg)
+	defer closeStorage(store)
+
+	// 只预览提示词：不创建 AI 客户端，不保存
+	if *dryRun {
+		gen := newGenerator(cfg, store, nil, nil)
+		gen.SetGroupBy(groupBy)
+		preview, err := gen.BuildWeeklyPrompt(weekEndDate, filter)
 		if err != nil {
-			log.Fatalf("Failed to create Codex client: %v", err)
+			fmt.Fprintf(os.Stderr, "Error: 构建提示词失败: %v\n", err)
+			exitWithStorage(store, 1)
 		}
-	} else if cfg.AIProvider == "claude" {
-		var claudeClient *summary.ClaudeClient
-		claudeClient, err = summary.NewClaudeClient(cfg.ClaudeCodePath)
-		if err != nil {
-			log.Fatalf("Failed to create Claude client: %v", err)
-		}
-		aiClient = claudeClient
-	} else if cfg.AIProvider == "coco" {
-		cocoPath := cfg.CocoPath
-		if cocoPath == "" {
-			cocoPath = "coco"
-		}
-		aiClient, err = summary.NewCocoClient(cocoPath, cfg.WorkDir)
-		if err != nil {
-			log.Fatalf("Failed to create Coco client: %v", err)
+		if err := writePromptPreview(preview, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 写入文件失败: %v\n", err)
+			exitWithStorage(store, 1)
 		}
-	} else {
-		log.Fatalf("Unknown AI provider: %s (supported: codex, claude, coco)", cfg.AIProvider)
+		fmt.Fprintln(os.Stderr, "（--dry-run：未调用 AI，未保存周报）")
+		return
 	}
 
+	// 创建 AI 客户端
+	aiClient := newAIClient(cfg)
+
 	// 创建对话框用于发送通知
 	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
 	dlg := dialog.NewOSAScriptDialog(dialogTimeout)
 
 	// 创建生成器
-	gen := summary.NewGenerator(store, aiClient, dlg)
+	gen := newGenerator(cfg, store, aiClient, dlg)
+	gen.SetGroupBy(groupBy)
+
+	// Ctrl+C / SIGTERM 时取消生成并终止 AI 子进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// 计算周开始日期
 	weekStartDate := weekEndDate.AddDate(0, 0, -6)
 
-	// 生成周度总结
+	// 按标签/项目过滤时基于原始记录生成局部周报，不覆盖完整周报
+	if !filter.IsEmpty() {
+		fmt.Fprintf(os.Stderr, "正在生成周报（%s 至 %s，%s）...\n",
+			weekStartDate.Format("2006-01-02"),
+			weekEndDate.Format("2006-01-02"),
+			filter.String())
+		content, err := gen.GenerateFilteredWeeklySummary(ctx, weekEndDate, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 生成周报失败: %v\n", err)
+			exitWithStorage(store, 1)
+		}
+		if err := writeFilteredSummary(content, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 写入文件失败: %v\n", err)
+			exitWithStorage(store, 1)
+		}
+		return
+	}
+
+	// 生成周度总结（实时显示 AI 输出）
 	fmt.Printf("正在生成周报（%s 至 %s）...\n",
 		weekStartDate.Format("2006-01-02"),
 		weekEndDate.Format("2006-01-02"))
+	if !*noStream {
+		gen.SetStreamOutput(os.Stdout)
+	}
 
-	if err := gen.GenerateWeeklySummary(weekEndDate); err != nil {
+	if err := gen.GenerateWeeklySummary(ctx, weekEndDate); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: 生成周报失败: %v\n", err)
-		os.Exit(1)
+		exitWithStorage(store, 1)
 	}
 
 	// 构建总结文件路径（周报存放在 summaries/weekly/ 子目录）
@@ -545,6 +736,824 @@ func runWeeklySummaryWithConfig(configPath string, args []string) {
 		weekEndDate.Format("2006-01-02"))
 }
 
+// runPromptWithConfig 输出生成日报/周报时将要发送给 AI 的提示词及使用的模板，用于调试模板
+func runPromptWithConfig(configPath string, args []string) {
+	promptCmd := flag.NewFlagSet("prompt", flag.ExitOnError)
+	dateStr := promptCmd.String("date", "", "日期（格式: 2006-01-02；日报默认今天，周报为周日、默认上周日）")
+	weekly := promptCmd.Bool("weekly", false, "输出周报的提示词")
+	filterFlags := addEntryFilterFlags(promptCmd)
+	groupByStr := promptCmd.String("group-by", "", "提示词中记录的分组方式: project（默认）或 tag")
+	output := promptCmd.String("output", "", "将提示词写入指定文件（默认输出到终端）")
+	promptCmd.Parse(args)
+	groupBy := parseGroupBy(*groupByStr, models.GroupByProject)
+	filter := filterFlags.filter()
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	var date time.Time
+	switch {
+	case *dateStr != "":
+		date, err = time.Parse("2006-01-02", *dateStr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 无效的日期格式，应为 YYYY-MM-DD\n")
+			os.Exit(1)
+		}
+	case *weekly:
+		date = lastSunday(time.Now())
+	default:
+		date = time.Now()
+	}
+
+	store := openStorage(cfg)
+	defer closeStorage(store)
+
+	// 只构建提示词，不需要 AI 客户端和通知
+	gen := newGenerator(cfg, store, nil, nil)
+	gen.SetGroupBy(groupBy)
+
+	var preview *summary.PromptPreview
+	if *weekly {
+		preview, err = gen.BuildWeeklyPrompt(date, filter)
+	} else {
+		preview, err = gen.BuildDailyPrompt(date, filter)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 构建提示词失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+	if err := writePromptPreview(preview, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 写入文件失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// writePromptPreview 输出提示词（终端或文件），模板来源等信息输出到 stderr，便于重定向提示词
+func writePromptPreview(preview *summary.PromptPreview, output string) error {
+	source := preview.Template
+	if source == summary.FallbackPromptSource {
+		source += "（模板不可用，使用内置降级提示词）"
+	}
+	period := preview.StartDate.Format("2006-01-02")
+	if !preview.EndDate.Equal(preview.StartDate) {
+		period += " 至 " + preview.EndDate.Format("2006-01-02")
+	}
+	fmt.Fprintf(os.Stderr, "日期: %s\n模板: %s\n提示词长度: %d 字符\n\n", period, source, utf8.RuneCountInString(preview.Prompt))
+
+	if output == "" {
+		fmt.Println(preview.Prompt)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(preview.Prompt), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ 提示词已保存到: %s\n", output)
+	return nil
+}
+
+// lastSunday 返回上周日（今天是周日时返回 7 天前）
+func lastSunday(now time.Time) time.Time {
+	daysFromLastSunday := int(now.Weekday())
+	if daysFromLastSunday == 0 {
+		daysFromLastSunday = 7
+	}
+	return now.AddDate(0, 0, -daysFromLastSunday)
+}
+
+// runMonthlySummaryWithConfig 手动生成月度总结
+func runMonthlySummaryWithConfig(configPath string, args []string) {
+	monthlyFlags := flag.NewFlagSet("monthly", flag.ExitOnError)
+	monthStr := monthlyFlags.String("month", "", "月份（格式：YYYY-MM，默认为上个月）")
+	noStream := monthlyFlags.Bool("no-stream", false, "不实时显示 AI 输出，等待生成完成")
+	monthlyFlags.Parse(args)
+
+	// 加载配置
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 设置日志
+	if cfg.EnableLogging {
+		logFile := cfg.LogFile
+		if logFile == "" {
+			logFile = filepath.Join("run", "logs", "app.log")
+		}
+		os.MkdirAll(filepath.Dir(logFile), 0755)
+		setupLogging(logFile, cfg.MaxLogSizeMB)
+	}
+
+	// 确定月份
+	var month time.Time
+	if *monthStr == "" {
+		// 默认：上个月
+		now := time.Now()
+		month = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+	} else {
+		month, err = time.ParseInLocation("2006-01", *monthStr, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 无效的月份格式，应为 YYYY-MM\n")
+			os.Exit(1)
+		}
+	}
+	monthStart, monthEnd := summary.MonthRange(month)
+
+	store := openStorage(cfg)
+	defer closeStorage(store)
+
+	aiClient := newAIClient(cfg)
+
+	// 创建对话框用于发送通知
+	dialogTimeout := time.Duration(cfg.DialogTimeout) * time.Second
+	dlg := dialog.NewOSAScriptDialog(dialogTimeout)
+
+	gen := newGenerator(cfg, store, aiClient, dlg)
+
+	// Ctrl+C / SIGTERM 时取消生成并终止 AI 子进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 生成月度总结（实时显示 AI 输出）
+	fmt.Printf("正在生成 %s 的月报（%s 至 %s）...\n",
+		monthStart.Format("2006-01"),
+		monthStart.Format("2006-01-02"),
+		monthEnd.Format("2006-01-02"))
+	if !*noStream {
+		gen.SetStreamOutput(os.Stdout)
+	}
+
+	if err := gen.GenerateMonthlySummary(ctx, month); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 生成月报失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+
+	summaryPath := filepath.Join(cfg.SummaryDir, "monthly", fmt.Sprintf("monthly-%s.md", monthStart.Format("2006-01")))
+	fmt.Printf("✓ 月报已生成并保存到: %s\n", summaryPath)
+}
+
+// runReportWithConfig 基于任意日期范围（可按标签/项目过滤）生成报告
+func runReportWithConfig(configPath string, args []string) {
+	reportCmd := flag.NewFlagSet("report", flag.ExitOnError)
+	fromStr := reportCmd.String("from", "", "起始日期（格式：YYYY-MM-DD，必填）")
+	toStr := reportCmd.String("to", "", "结束日期（格式：YYYY-MM-DD，默认今天）")
+	filterExpr := reportCmd.String("filter", "", `过滤条件，如 "@billing #oncall"（项目最多一个，标签需同时满足）`)
+	filterFlags := addEntryFilterFlags(reportCmd)
+	source := reportCmd.String("source", summary.ReportSourceAuto, "内容来源: auto（有日报用日报，否则用原始记录；过滤时只用原始记录）、entries 或 summaries")
+	templatePath := reportCmd.String("template", "", "提示词模板路径（默认使用 template_dir 下的 report_prompt.md 或内置模板）")
+	groupByStr := reportCmd.String("group-by", "", "记录分布的统计方式: project（默认）或 tag")
+	noStream := reportCmd.Bool("no-stream", false, "不实时显示 AI 输出，等待生成完成")
+	reportCmd.Parse(args)
+	groupBy := parseGroupBy(*groupByStr, models.GroupByProject)
+
+	if *fromStr == "" {
+		fmt.Fprintln(os.Stderr, "Error: 请通过 --from 指定起始日期")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary report --from DATE [--to DATE] [--filter EXPR] [--source SOURCE] [--template PATH]")
+		os.Exit(1)
+	}
+	startDate, err := time.ParseInLocation("2006-01-02", *fromStr, time.Local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 无效的日期格式 %s，应为 YYYY-MM-DD\n", *fromStr)
+		os.Exit(1)
+	}
+	endDate := time.Now()
+	if *toStr != "" {
+		endDate, err = time.ParseInLocation("2006-01-02", *toStr, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 无效的日期格式 %s，应为 YYYY-MM-DD\n", *toStr)
+			os.Exit(1)
+		}
+	}
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.Local)
+	if endDate.Before(startDate) {
+		fmt.Fprintln(os.Stderr, "Error: 结束日期不能早于起始日期")
+		os.Exit(1)
+	}
+
+	// --filter 与 --tag/--project 合并
+	filter, err := models.ParseEntryFilter(*filterExpr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 无效的过滤条件: %v\n", err)
+		os.Exit(1)
+	}
+	extra := filterFlags.filter()
+	filter.Tags = append(filter.Tags, extra.Tags...)
+	if extra.Project != "" {
+		if filter.Project != "" && filter.Project != models.NormalizeProject(extra.Project) {
+			fmt.Fprintln(os.Stderr, "Error: 只能指定一个项目")
+			os.Exit(1)
+		}
+		filter.Project = models.NormalizeProject(extra.Project)
+	}
+
+	// 加载配置
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 设置日志
+	if cfg.EnableLogging {
+		logFile := cfg.LogFile
+		if logFile == "" {
+			logFile = filepath.Join("run", "logs", "app.log")
+		}
+		os.MkdirAll(filepath.Dir(logFile), 0755)
+		setupLogging(logFile, cfg.MaxLogSizeMB)
+	}
+
+	store := openStorage(cfg)
+	defer closeStorage(store)
+
+	aiClient := newAIClient(cfg)
+
+	// 报告由用户手动生成，不发送通知
+	gen := newGenerator(cfg, store, aiClient, nil)
+	gen.SetGroupBy(groupBy)
+
+	// Ctrl+C / SIGTERM 时取消生成并终止 AI 子进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rangeStr := fmt.Sprintf("%s 至 %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if !filter.IsEmpty() {
+		rangeStr += "，" + filter.String()
+	}
+	fmt.Printf("正在生成报告（%s）...\n", rangeStr)
+	if !*noStream {
+		gen.SetStreamOutput(os.Stdout)
+	}
+
+	filename, err := gen.GenerateReport(ctx, summary.ReportOptions{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Filter:       filter,
+		Source:       *source,
+		TemplatePath: *templatePath,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 生成报告失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+
+	fmt.Printf("✓ 报告已生成并保存到: %s\n", filepath.Join(cfg.SummaryDir, "reports", filename))
+}
+
+// runSearchWithConfig 搜索工作记录和总结
+func runSearchWithConfig(configPath string, args []string) {
+	searchCmd := flag.NewFlagSet("search", flag.ExitOnError)
+	fromStr := searchCmd.String("from", "", "起始日期 (格式: 2006-01-02)")
+	toStr := searchCmd.String("to", "", "结束日期 (格式: 2006-01-02)")
+	useRegex := searchCmd.Bool("regex", false, "将查询作为正则表达式")
+	kind := searchCmd.String("type", "", "只搜索指定类型: entry, summary, daily, weekly, monthly")
+	limit := searchCmd.Int("limit", 50, "最大结果数（0 表示不限）")
+	filterFlags := addEntryFilterFlags(searchCmd)
+	positional := parseInterspersed(searchCmd, args)
+
+	query := strings.Join(positional, " ")
+	if query == "" && filterFlags.filter().IsEmpty() && *fromStr == "" && *toStr == "" {
+		fmt.Fprintln(os.Stderr, "Error: 请提供搜索内容")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary search <query> [--from DATE] [--to DATE] [--regex] [--tag TAG] [--project NAME] [--type TYPE]")
+		fmt.Fprintln(os.Stderr, "示例: daily_summary search 支付服务 --from 2026-01-01")
+		os.Exit(1)
+	}
+
+	for _, dateStr := range []string{*fromStr, *toStr} {
+		if dateStr == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", dateStr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: 无效的日期格式，应为 YYYY-MM-DD\n")
+			os.Exit(1)
+		}
+	}
+
+	var kinds []string
+	switch *kind {
+	case "":
+	case "summary":
+		kinds = []string{search.KindDaily, search.KindWeekly, search.KindMonthly}
+	case search.KindEntry, search.KindDaily, search.KindWeekly, search.KindMonthly:
+		kinds = []string{*kind}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: 未知的类型 %s（可选: entry, summary, daily, weekly, monthly）\n", *kind)
+		os.Exit(1)
+	}
+
+	cfg, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	indexPath := filepath.Join(filepath.Dir(cfg.DataDir), "index", "search.gob")
+	err := cli.RunSearch(store, indexPath, cfg.SummaryDir, search.Query{
+		Text:    query,
+		Regex:   *useRegex,
+		From:    *fromStr,
+		To:      *toStr,
+		Tags:    filterFlags.tags,
+		Project: *filterFlags.project,
+		Kinds:   kinds,
+		Limit:   *limit,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 搜索失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// runExportWithConfig 导出工作记录（可选打包日报/周报）
+func runExportWithConfig(configPath string, args []string) {
+	exportCmd := flag.NewFlagSet("export", flag.ExitOnError)
+	fromStr := exportCmd.String("from", "", "起始日期 (格式: 2006-01-02，默认 7 天前)")
+	toStr := exportCmd.String("to", "", "结束日期 (格式: 2006-01-02，默认今天)")
+	format := exportCmd.String("format", export.FormatCSV, "导出格式: "+strings.Join(export.Formats, ", "))
+	bundle := exportCmd.Bool("bundle", false, "连同日报/周报打包为 zip")
+	output := exportCmd.String("output", "", "输出文件（默认输出到终端；--bundle 时默认为 daily_summary-<from>_<to>.zip）")
+	maxWindow := exportCmd.Int("max-window", 0, "单条记录耗时上限（分钟，默认为 max_entry_window 或提醒间隔的 2 倍）")
+	filterFlags := addEntryFilterFlags(exportCmd)
+	exportCmd.Parse(args)
+
+	validFormat := false
+	for _, f := range export.Formats {
+		if *format == f {
+			validFormat = true
+		}
+	}
+	if !validFormat {
+		fmt.Fprintf(os.Stderr, "Error: 未知的导出格式 %s（可选: %s）\n", *format, strings.Join(export.Formats, ", "))
+		os.Exit(1)
+	}
+
+	today := time.Now()
+	to := parseDateFlag(*toStr, time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local))
+	from := parseDateFlag(*fromStr, to.AddDate(0, 0, -6))
+	if from.After(to) {
+		fmt.Fprintln(os.Stderr, "Error: 起始日期不能晚于结束日期")
+		os.Exit(1)
+	}
+
+	if *bundle && *output == "" {
+		*output = fmt.Sprintf("daily_summary-%s_%s.zip", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+
+	cfg, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	timeOpts := timesheetOptions(cfg)
+	opts := export.Options{
+		From:           from,
+		To:             to,
+		Filter:         filterFlags.filter(),
+		DefaultWindow:  timeOpts.DefaultWindow,
+		MaxWindow:      timeOpts.MaxWindow,
+		Breaks:         timeOpts.Breaks,
+		SupplementTags: timeOpts.SupplementTags,
+	}
+	if *maxWindow > 0 {
+		opts.MaxWindow = time.Duration(*maxWindow) * time.Minute
+	}
+
+	if err := cli.RunExport(store, cfg.SummaryDir, *format, opts, *bundle, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 导出失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// runImportWithConfig 从 CSV、JSON Lines 或导出文件导入工作记录
+func runImportWithConfig(configPath string, args []string) {
+	importCmd := flag.NewFlagSet("import", flag.ExitOnError)
+	format := importCmd.String("format", "", "文件格式: "+strings.Join(importer.Names(), ", ")+"（默认按扩展名识别）")
+	mapping := importCmd.String("map", "", "CSV 列映射，如 timestamp=开始时间,content=描述（字段: timestamp, date, time, content, project, tags）")
+	timeLayout := importCmd.String("time-format", "", "时间格式（Go layout，如 2006/01/02 15:04），默认自动识别常见格式")
+	dryRun := importCmd.Bool("dry-run", false, "只预览将要导入的记录，不写入")
+	markSummarized := importCmd.Bool("mark-summarized", false, "将导入文件涉及的日期标记为已生成日报（不自动补生成，重复导入时同样生效）")
+	positional := parseInterspersed(importCmd, args)
+
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: 请提供要导入的文件")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary import <file> [--format FORMAT] [--map FIELD=COLUMN,...] [--dry-run]")
+		fmt.Fprintln(os.Stderr, "示例: daily_summary import toggl.csv --format toggl --dry-run")
+		os.Exit(1)
+	}
+	path := positional[0]
+
+	if *format == "" {
+		*format = importer.DetectFormat(path)
+		if *format == "" {
+			fmt.Fprintf(os.Stderr, "Error: 无法根据扩展名识别文件格式，请使用 --format 指定（可选: %s）\n", strings.Join(importer.Names(), ", "))
+			os.Exit(1)
+		}
+	}
+
+	columns := make(map[string]string)
+	if *mapping != "" {
+		for _, pair := range strings.Split(*mapping, ",") {
+			field, column, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(column) == "" {
+				fmt.Fprintf(os.Stderr, "Error: 无效的列映射 %q，应为 字段=列名\n", pair)
+				os.Exit(1)
+			}
+			columns[strings.TrimSpace(field)] = strings.TrimSpace(column)
+		}
+	}
+
+	parser, err := importer.NewParser(*format, importer.ParserOptions{Columns: columns, TimeLayout: *timeLayout})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	_, store := loadEntryCommand(configPath)
+	defer closeStorage(store)
+
+	if err := cli.RunImport(store, parser, path, *dryRun, *markSummarized); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 导入失败: %v\n", err)
+		exitWithStorage(store, 1)
+	}
+}
+
+// parseDateFlag 解析 YYYY-MM-DD 格式的日期参数，为空时返回 defaultValue
+func parseDateFlag(value string, defaultValue time.Time) time.Time {
+	if value == "" {
+		return defaultValue
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 无效的日期格式 %s，应为 YYYY-MM-DD\n", value)
+		os.Exit(1)
+	}
+	return date
+}
+
+// reminderInterval 获取配置的提醒间隔（minute_interval 优先）
+func reminderInterval(cfg *models.Config) time.Duration {
+	minutes := cfg.MinuteInterval
+	if minutes <= 0 {
+		minutes = cfg.HourlyInterval * 60
+	}
+	if minutes <= 0 {
+		minutes = 60
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// stringList 可重复指定的字符串 flag（如 --tag a --tag b）
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// entryFilterFlags 按标签/项目过滤记录的公共 flag
+type entryFilterFlags struct {
+	tags    stringList
+	project *string
+}
+
+// addEntryFilterFlags 为子命令注册 --tag 和 --project flag
+func addEntryFilterFlags(fs *flag.FlagSet) *entryFilterFlags {
+	f := &entryFilterFlags{}
+	fs.Var(&f.tags, "tag", "只包含带有指定 #标签 的记录（可重复，需同时满足）")
+	f.project = fs.String("project", "", "只包含属于指定 @项目 的记录")
+	return f
+}
+
+// filter 转换为记录过滤条件
+func (f *entryFilterFlags) filter() models.EntryFilter {
+	return models.EntryFilter{Tags: f.tags, Project: *f.project}
+}
+
+// parseGroupBy 校验 --group-by 参数，为空时返回 defaultValue
+func parseGroupBy(value, defaultValue string) string {
+	switch value {
+	case "":
+		return defaultValue
+	case models.GroupByProject, models.GroupByTag:
+		return value
+	default:
+		fmt.Fprintf(os.Stderr, "Error: 未知的分组方式 %s（可选: project, tag）\n", value)
+		os.Exit(1)
+		return ""
+	}
+}
+
+// writeFilteredSummary 输出按标签/项目过滤生成的总结（写入文件或终端）
+func writeFilteredSummary(content, output string) error {
+	if output == "" {
+		fmt.Println(content)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ 总结已保存到: %s\n", output)
+	return nil
+}
+
+// parseInterspersed 解析子命令参数，允许 flag 出现在位置参数之后
+// （标准 flag 包遇到第一个非 flag 参数就会停止解析）
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// runMigrateWithConfig 将数据升级到当前格式版本
+func runMigrateWithConfig(configPath string, args []string) {
+	migrateCmd := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := migrateCmd.Bool("dry-run", false, "只列出待执行的迁移，不修改数据")
+	migrateCmd.Parse(args)
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := cli.RunMigrate(newMigrator(cfg), *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 迁移失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newMigrator 创建数据迁移器，迁移前的备份保存在备份目录
+func newMigrator(cfg *models.Config) *migrate.Migrator {
+	return migrate.New(cfg.DataDir, cfg.SummaryDir, backupDir(cfg))
+}
+
+// runBackupWithConfig 创建数据备份
+func runBackupWithConfig(configPath string, args []string) {
+	backupCmd := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := backupCmd.String("output", "", "备份文件路径（默认保存到备份目录并清理超出保留个数的旧备份）")
+	keep := backupCmd.Int("keep", -1, "保留的备份个数（默认使用配置 backup_retention，0 表示不清理）")
+	backupCmd.Parse(args)
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	retention := cfg.BackupRetention
+	if *keep >= 0 {
+		retention = *keep
+	}
+
+	if err := cli.RunBackup(backupSources(cfg), backupDir(cfg), *output, retention); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 备份失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runRestoreWithConfig 从备份文件恢复数据
+func runRestoreWithConfig(configPath string, args []string) {
+	restoreCmd := flag.NewFlagSet("restore", flag.ExitOnError)
+	verifyOnly := restoreCmd.Bool("verify", false, "只校验备份文件，不恢复")
+	positional := parseInterspersed(restoreCmd, args)
+
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: 请提供备份文件路径")
+		fmt.Fprintln(os.Stderr, "\n用法: daily_summary restore <archive> [--verify]")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := cli.RunRestore(positional[0], restoreTargets(cfg), backupDir(cfg), cfg.WorkDir, *verifyOnly); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 恢复失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runFsckWithConfig 检查并修复数据文件
+func runFsckWithConfig(configPath string, args []string) {
+	fsckCmd := flag.NewFlagSet("fsck", flag.ExitOnError)
+	repair := fsckCmd.Bool("repair", false, "修复发现的问题（隔离无法解析的文件、移动/去重记录、校正总结标记）")
+	fsckCmd.Parse(args)
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.StorageBackend == storage.BackendSQLite {
+		fmt.Println("提示：fsck 检查的是 data_dir 下的 JSON 数据文件，当前使用 sqlite 存储后端")
+	}
+
+	remaining, err := cli.RunFsck(fsck.Options{
+		DataDir:       cfg.DataDir,
+		SummaryDir:    cfg.SummaryDir,
+		QuarantineDir: filepath.Join(filepath.Dir(cfg.DataDir), "quarantine"),
+		Repair:        *repair,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 检查失败: %v\n", err)
+		os.Exit(1)
+	}
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
+
+// backupDir 返回备份目录（未配置 backup_dir 时为 run/backups）
+func backupDir(cfg *models.Config) string {
+	if cfg.BackupDir != "" {
+		return cfg.BackupDir
+	}
+	return filepath.Join(filepath.Dir(cfg.DataDir), "backups")
+}
+
+// backupSources 返回需要备份的路径（仅 sqlite 后端备份数据库）
+func backupSources(cfg *models.Config) backup.Sources {
+	sources := restoreTargets(cfg)
+	if cfg.StorageBackend != storage.BackendSQLite {
+		sources.SQLitePath = ""
+	}
+	return sources
+}
+
+// restoreTargets 返回恢复的目标路径（备份中包含数据库时恢复到 sqlite_path）
+func restoreTargets(cfg *models.Config) backup.Sources {
+	return backup.Sources{
+		DataDir:    cfg.DataDir,
+		SummaryDir: cfg.SummaryDir,
+		TasksFile:  filepath.Join(filepath.Dir(cfg.DataDir), "tasks.json"),
+		SQLitePath: storage.SQLitePath(cfg),
+	}
+}
+
+// runSQLiteImportWithConfig 将 JSON 数据和 Markdown 总结导入 SQLite 数据库
+func runSQLiteImportWithConfig(configPath string) {
+	// 加载配置
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 设置日志
+	if cfg.EnableLogging {
+		logFile := cfg.LogFile
+		if logFile == "" {
+			logFile = filepath.Join("run", "logs", "app.log")
+		}
+		os.MkdirAll(filepath.Dir(logFile), 0755)
+		setupLogging(logFile, cfg.MaxLogSizeMB)
+	}
+
+	// 无论当前配置的存储后端是什么，都导入到 sqlite_path 指定的数据库
+	dbPath := storage.SQLitePath(cfg)
+	store, err := storage.NewSQLiteStorage(dbPath, cfg.SummaryDir)
+	if err != nil {
+		log.Fatalf("Failed to open sqlite storage: %v", err)
+	}
+	defer store.Close()
+
+	if err := cli.RunSQLiteImport(store, cfg.DataDir, cfg.SummaryDir, dbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: 导入失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.StorageBackend != storage.BackendSQLite {
+		fmt.Println("\n提示：在配置文件中设置 storage_backend: sqlite 后即可切换到 SQLite 存储")
+	}
+}
+
+// newGenerator 根据配置创建总结生成器
+func newGenerator(cfg *models.Config, store storage.Storage, aiClient summary.AIClient, notifier summary.Notifier) *summary.Generator {
+	gen := summary.NewGenerator(store, aiClient, notifier)
+	gen.SetRequiredSections(cfg.SummarySections, cfg.WeeklySections, cfg.MonthlySections)
+	gen.SetOutputRetries(cfg.AIOutputRetries)
+	gen.SetReportChunkChars(cfg.ReportChunkChars)
+	gen.SetTemplateDir(cfg.TemplateDir)
+	gen.SetTemplatePath(cfg.DailyTemplate)
+	gen.SetWeeklyTemplatePath(cfg.WeeklyTemplate)
+	gen.SetMonthlyTemplatePath(cfg.MonthlyTemplate)
+	gen.SetTimeAccounting(timesheetOptions(cfg))
+	return gen
+}
+
+// timesheetOptions 根据配置返回计算记录耗时的规则：当天第一条记录按一个提醒间隔计算，单条记录上限默认为提醒间隔的 2 倍
+func timesheetOptions(cfg *models.Config) timesheet.Options {
+	interval := reminderInterval(cfg)
+	opts := timesheet.Options{
+		DefaultWindow:  interval,
+		MaxWindow:      2 * interval,
+		SupplementTags: cfg.SupplementTags,
+	}
+	if cfg.MaxEntryWindow > 0 {
+		opts.MaxWindow = time.Duration(cfg.MaxEntryWindow) * time.Minute
+	}
+	if opts.SupplementTags == nil {
+		opts.SupplementTags = timesheet.DefaultSupplementTags
+	}
+
+	breaks := cfg.WorkBreaks
+	if breaks == nil {
+		breaks = timesheet.DefaultBreaks
+	}
+	parsed, err := timesheet.ParseBreaks(breaks)
+	if err != nil {
+		log.Fatalf("Invalid work_breaks: %v", err)
+	}
+	opts.Breaks = parsed
+	return opts
+}
+
+// newAIClient 根据配置创建 AI 客户端：按 ai_providers 顺序回退，每个提供商的调用受 aiTimeout 限制
+func newAIClient(cfg *models.Config) summary.AIClient {
+	var providers []summary.Provider
+	hasOffline := false
+	for _, name := range aiProviders(cfg) {
+		providers = append(providers, summary.Provider{
+			Name:   name,
+			Client: summary.WithTimeout(newProviderClient(cfg, name), aiTimeout(cfg, name)),
+		})
+		hasOffline = hasOffline || name == summary.OfflineProviderName
+	}
+	// 所有 AI 提供商都失败时离线生成日报，避免没有可用模型的日子没有任何记录
+	if cfg.OfflineFallback && !hasOffline {
+		providers = append(providers, summary.Provider{Name: summary.OfflineProviderName, Client: summary.NewOfflineClient()})
+	}
+
+	chain, err := summary.NewChain(providers, summary.RetryPolicy{
+		MaxRetries: cfg.AIRetries,
+		Backoff:    time.Duration(cfg.AIRetryBackoff) * time.Second,
+		MaxBackoff: aiMaxRetryBackoff,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create AI client: %v", err)
+	}
+	if len(providers) > 1 {
+		log.Printf("AI provider fallback order: %s", strings.Join(chain.Names(), " -> "))
+	}
+	return chain
+}
+
+// aiProviders 返回按顺序尝试的 AI 提供商（ai_providers 优先，否则为 ai_provider，默认 codex）
+func aiProviders(cfg *models.Config) []string {
+	if len(cfg.AIProviders) > 0 {
+		return cfg.AIProviders
+	}
+	if cfg.AIProvider != "" {
+		return []string{cfg.AIProvider}
+	}
+	return []string{"codex"}
+}
+
+// aiTimeout 返回指定 AI 提供商的调用超时（ai_timeouts 中的配置优先于 ai_timeout），0 表示不限制
+func aiTimeout(cfg *models.Config, provider string) time.Duration {
+	seconds := cfg.AITimeout
+	if override, ok := cfg.AITimeouts[provider]; ok {
+		seconds = override
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// newProviderClient 创建指定提供商的 AI 客户端（内置提供商或 command_providers 中定义的命令）
+func newProviderClient(cfg *models.Config, provider string) summary.AIClient {
+	client, err := summary.NewProvider(cfg, provider)
+	if err != nil {
+		log.Fatalf("Failed to create AI client: %v", err)
+	}
+	log.Printf("Using %s for summary generation", provider)
+	return client
+}
+
+// openStorage 根据配置创建存储实例
+func openStorage(cfg *models.Config) storage.Storage {
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+	return store
+}
+
+// closeStorage 关闭需要释放资源的存储实例（如 SQLite 连接）
+func closeStorage(store storage.Storage) {
+	if closer, ok := store.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// exitWithStorage 关闭存储后退出：os.Exit 不会执行 defer，SQLite 存储需要 Close 才会合并 WAL
+func exitWithStorage(store storage.Storage, code int) {
+	closeStorage(store)
+	os.Exit(code)
+}
+
 // printHelp 打印帮助信息
 func printHelp() {
 	fmt.Println(`Daily Summary Tool - 工作记录助手
@@ -556,9 +1565,23 @@ func printHelp() {
   serve            启动后台服务（长期运行模式）
   add <content>    手动添加工作记录
   popup            弹窗输入工作记录（与定时弹窗相同）
-  list             查看今日记录
-  summary [--date] 生成工作总结
-  weekly [--date]  生成周度总结（基于每日总结）
+  list             查看今日记录（含记录 ID，支持 --tag/--project/--group-by）
+  edit <id> [内容] 修改指定记录（省略内容时弹窗修改）
+  delete <id>      删除指定记录
+  amend <content>  修改最后一条记录
+  summary [--date] 生成工作总结，实时显示 AI 输出（--no-stream 关闭；--tag/--project 只总结相关记录，不保存）
+  weekly [--date]  生成周度总结（基于每日总结；--no-stream、--tag/--project 同上）
+  monthly [--month] 生成月度总结（基于当月的日报和周报；--no-stream 同上）
+  report --from    生成任意日期范围的报告（--to/--filter/--source/--template，保存到 summaries/reports）
+  prompt [--date]  输出将要发送给 AI 的提示词及使用的模板（--weekly 周报；summary/weekly 的 --dry-run 同样只输出提示词）
+  search <query>   搜索工作记录和总结（支持 --from/--to/--regex/--tag/--project/--type）
+  export           导出工作记录（--from/--to/--format csv|json|ics|md，--bundle 打包总结）
+  import <file>    导入工作记录（csv、toggl、jsonl 或 export 导出的 json，支持 --dry-run）
+  backup           备份数据、总结和任务状态（tar.gz，含清单和校验和）
+  restore <file>   校验并恢复备份（--verify 只校验；需先停止后台服务）
+  fsck             检查数据文件（损坏、日期错误、重复记录、总结标记），--repair 修复
+  migrate          升级旧格式数据（serve 启动时自动执行，--dry-run 只查看）
+  sqlite-import    将 JSON 数据和 Markdown 总结导入 SQLite 数据库
   help             显示此帮助信息
 
 全局选项:
@@ -570,15 +1593,37 @@ func printHelp() {
   daily_summary add "完成需求文档审查"              # 添加工作记录
   daily_summary popup                              # 弹窗输入工作记录
   daily_summary list                               # 查看今日记录
+  daily_summary list --group-by project            # 按 @项目 分组查看今日记录
+  daily_summary edit 20260119-a1b2c3 "修正后的内容"  # 修改指定记录
+  daily_summary amend "补充说明"                    # 修改最后一条记录
   daily_summary summary                            # 生成今日总结
   daily_summary summary --date 2026-01-19          # 生成指定日期的总结
+  daily_summary summary --project billing          # 只总结 @billing 相关的记录
   daily_summary weekly                             # 生成上周的周报
   daily_summary weekly --date 2026-01-26           # 生成指定周末日期的周报
+  daily_summary summary --dry-run                  # 查看今日总结的提示词，不调用 AI
+  daily_summary prompt --weekly --date 2026-01-26  # 查看指定周的周报提示词及使用的模板
+  daily_summary monthly                            # 生成上个月的月报
+  daily_summary monthly --month 2026-09            # 生成指定月份的月报
+  daily_summary report --from 2026-09-01 --to 2026-09-14                  # 生成迭代回顾报告
+  daily_summary report --from 2026-07-01 --to 2026-09-30 --filter "#billing"  # 生成本季度 #billing 的项目报告
+  daily_summary search 支付服务 --from 2026-01-01  # 搜索记录和总结
+  daily_summary search "pay(ment)?" --regex        # 正则搜索
+  daily_summary export --from 2026-01-01 --to 2026-01-31 --format csv --output jan.csv  # 导出工时表
+  daily_summary export --format md --bundle        # 导出最近 7 天记录并打包日报/周报
+  daily_summary import log.csv --map timestamp=时间,content=事项 --dry-run  # 预览导入表格记录
+  daily_summary import toggl.csv --format toggl    # 导入 Toggl 导出的 CSV
+  daily_summary backup                             # 备份到 run/backups
+  daily_summary restore run/backups/daily_summary-backup-20260120-030000.tar.gz  # 从备份恢复
+  daily_summary fsck --repair                      # 检查并修复数据文件
+  daily_summary migrate --dry-run                  # 查看待执行的数据迁移
+  daily_summary sqlite-import                      # 迁移历史数据到 SQLite
   daily_summary --config ~/my-config.yaml          # 使用自定义配置启动服务
 
 说明:
   - 后台服务通过 install.sh 安装后会自动启动
   - add 命令直接在命令行添加记录，popup 命令弹窗输入
+  - 记录中的 #标签 和 @项目 会被自动识别，用于过滤、分组和总结分类
   - 手动添加的记录会立即保存，并在下次定时弹窗中显示
   - 如果后台服务已在运行，执行 serve 命令会提示并退出
   - Mac 睡眠唤醒后，定时器会自动重置，确保定时任务正常运行`)
@@ -0,0 +1,19 @@
+// Package templates 内置的提示词模板
+// 模板在编译时嵌入二进制，launchd、cron 等从任意工作目录启动时也能使用完整的默认模板；
+// 可通过 template_dir 或 daily_template / weekly_template / monthly_template 配置覆盖
+package templates
+
+import "embed"
+
+// 模板文件名（同时也是 template_dir 下查找自定义模板时使用的文件名）
+const (
+	Daily   = "summary_prompt.md"
+	Weekly  = "weekly_summary_prompt.md"
+	Monthly = "monthly_summary_prompt.md"
+	Report  = "report_prompt.md"
+)
+
+// FS 内置的默认模板
+//
+//go:embed *.md
+var FS embed.FS